@@ -0,0 +1,151 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	prefixesMx sync.RWMutex
+	prefixes   = make(map[string]bool)
+)
+
+// registerPrefix records that a logger with the given prefix was created
+func registerPrefix(prefix string) {
+	prefixesMx.RLock()
+	registered := prefixes[prefix]
+	prefixesMx.RUnlock()
+	if !registered {
+		prefixesMx.Lock()
+		prefixes[prefix] = true
+		prefixesMx.Unlock()
+	}
+}
+
+// adminLogger describes the configuration of loggers with a given prefix
+type adminLogger struct {
+	Prefix string `json:"prefix"`
+	Level  string `json:"level"`
+	Trace  bool   `json:"trace"`
+}
+
+// adminConfig describes the configuration of all loggers
+type adminConfig struct {
+	Level   string         `json:"level"`
+	Loggers []*adminLogger `json:"loggers"`
+}
+
+// adminUpdate is the body of PUT requests. Fields that aren't set are left
+// unchanged.
+type adminUpdate struct {
+	Level *string `json:"level"`
+	Trace *bool   `json:"trace"`
+}
+
+// AdminHandler returns an http.Handler that lets operators inspect and change
+// levels at runtime, for example mounted with:
+//
+//	http.Handle("/debug/golog", golog.AdminHandler())
+//
+// GET returns the global level along with the level and trace setting of the
+// prefix of every logger created so far. PUT with a body like
+// {"level": "info"} sets the global level. Requests with a prefix query
+// parameter, e.g. /debug/golog?prefix=flashlight.proxy, apply to loggers with
+// that prefix instead: GET returns its level and trace setting, PUT with a body
+// like {"level": "debug", "trace": true} sets either or both, and DELETE
+// removes them so that the prefix goes back to the global level. Levels are
+// parsed with ParseSeverity.
+//
+// The handler has no access control of its own, so it should only be exposed
+// to operators.
+func AdminHandler() http.Handler {
+	return http.HandlerFunc(serveAdmin)
+}
+
+func serveAdmin(resp http.ResponseWriter, req *http.Request) {
+	prefix, hasPrefix := req.URL.Query()["prefix"]
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var update adminUpdate
+		if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+			http.Error(resp, "invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		var severity Severity
+		if update.Level != nil {
+			var err error
+			severity, err = ParseSeverity(*update.Level)
+			if err != nil {
+				http.Error(resp, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if !hasPrefix {
+			if update.Trace != nil {
+				http.Error(resp, "trace can only be set for a prefix", http.StatusBadRequest)
+				return
+			}
+			if update.Level != nil {
+				SetLevel(severity)
+			}
+			break
+		}
+		if update.Level != nil {
+			SetPrefixLevel(prefix[0], severity)
+		}
+		if update.Trace != nil {
+			setTrace(prefix[0], *update.Trace)
+		}
+	case http.MethodDelete:
+		if !hasPrefix {
+			http.Error(resp, "prefix is required", http.StatusBadRequest)
+			return
+		}
+		clearPrefix(prefix[0])
+	default:
+		resp.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(resp, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var result interface{}
+	if hasPrefix {
+		result = adminLoggerFor(prefix[0])
+	} else {
+		config := &adminConfig{Level: adminLevel(GetLevel()), Loggers: []*adminLogger{}}
+		prefixesMx.RLock()
+		for p := range prefixes {
+			config.Loggers = append(config.Loggers, adminLoggerFor(p))
+		}
+		prefixesMx.RUnlock()
+		sort.Slice(config.Loggers, func(i, j int) bool {
+			return config.Loggers[i].Prefix < config.Loggers[j].Prefix
+		})
+		result = config
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(resp).Encode(result)
+}
+
+func adminLoggerFor(prefix string) *adminLogger {
+	trace, found := levelRules.Load().(*levelRuleSet).traces[prefix]
+	if !found {
+		trace = traceEnabledFor(prefix)
+	}
+	return &adminLogger{Prefix: prefix, Level: adminLevel(levelFor(prefix)), Trace: trace}
+}
+
+// adminLevel returns the default label of the given level, or its numeric
+// value if it doesn't have one, so that it can be parsed with ParseSeverity.
+func adminLevel(s Severity) string {
+	for _, known := range severities {
+		if s == known {
+			return s.defaultLabel()
+		}
+	}
+	return strconv.Itoa(int(s))
+}
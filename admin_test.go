@@ -0,0 +1,67 @@
+package golog
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	defer SetLevel(0)
+	defer clearPrefix("admin.b")
+	defer clearPrefix("admin.a")
+
+	a := LoggerFor("admin.a")
+	LoggerFor("admin.b")
+	handler := AdminHandler()
+	do := func(method string, target string, body string) (int, string) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp.Code, strings.TrimSpace(resp.Body.String())
+	}
+
+	code, body := do(http.MethodGet, "/debug/golog", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `{"level":"0","loggers":[`)
+	assert.Contains(t, body, `{"prefix":"admin.a","level":"0","trace":false},{"prefix":"admin.b","level":"0","trace":false}`)
+
+	code, body = do(http.MethodPut, "/debug/golog", `{"level":"info"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `{"level":"INFO","loggers":[`)
+	assert.Equal(t, Severity(INFO), GetLevel())
+
+	code, body = do(http.MethodPut, "/debug/golog?prefix=admin.a", `{"level":"debug","trace":true}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"prefix":"admin.a","level":"DEBUG","trace":true}`, body)
+	assert.True(t, a.IsTraceEnabled())
+	a.Debug("debug")
+	a.Trace("trace")
+	assert.Equal(t, "DEBUG admin.a: admin_test.go:999 debug\n", out.String(), "TRACE is still below the level")
+
+	code, body = do(http.MethodPut, "/debug/golog?prefix=admin.a", `{"trace":false}`)
+	assert.Equal(t, `{"prefix":"admin.a","level":"DEBUG","trace":false}`, body)
+	assert.False(t, a.IsTraceEnabled())
+
+	code, body = do(http.MethodGet, "/debug/golog?prefix=admin.b", "")
+	assert.Equal(t, `{"prefix":"admin.b","level":"INFO","trace":false}`, body)
+
+	code, body = do(http.MethodDelete, "/debug/golog?prefix=admin.a", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"prefix":"admin.a","level":"INFO","trace":false}`, body)
+
+	code, _ = do(http.MethodPut, "/debug/golog?prefix=admin.a", `{"level":"loud"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPut, "/debug/golog", `{"trace":true}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodDelete, "/debug/golog", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do(http.MethodPost, "/debug/golog", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
@@ -0,0 +1,254 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/getlantern/hidden"
+)
+
+// AlertFormat is the payload format of the webhook used by an AlertReporter
+type AlertFormat int
+
+const (
+	// AlertGeneric posts a JSON object with the fields dedup_key, summary,
+	// severity, prefix, host, time and context.
+	AlertGeneric AlertFormat = iota
+
+	// AlertPagerDuty posts a trigger event to the PagerDuty Events API v2,
+	// e.g. https://events.pagerduty.com/v2/enqueue.
+	AlertPagerDuty
+
+	// AlertSlack posts a message to a Slack incoming webhook
+	AlertSlack
+)
+
+// AlertOptions configures an AlertReporter
+type AlertOptions struct {
+	// Format is the payload format, defaults to AlertGeneric
+	Format AlertFormat
+
+	// RoutingKey is the integration key of the PagerDuty service
+	RoutingKey string
+
+	// ErrorThreshold is the number of ERRORs logged with the same prefix
+	// within Window that triggers an alert. 0 disables alerting on ERRORs, in
+	// which case only FATALs trigger alerts.
+	ErrorThreshold int
+
+	// Window is the window in which ERRORs are counted, defaults to 1 minute
+	Window time.Duration
+
+	// DedupInterval is the time during which alerts with the same dedup key
+	// aren't repeated, defaults to 1 hour.
+	DedupInterval time.Duration
+
+	// QueueSize is the maximum number of alerts waiting to be sent, defaults
+	// to 100. Alerts triggered while the queue is full are dropped.
+	QueueSize int
+
+	// Client is the http.Client used for sending, defaults to a client with a
+	// 30 second timeout.
+	Client *http.Client
+}
+
+// AlertReporter fires a webhook when a FATAL is reported, or when the number
+// of ERRORs reported for a prefix crosses a threshold within a window. Register
+// it with RegisterReporter(reporter.Report).
+//
+// Each alert has a dedup key, made from the prefix and the error's
+// fingerprint (see Fingerprint) for FATALs, and from the prefix for ERROR
+// rates. Alerts with the same dedup key are only sent once per
+// DedupInterval, and PagerDuty additionally groups them into one incident.
+type AlertReporter struct {
+	url    string
+	opts   AlertOptions
+	http   HTTPOptions
+	alerts chan []byte
+	wg     sync.WaitGroup
+	now    func() time.Time
+
+	mx     sync.Mutex
+	errors map[string][]time.Time
+	sent   map[string]time.Time
+	closed bool
+}
+
+// NewAlertReporter creates an AlertReporter that posts alerts to the given
+// webhook url. opts may be nil.
+func NewAlertReporter(url string, opts *AlertOptions) *AlertReporter {
+	a := &AlertReporter{
+		url:    url,
+		now:    time.Now,
+		errors: make(map[string][]time.Time),
+		sent:   make(map[string]time.Time),
+	}
+	if opts != nil {
+		a.opts = *opts
+	}
+	if a.opts.Window <= 0 {
+		a.opts.Window = time.Minute
+	}
+	if a.opts.DedupInterval <= 0 {
+		a.opts.DedupInterval = time.Hour
+	}
+	if a.opts.QueueSize <= 0 {
+		a.opts.QueueSize = 100
+	}
+	a.http = HTTPOptions{Client: a.opts.Client}
+	a.http.applyDefaults()
+	a.alerts = make(chan []byte, a.opts.QueueSize)
+	a.wg.Add(1)
+	go a.send()
+	return a
+}
+
+// Report implements ErrorReporter
+func (a *AlertReporter) Report(err error, severity Severity, ctx map[string]interface{}) {
+	prefix, _ := ctx["prefix"].(string)
+	message := hidden.Clean(err.Error())
+	now := a.now()
+
+	var dedupKey, summary string
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if a.closed {
+		return
+	}
+	switch {
+	case severity >= FATAL:
+		if fingerprint, ok := ctx["error_fingerprint"].(string); ok {
+			dedupKey = alertDedupKey("fatal", prefix, fingerprint)
+		} else {
+			desc, ok := ctx["error"].(string)
+			if !ok {
+				desc = message
+			}
+			location, _ := ctx["error_location"].(string)
+			dedupKey = alertDedupKey("fatal", prefix, desc, location)
+		}
+		summary = fmt.Sprintf("FATAL in %v: %v", alertComponent(prefix), message)
+	case severity >= ERROR && a.opts.ErrorThreshold > 0:
+		times := a.errors[prefix]
+		cutoff := now.Add(-a.opts.Window)
+		for len(times) > 0 && !times[0].After(cutoff) {
+			times = times[1:]
+		}
+		times = append(times, now)
+		a.errors[prefix] = times
+		if len(times) < a.opts.ErrorThreshold {
+			return
+		}
+		dedupKey = alertDedupKey("errors", prefix)
+		summary = fmt.Sprintf("%d errors in %v within %v, last: %v", len(times), alertComponent(prefix), a.opts.Window, message)
+	default:
+		return
+	}
+
+	if last, found := a.sent[dedupKey]; found && now.Sub(last) < a.opts.DedupInterval {
+		return
+	}
+	a.sent[dedupKey] = now
+	select {
+	case a.alerts <- a.encode(dedupKey, summary, severity, prefix, now, ctx):
+	default:
+		// queue full, drop alert
+	}
+}
+
+// Close sends all queued alerts and stops the reporter
+func (a *AlertReporter) Close() error {
+	a.mx.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.alerts)
+	}
+	a.mx.Unlock()
+	a.wg.Wait()
+	return nil
+}
+
+func (a *AlertReporter) send() {
+	defer a.wg.Done()
+	for alert := range a.alerts {
+		if err := postWithRetry(a.url, "application/json", alert, &a.http); err != nil {
+			errorOnLogging(err)
+		}
+	}
+}
+
+// encode encodes an alert in the configured format
+func (a *AlertReporter) encode(dedupKey string, summary string, severity Severity, prefix string, ts time.Time, ctx map[string]interface{}) []byte {
+	buf := &bytes.Buffer{}
+	switch a.opts.Format {
+	case AlertPagerDuty:
+		pdSeverity := "error"
+		if severity >= FATAL {
+			pdSeverity = "critical"
+		}
+		buf.WriteString(`{"routing_key":`)
+		writeJSONString(buf, a.opts.RoutingKey)
+		buf.WriteString(`,"event_action":"trigger","dedup_key":`)
+		writeJSONString(buf, dedupKey)
+		buf.WriteString(`,"payload":{"summary":`)
+		writeJSONString(buf, summary)
+		buf.WriteString(`,"source":`)
+		writeJSONString(buf, hostname)
+		buf.WriteString(`,"severity":"`)
+		buf.WriteString(pdSeverity)
+		buf.WriteString(`","timestamp":`)
+		writeJSONString(buf, ts.UTC().Format(time.RFC3339Nano))
+		if prefix != "" {
+			buf.WriteString(`,"component":`)
+			writeJSONString(buf, prefix)
+		}
+		buf.WriteString(`,"custom_details":`)
+		writeJSONObject(buf, ctx)
+		buf.WriteString(`}}`)
+	case AlertSlack:
+		buf.WriteString(`{"text":`)
+		writeJSONString(buf, "["+hostname+"] "+summary)
+		buf.WriteByte('}')
+	default:
+		buf.WriteString(`{"dedup_key":`)
+		writeJSONString(buf, dedupKey)
+		buf.WriteString(`,"summary":`)
+		writeJSONString(buf, summary)
+		buf.WriteString(`,"severity":`)
+		writeJSONString(buf, severity.String())
+		buf.WriteString(`,"prefix":`)
+		writeJSONString(buf, prefix)
+		buf.WriteString(`,"host":`)
+		writeJSONString(buf, hostname)
+		buf.WriteString(`,"time":`)
+		writeJSONString(buf, ts.UTC().Format(time.RFC3339Nano))
+		buf.WriteString(`,"context":`)
+		writeJSONObject(buf, ctx)
+		buf.WriteByte('}')
+	}
+	return buf.Bytes()
+}
+
+// alertDedupKey hashes the given parts into a dedup key
+func alertDedupKey(parts ...string) string {
+	h := sha1.New()
+	for _, part := range parts {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func alertComponent(prefix string) string {
+	if prefix == "" {
+		return "(no prefix)"
+	}
+	return prefix
+}
@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAlertServer(t *testing.T) (*httptest.Server, chan map[string]interface{}) {
+	alerts := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		var alert map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&alert))
+		alerts <- alert
+	}))
+	return server, alerts
+}
+
+func TestAlertReporterFatal(t *testing.T) {
+	server, alerts := newAlertServer(t)
+	defer server.Close()
+
+	a := NewAlertReporter(server.URL, &AlertOptions{Format: AlertPagerDuty, RoutingKey: "key"})
+	report := func(host string) {
+		op := ops.Begin("name").Set("cvarA", "a")
+		err := errors.New("unable to dial %v", host)
+		op.End()
+		ctx := ops.AsMap(err, true)
+		ctx["prefix"] = "myprefix"
+		a.Report(err, FATAL, ctx)
+	}
+	for i := 0; i < 3; i++ {
+		// same description and location, so deduplicated
+		report("www.google.com")
+	}
+	a.Report(errors.New("Some error"), ERROR, map[string]interface{}{"prefix": "myprefix"})
+	assert.NoError(t, a.Close())
+	assert.Len(t, alerts, 1)
+
+	alert := <-alerts
+	assert.Equal(t, "key", alert["routing_key"])
+	assert.Equal(t, "trigger", alert["event_action"])
+	assert.Len(t, alert["dedup_key"], 40)
+	payload := alert["payload"].(map[string]interface{})
+	assert.Equal(t, "FATAL in myprefix: unable to dial www.google.com", payload["summary"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "myprefix", payload["component"])
+	assert.Equal(t, "a", payload["custom_details"].(map[string]interface{})["cvarA"])
+}
+
+func TestAlertReporterErrorRate(t *testing.T) {
+	server, alerts := newAlertServer(t)
+	defer server.Close()
+
+	a := NewAlertReporter(server.URL, &AlertOptions{ErrorThreshold: 3, Window: time.Minute, DedupInterval: 10 * time.Minute})
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return now }
+	report := func(prefix string, message string) {
+		a.Report(errors.New("%v", message), ERROR, map[string]interface{}{"prefix": prefix})
+	}
+
+	report("a", "error 1")
+	report("b", "error 1")
+	now = now.Add(time.Minute)
+	report("a", "error 2")
+	report("a", "error 3")
+	assert.Empty(t, alerts, "first error should have left the window")
+	report("a", "error 4")
+	alert := <-alerts
+	assert.Equal(t, "3 errors in a within 1m0s, last: error 4", alert["summary"])
+	assert.Equal(t, "ERROR", alert["severity"])
+	assert.Equal(t, "a", alert["prefix"])
+	assert.Equal(t, "2020-01-01T00:01:00Z", alert["time"])
+	dedupKey := alert["dedup_key"]
+
+	report("a", "error 5")
+	now = now.Add(5 * time.Minute)
+	report("a", "error 6")
+	report("a", "error 7")
+	report("a", "error 8")
+	report("b", "error 2")
+	assert.Empty(t, alerts, "alerts should be deduplicated")
+
+	now = now.Add(5 * time.Minute)
+	report("a", "error 9")
+	report("a", "error 10")
+	report("a", "error 11")
+	assert.NoError(t, a.Close())
+	alert = <-alerts
+	assert.Equal(t, "3 errors in a within 1m0s, last: error 11", alert["summary"])
+	assert.Equal(t, dedupKey, alert["dedup_key"])
+}
+
+func TestAlertReporterSlack(t *testing.T) {
+	a := &AlertReporter{opts: AlertOptions{Format: AlertSlack}}
+	assert.Equal(t, `{"text":"[`+hostname+`] FATAL in (no prefix): boom"}`, string(a.encode("key", "FATAL in (no prefix): boom", FATAL, "", time.Now(), nil)))
+}
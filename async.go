@@ -0,0 +1,379 @@
+package golog
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy determines what an AsyncWriter does with entries when its
+// queue is full
+type OverflowPolicy int
+
+const (
+	// Block blocks the logging goroutine until there's room in the queue
+	Block OverflowPolicy = iota
+
+	// Drop drops the newly logged entry, which is counted
+	Drop
+
+	// DropOldest drops the oldest queued entry to make room for the newly
+	// logged one
+	DropOldest
+
+	// DropLowestSeverity drops the oldest of the queued entries with the lowest
+	// severity to make room for the newly logged one, unless the new entry's
+	// severity is lower still, in which case it's dropped instead. This keeps
+	// errors flowing while DEBUG chatter is shed.
+	DropLowestSeverity
+)
+
+// AsyncOptions configures an AsyncWriter
+type AsyncOptions struct {
+	// QueueSize is the maximum number of queued entries, defaults to 1024
+	QueueSize int
+
+	// Overflow is the policy for entries logged while the queue is full,
+	// defaults to Block. With any other policy, logging never waits for the
+	// underlying output.
+	Overflow OverflowPolicy
+
+	// LockFree uses a lock-free ring buffer instead of a channel as the queue,
+	// for services logging more than about 100k entries per second, where the
+	// channel's lock becomes contended. The queue size is rounded up to a power
+	// of two, and as producers can't wait on a lock-free queue, entries logged
+	// while it's full are always dropped.
+	LockFree bool
+}
+
+// AsyncWriter is an output that queues entries in a bounded queue and
+// formats and writes them to the underlying output on a background goroutine,
+// taking formatting and I/O off the logging goroutines. Call Flush to wait for
+// queued entries to be written, e.g. before exiting, and Close to stop the
+// background goroutine.
+type AsyncWriter struct {
+	out           Sink
+	overflow      OverflowPolicy
+	queue         chan Entry
+	ring          *entryQueue
+	evicting      *evictingQueue
+	pop           func() (Entry, bool)
+	idle          int32
+	wake          chan struct{}
+	flushRequests chan chan struct{}
+	closeOnce     sync.Once
+	closed        chan struct{}
+	done          chan struct{}
+	dropped       uint64
+
+	droppedMx         sync.Mutex
+	droppedBySeverity map[Severity]uint64
+}
+
+// AsyncOutput creates an AsyncWriter that writes to out. If out isn't an
+// EntryWriter, entries are formatted with the Formatter of the logger that
+// logged them. opts may be nil. For example:
+//
+//	out := golog.AsyncOutput(os.Stderr, &golog.AsyncOptions{QueueSize: 10000, Overflow: golog.Drop})
+//	golog.SetOutputs(out, out)
+//	defer out.Close()
+func AsyncOutput(out io.Writer, opts *AsyncOptions) *AsyncWriter {
+	var o AsyncOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+	w := &AsyncWriter{
+		out:           Sink{Out: out},
+		overflow:      o.Overflow,
+		flushRequests: make(chan chan struct{}),
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	switch {
+	case o.LockFree:
+		w.ring = newEntryQueue(o.QueueSize)
+		w.pop = w.ring.pop
+	case o.Overflow == DropOldest || o.Overflow == DropLowestSeverity:
+		w.evicting = newEvictingQueue(o.QueueSize, o.Overflow)
+		w.pop = w.evicting.pop
+	}
+	if w.pop != nil {
+		w.wake = make(chan struct{}, 1)
+		go w.runPolled()
+		return w
+	}
+	w.queue = make(chan Entry, o.QueueSize)
+	go w.run()
+	return w
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	return len(p), w.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter, queueing e
+func (w *AsyncWriter) WriteEntry(e Entry) error {
+	select {
+	case <-w.closed:
+		return errClosed
+	default:
+	}
+	if w.ring != nil {
+		if !w.ring.push(e) {
+			w.drop(e)
+			return nil
+		}
+		w.wakeUp()
+		return nil
+	}
+	if w.evicting != nil {
+		if dropped, ok := w.evicting.push(e); ok {
+			w.drop(dropped)
+		}
+		w.wakeUp()
+		return nil
+	}
+	if w.overflow == Drop {
+		select {
+		case w.queue <- e:
+		default:
+			w.drop(e)
+		}
+		return nil
+	}
+	select {
+	case w.queue <- e:
+		return nil
+	case <-w.closed:
+		return errClosed
+	}
+}
+
+// wakeUp wakes up the background goroutine if it's waiting for entries to be
+// pushed to a polled queue
+func (w *AsyncWriter) wakeUp() {
+	if atomic.LoadInt32(&w.idle) == 1 {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// drop counts e as dropped
+func (w *AsyncWriter) drop(e Entry) {
+	atomic.AddUint64(&w.dropped, 1)
+	w.droppedMx.Lock()
+	if w.droppedBySeverity == nil {
+		w.droppedBySeverity = make(map[Severity]uint64)
+	}
+	w.droppedBySeverity[e.Severity]++
+	w.droppedMx.Unlock()
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// DroppedBySeverity returns the number of entries dropped because the queue was
+// full for each severity. Entries written with Write have no severity and are
+// counted under 0.
+func (w *AsyncWriter) DroppedBySeverity() map[Severity]uint64 {
+	w.droppedMx.Lock()
+	defer w.droppedMx.Unlock()
+	result := make(map[Severity]uint64, len(w.droppedBySeverity))
+	for severity, dropped := range w.droppedBySeverity {
+		result[severity] = dropped
+	}
+	return result
+}
+
+// Flush waits for all entries queued before the call to be written, and then
+// flushes the underlying output if it can be flushed.
+func (w *AsyncWriter) Flush() {
+	ack := make(chan struct{})
+	select {
+	case w.flushRequests <- ack:
+		<-ack
+	case <-w.done:
+	}
+	if err := flushOutput(w.out.Out); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+// Reopen implements Reopener, writing all queued entries and then reopening
+// the underlying output if it can be reopened.
+func (w *AsyncWriter) Reopen() error {
+	w.Flush()
+	return reopen(w.out.Out)
+}
+
+// Close writes all queued entries and stops the background goroutine
+func (w *AsyncWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	drain := func() {
+		for {
+			select {
+			case e := <-w.queue:
+				w.write(e)
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case e := <-w.queue:
+			w.write(e)
+		case ack := <-w.flushRequests:
+			drain()
+			close(ack)
+		case <-w.closed:
+			drain()
+			return
+		}
+	}
+}
+
+// runPolled is like run for queues that are polled with pop, sleeping until
+// woken up by a producer when the queue is empty.
+func (w *AsyncWriter) runPolled() {
+	defer close(w.done)
+	drain := func() bool {
+		drained := false
+		for {
+			e, ok := w.pop()
+			if !ok {
+				return drained
+			}
+			w.write(e)
+			drained = true
+		}
+	}
+	for {
+		drained := drain()
+		select {
+		case ack := <-w.flushRequests:
+			drain()
+			close(ack)
+			continue
+		case <-w.closed:
+			drain()
+			return
+		default:
+		}
+		if drained {
+			continue
+		}
+		// Announce that we're going idle before checking the queue once more,
+		// so that a concurrent push either gets drained or wakes us up.
+		atomic.StoreInt32(&w.idle, 1)
+		if drain() {
+			atomic.StoreInt32(&w.idle, 0)
+			continue
+		}
+		select {
+		case <-w.wake:
+		case ack := <-w.flushRequests:
+			drain()
+			close(ack)
+		case <-w.closed:
+			drain()
+			return
+		}
+		atomic.StoreInt32(&w.idle, 0)
+	}
+}
+
+func (w *AsyncWriter) write(e Entry) {
+	if err := w.out.write(e); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+// evictingQueue is a bounded FIFO queue of entries that makes room for new
+// entries when it's full by evicting a queued entry according to its
+// OverflowPolicy, so that pushing never waits for the consumer.
+type evictingQueue struct {
+	mx      sync.Mutex
+	policy  OverflowPolicy
+	entries []Entry
+	head    int
+	n       int
+}
+
+func newEvictingQueue(size int, policy OverflowPolicy) *evictingQueue {
+	return &evictingQueue{policy: policy, entries: make([]Entry, size)}
+}
+
+// push adds e to the queue, returning the entry that was dropped to make room
+// for it, if any
+func (q *evictingQueue) push(e Entry) (Entry, bool) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	if q.n < len(q.entries) {
+		*q.at(q.n) = e
+		q.n++
+		return Entry{}, false
+	}
+	victim := 0
+	if q.policy == DropLowestSeverity {
+		for i := 1; i < q.n; i++ {
+			if q.at(i).Severity < q.at(victim).Severity {
+				victim = i
+			}
+		}
+		if e.Severity < q.at(victim).Severity {
+			return e, true
+		}
+	}
+	dropped := *q.at(victim)
+	if victim == 0 {
+		// the oldest slot becomes the newest
+		q.head = (q.head + 1) % len(q.entries)
+	} else {
+		for i := victim; i < q.n-1; i++ {
+			*q.at(i) = *q.at(i + 1)
+		}
+	}
+	*q.at(q.n - 1) = e
+	return dropped, true
+}
+
+// pop removes the oldest entry from the queue, returning false if the queue
+// is empty
+func (q *evictingQueue) pop() (Entry, bool) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	if q.n == 0 {
+		return Entry{}, false
+	}
+	slot := q.at(0)
+	e := *slot
+	*slot = Entry{}
+	q.head = (q.head + 1) % len(q.entries)
+	q.n--
+	return e, true
+}
+
+// at returns the i-th queued entry, counting from the oldest
+func (q *evictingQueue) at(i int) *Entry {
+	return &q.entries[(q.head+i)%len(q.entries)]
+}
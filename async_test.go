@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingRecorder struct {
+	entryRecorder
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRecorder) WriteEntry(e Entry) error {
+	select {
+	case r.started <- struct{}{}:
+	default:
+	}
+	<-r.release
+	return r.entryRecorder.WriteEntry(e)
+}
+
+func TestAsyncOutput(t *testing.T) {
+	r := &entryRecorder{}
+	out := AsyncOutput(r, &AsyncOptions{QueueSize: 10})
+	reset := SetOutputs(out, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				l.Debug("Hello")
+			}
+		}()
+	}
+	wg.Wait()
+	out.Flush()
+	assert.Len(t, r.entries, 100, "blocking policy shouldn't drop entries")
+	assert.Equal(t, "myprefix", r.entries[0].Prefix)
+
+	l.Debug("last")
+	assert.NoError(t, out.Close())
+	assert.Len(t, r.entries, 101, "close should write queued entries")
+	assert.Equal(t, errClosed, out.WriteEntry(Entry{}))
+	out.Flush()
+}
+
+func TestAsyncOutputDrop(t *testing.T) {
+	r := &blockingRecorder{started: make(chan struct{}, 1), release: make(chan struct{})}
+	out := AsyncOutput(r, &AsyncOptions{QueueSize: 2, Overflow: Drop})
+	assert.NoError(t, out.WriteEntry(Entry{Message: "Hello"}))
+	<-r.started
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, out.WriteEntry(Entry{Message: "Hello"}))
+	}
+	// one entry is being written, two are queued
+	assert.EqualValues(t, 7, out.Dropped())
+	assert.Equal(t, map[Severity]uint64{0: 7}, out.DroppedBySeverity())
+	close(r.release)
+	out.Close()
+	assert.Len(t, r.entries, 3)
+}
+
+func TestAsyncOutputLockFree(t *testing.T) {
+	r := &entryRecorder{}
+	out := AsyncOutput(r, &AsyncOptions{QueueSize: 1000, LockFree: true})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				out.WriteEntry(Entry{Message: "Hello"})
+			}
+		}()
+	}
+	wg.Wait()
+	out.Flush()
+	assert.Len(t, r.entries, 800-int(out.Dropped()))
+
+	blocking := &blockingRecorder{started: make(chan struct{}, 1), release: make(chan struct{})}
+	out = AsyncOutput(blocking, &AsyncOptions{QueueSize: 2, LockFree: true})
+	assert.NoError(t, out.WriteEntry(Entry{Message: "Hello"}))
+	<-blocking.started
+	for i := 0; i < 9; i++ {
+		assert.NoError(t, out.WriteEntry(Entry{Message: "Hello"}))
+	}
+	assert.EqualValues(t, 7, out.Dropped())
+	close(blocking.release)
+	out.Close()
+	assert.Len(t, blocking.entries, 3)
+}
+
+func TestAsyncOutputDropOldest(t *testing.T) {
+	r := &blockingRecorder{started: make(chan struct{}, 1), release: make(chan struct{})}
+	out := AsyncOutput(r, &AsyncOptions{QueueSize: 3, Overflow: DropOldest})
+	assert.NoError(t, out.WriteEntry(Entry{Severity: DEBUG, Message: "0"}))
+	<-r.started
+	for i := 1; i <= 6; i++ {
+		assert.NoError(t, out.WriteEntry(Entry{Severity: DEBUG, Message: strconv.Itoa(i)}))
+	}
+	assert.EqualValues(t, 3, out.Dropped())
+	assert.Equal(t, map[Severity]uint64{DEBUG: 3}, out.DroppedBySeverity())
+	close(r.release)
+	out.Close()
+	var messages []string
+	for _, e := range r.entries {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"0", "4", "5", "6"}, messages)
+}
+
+func TestAsyncOutputDropLowestSeverity(t *testing.T) {
+	r := &blockingRecorder{started: make(chan struct{}, 1), release: make(chan struct{})}
+	out := AsyncOutput(r, &AsyncOptions{QueueSize: 3, Overflow: DropLowestSeverity})
+	assert.NoError(t, out.WriteEntry(Entry{Severity: DEBUG, Message: "first"}))
+	<-r.started
+	for _, e := range []Entry{
+		{Severity: DEBUG, Message: "debug1"},
+		{Severity: ERROR, Message: "error1"},
+		{Severity: DEBUG, Message: "debug2"},
+		{Severity: WARN, Message: "warn"},
+		{Severity: TRACE, Message: "trace"},
+		{Severity: ERROR, Message: "error2"},
+		{Severity: ERROR, Message: "error3"},
+	} {
+		assert.NoError(t, out.WriteEntry(e))
+	}
+	assert.EqualValues(t, 4, out.Dropped())
+	assert.Equal(t, map[Severity]uint64{TRACE: 1, DEBUG: 2, WARN: 1}, out.DroppedBySeverity())
+	close(r.release)
+	out.Close()
+	var messages []string
+	for _, e := range r.entries {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"first", "error1", "error2", "error3"}, messages)
+}
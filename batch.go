@@ -0,0 +1,155 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	dropReportInterval = 10 * time.Second
+)
+
+var (
+	errQueueFull = errors.New("queue full, dropping entries")
+	errClosed    = errors.New("output closed")
+)
+
+// dropCounter counts entries dropped because a queue was full. Rather than
+// reporting every dropped entry, which would flood stderr exactly when the
+// output can't keep up, it reports the first drop and then at most once every
+// dropReportInterval.
+type dropCounter struct {
+	dropped    uint64
+	lastReport int64
+}
+
+// drop counts an entry as dropped, reporting it if it's time to
+func (d *dropCounter) drop() {
+	dropped := atomic.AddUint64(&d.dropped, 1)
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&d.lastReport)
+	if last != 0 && now-last < int64(dropReportInterval) {
+		return
+	}
+	if atomic.CompareAndSwapInt64(&d.lastReport, last, now) {
+		errorOnLogging(fmt.Errorf("%v (%d dropped so far)", errQueueFull, dropped))
+	}
+}
+
+// Dropped returns the number of entries dropped because the queue was full
+func (d *dropCounter) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// batcher queues entries and hands them to a send function in batches, either
+// once maxBatchSize entries have accumulated or every flushInterval.
+type batcher struct {
+	dropCounter
+	queue         chan Entry
+	maxBatchSize  int
+	flushInterval time.Duration
+	send          func(batch []Entry)
+	flushRequests chan chan struct{}
+	closeOnce     sync.Once
+	closed        chan struct{}
+	done          chan struct{}
+}
+
+func newBatcher(queueSize int, maxBatchSize int, flushInterval time.Duration, send func(batch []Entry)) *batcher {
+	b := &batcher{
+		queue:         make(chan Entry, queueSize),
+		maxBatchSize:  maxBatchSize,
+		flushInterval: flushInterval,
+		send:          send,
+		flushRequests: make(chan chan struct{}),
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add queues the given entry without blocking, dropping it if the queue is
+// full
+func (b *batcher) add(e Entry) error {
+	select {
+	case <-b.closed:
+		return errClosed
+	default:
+	}
+	select {
+	case b.queue <- e:
+	default:
+		b.drop()
+	}
+	return nil
+}
+
+// Flush sends all queued entries and waits for the send to finish
+func (b *batcher) Flush() {
+	ack := make(chan struct{})
+	select {
+	case b.flushRequests <- ack:
+		<-ack
+	case <-b.done:
+	}
+}
+
+// Close sends all queued entries and stops the batcher
+func (b *batcher) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	<-b.done
+	return nil
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, b.maxBatchSize)
+	sendBatch := func() {
+		if len(batch) > 0 {
+			b.send(batch)
+			batch = make([]Entry, 0, b.maxBatchSize)
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case e := <-b.queue:
+				batch = append(batch, e)
+				if len(batch) >= b.maxBatchSize {
+					sendBatch()
+				}
+			default:
+				sendBatch()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case e := <-b.queue:
+			batch = append(batch, e)
+			if len(batch) >= b.maxBatchSize {
+				sendBatch()
+			}
+		case <-ticker.C:
+			sendBatch()
+		case ack := <-b.flushRequests:
+			drain()
+			close(ack)
+		case <-b.closed:
+			drain()
+			return
+		}
+	}
+}
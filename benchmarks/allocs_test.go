@@ -0,0 +1,41 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAllocationBudgets fails if common logging calls allocate more than they
+// used to, acting as a regression gate for changes to the hot path. Lower the
+// budgets when an optimization lands.
+func TestAllocationBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations aren't representative with the race detector")
+	}
+	defer discardOutputs()()
+	text := golog.LoggerFor("benchmarks.text")
+	json := golog.LoggerFor("benchmarks.json")
+	json.SetFormatter(&golog.JSONFormatter{})
+	typed := golog.LoggerFor("benchmarks.typed").With(golog.Int("attempt", 3), golog.String("user", "bob"), golog.Bool("cached", true), golog.Duration("elapsed", time.Millisecond))
+	disabled := golog.LoggerFor("benchmarks.disabled")
+	golog.SetPrefixLevel("benchmarks.disabled", golog.ERROR)
+	defer golog.ResetPrefixLevels()
+
+	budgets := []struct {
+		name   string
+		allocs float64
+		log    func()
+	}{
+		{"Debugf", 6, func() { text.Debugf("Handled request %d in %v", 1, time.Millisecond) }},
+		{"DebugfJSON", 7, func() { json.Debugf("Handled request %d in %v", 1, time.Millisecond) }},
+		{"DebugTypedFields", 6, func() { typed.Debug("Handled request") }},
+		{"DebugfDisabled", 1, func() { disabled.Debugf("Handled request %d in %v", 1, time.Millisecond) }},
+	}
+	for _, b := range budgets {
+		allocs := testing.AllocsPerRun(100, b.log)
+		assert.True(t, allocs <= b.allocs, "%v allocated %v times per call, budget is %v", b.name, allocs, b.allocs)
+	}
+}
@@ -0,0 +1,21 @@
+// Package benchmarks contains benchmarks of realistic golog usage, so that the
+// performance impact of changes to formatting and locking can be measured and
+// compared across releases. Run them with:
+//
+//	go test -run=NONE -bench=. -benchmem ./benchmarks
+//
+// and compare the results of two versions with benchstat. The benchmarks write
+// to ioutil.Discard, so they measure the cost of logging without I/O.
+package benchmarks
+
+import (
+	"io/ioutil"
+
+	"github.com/getlantern/golog"
+)
+
+// discardOutputs sets both outputs to ioutil.Discard and returns a function
+// that restores the previous outputs.
+func discardOutputs() func() {
+	return golog.SetOutputs(ioutil.Discard, ioutil.Discard)
+}
@@ -0,0 +1,118 @@
+package benchmarks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/golog"
+	"github.com/getlantern/ops"
+)
+
+func BenchmarkDebugf(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("Handled request %d in %v", i, time.Millisecond)
+	}
+}
+
+func BenchmarkDebugfJSON(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	l.SetFormatter(&golog.JSONFormatter{})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("Handled request %d in %v", i, time.Millisecond)
+	}
+}
+
+func BenchmarkDebugfWithOpsContext(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	op := ops.Begin("request").Set("client_ip", "10.0.0.1").Set("method", "GET").Set("path", "/index.html")
+	defer op.End()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("Handled request %d in %v", i, time.Millisecond)
+	}
+}
+
+func BenchmarkDebugfDisabled(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	golog.SetPrefixLevel("benchmarks", golog.ERROR)
+	defer golog.ResetPrefixLevels()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("Handled request %d in %v", i, time.Millisecond)
+	}
+}
+
+func BenchmarkErrorWithCauseChain(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	err := errors.New("connection refused")
+	for i := 0; i < 10; i++ {
+		err = errors.New("layer %d failed: %v", i, err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.Error(err)
+	}
+}
+
+func BenchmarkErrorWithStdlibWrapping(b *testing.B) {
+	defer discardOutputs()()
+	l := golog.LoggerFor("benchmarks")
+	err := fmt.Errorf("connection refused")
+	for i := 0; i < 10; i++ {
+		err = fmt.Errorf("layer %d failed: %w", i, err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithError(err).Error("request failed")
+	}
+}
+
+func BenchmarkConcurrentLoggers(b *testing.B) {
+	defer discardOutputs()()
+	loggers := make([]golog.Logger, 8)
+	for i := range loggers {
+		loggers[i] = golog.LoggerFor(fmt.Sprintf("benchmarks.%d", i)).WithField("worker", i)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	var next int64
+	b.RunParallel(func(pb *testing.PB) {
+		l := loggers[int(atomic.AddInt64(&next, 1))%len(loggers)]
+		for i := 0; pb.Next(); i++ {
+			l.Debugf("Handled request %d", i)
+		}
+	})
+}
+
+func BenchmarkAsyncOutput(b *testing.B) {
+	out := golog.AsyncOutput(ioutil.Discard, &golog.AsyncOptions{QueueSize: 10000, Overflow: golog.DropOldest})
+	reset := golog.SetOutputs(out, out)
+	defer reset()
+	defer out.Close()
+	l := golog.LoggerFor("benchmarks")
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			l.Debugf("Handled request %d", i)
+		}
+	})
+}
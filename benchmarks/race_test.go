@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package benchmarks
+
+// raceEnabled is true when running with the race detector, which adds
+// allocations of its own
+const raceEnabled = true
@@ -0,0 +1,128 @@
+package golog
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BufferedWriter is an output that buffers writes to the underlying output
+// and flushes them once the buffer reaches a size or an interval has passed
+// since the first buffered write, whichever comes first, so that files and
+// network outputs aren't hit with one small write per entry. Writes larger
+// than the buffer are flushed right away.
+type BufferedWriter struct {
+	out      io.Writer
+	size     int
+	interval time.Duration
+
+	mx     sync.Mutex
+	buf    []byte
+	timer  *time.Timer
+	closed bool
+}
+
+// BufferedOutput creates a BufferedWriter that writes to out in chunks of up
+// to size bytes, at least every interval. For example:
+//
+//	out := golog.BufferedOutput(file, 256*1024, time.Second)
+//	golog.SetOutputs(out, out)
+//	defer golog.Flush()
+func BufferedOutput(out io.Writer, size int, interval time.Duration) *BufferedWriter {
+	return &BufferedWriter{
+		out:      out,
+		size:     size,
+		interval: interval,
+		buf:      make([]byte, 0, size),
+	}
+}
+
+// Write implements io.Writer
+func (w *BufferedWriter) Write(p []byte) (int, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if w.closed {
+		return 0, errClosed
+	}
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.size {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	} else if w.timer == nil && w.interval > 0 {
+		w.timer = time.AfterFunc(w.interval, func() {
+			if err := w.Flush(); err != nil {
+				errorOnLogging(err)
+			}
+		})
+	}
+	return len(p), nil
+}
+
+// Flush writes all buffered data to the underlying output
+func (w *BufferedWriter) Flush() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	return w.flush()
+}
+
+// Reopen implements Reopener, writing all buffered data and then reopening
+// the underlying output if it can be reopened.
+func (w *BufferedWriter) Reopen() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	err := w.flush()
+	if reopenErr := reopen(w.out); err == nil {
+		err = reopenErr
+	}
+	return err
+}
+
+// Close flushes the buffer and stops accepting writes. It doesn't close the
+// underlying output.
+func (w *BufferedWriter) Close() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	w.closed = true
+	return w.flush()
+}
+
+func (w *BufferedWriter) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.buf)
+	w.buf = w.buf[:0]
+	return err
+}
+
+// Flush flushes the current outputs, including the sinks of a Multi, waiting
+// for outputs like AsyncWriter, BufferedWriter and the batching network
+// outputs to write everything they hold. Call it before exiting. The first
+// error is returned.
+func Flush() error {
+	outs := GetOutputs()
+	err := flushOutput(outs.ErrorOut)
+	if debugErr := flushOutput(outs.DebugOut); err == nil {
+		err = debugErr
+	}
+	return err
+}
+
+// flushOutput flushes out if it has a Flush method, or its sinks if it's a
+// Multi
+func flushOutput(out io.Writer) error {
+	switch o := out.(type) {
+	case *Multi:
+		return o.eachSink(flushOutput)
+	case interface{ Flush() error }:
+		return o.Flush()
+	case interface{ Flush() }:
+		o.Flush()
+	}
+	return nil
+}
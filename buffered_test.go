@@ -0,0 +1,63 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestBufferedOutput(t *testing.T) {
+	out := &countingWriter{}
+	w := BufferedOutput(out, 10, time.Hour)
+	w.Write([]byte("12345"))
+	w.Write([]byte("6789"))
+	assert.Equal(t, 0, out.writes, "should buffer until size is reached")
+	w.Write([]byte("0abc"))
+	assert.Equal(t, 1, out.writes)
+	assert.Equal(t, "1234567890abc", out.String())
+
+	w.Write([]byte("def"))
+	assert.NoError(t, w.Close())
+	assert.Equal(t, "1234567890abcdef", out.String())
+	_, err := w.Write([]byte("x"))
+	assert.Equal(t, errClosed, err)
+}
+
+func TestBufferedOutputInterval(t *testing.T) {
+	out := newBuffer()
+	w := BufferedOutput(out, 1024, 10*time.Millisecond)
+	w.Write([]byte("Hello"))
+	assert.Empty(t, out.String())
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, "Hello", out.String())
+}
+
+func TestFlush(t *testing.T) {
+	out := &countingWriter{}
+	buffered := BufferedOutput(out, 1024, time.Hour)
+	r := &entryRecorder{}
+	async := AsyncOutput(MultiOutput(Sink{Out: buffered, Formatter: &customFormatter{}}, Sink{Out: r}), nil)
+	defer async.Close()
+	reset := SetOutputs(async, async)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.Debug("Hello")
+	l.Debug("world")
+	assert.NoError(t, Flush())
+	assert.Regexp(t, `^DEBUG\|myprefix\|buffered_test.go\|\d+\|Hello\|0\nDEBUG\|myprefix\|buffered_test.go\|\d+\|world\|0\n$`, out.String())
+	assert.Equal(t, 1, out.writes)
+	assert.Len(t, r.entries, 2)
+}
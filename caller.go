@@ -0,0 +1,36 @@
+package golog
+
+import "sync/atomic"
+
+var (
+	callersDisabled int32
+)
+
+// SetCaptureCallers enables or disables capturing the file and line of log
+// calls for all loggers. Capturing is enabled by default. When disabled,
+// entries have an empty File and a Line of 0.
+func SetCaptureCallers(enabled bool) {
+	var disabled int32
+	if !enabled {
+		disabled = 1
+	}
+	atomic.StoreInt32(&callersDisabled, disabled)
+}
+
+func (l *logger) WithCallerSkip(skip int) Logger {
+	c := l.child(l.prefix, l.fields)
+	c.callerSkip += skip
+	return c
+}
+
+func (l *logger) WithCaller(file string, line int) Logger {
+	c := l.child(l.prefix, l.fields)
+	c.callerFile, c.callerLine = file, line
+	return c
+}
+
+func (l *logger) WithoutCaller() Logger {
+	c := l.child(l.prefix, l.fields)
+	c.noCaller = true
+	return c
+}
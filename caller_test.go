@@ -0,0 +1,43 @@
+package golog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func logThroughHelper(l Logger, msg string) {
+	l.Debug(msg)
+}
+
+func TestCallerCapture(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	logThroughHelper(l, "wrapped")
+	logThroughHelper(l.WithCallerSkip(1).WithField("a", 1), "skipped")
+	l.WithoutCaller().Debug("no caller")
+	l.WithCaller("other.go", 42).Debug("given caller")
+	SetCaptureCallers(false)
+	l.Debug("disabled")
+	SetCaptureCallers(true)
+	l.Debug("enabled")
+
+	assert.Regexp(t, `^DEBUG myprefix: caller_test.go:12 wrapped
+DEBUG myprefix: caller_test.go:2\d skipped \[a=1\]
+DEBUG myprefix: no caller
+DEBUG myprefix: other.go:42 given caller
+DEBUG myprefix: disabled
+DEBUG myprefix: caller_test.go:\d+ enabled
+$`, out.String())
+
+	out.Reset()
+	l.SetFormatter(&JSONFormatter{})
+	l.WithoutCaller().Debug("no caller")
+	assert.NotContains(t, out.String(), `"caller"`)
+}
@@ -0,0 +1,116 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+	ansiBold   = "\x1b[1m"
+)
+
+// ColorMode controls whether the default text output uses ANSI colors.
+type ColorMode int32
+
+const (
+	// ColorNever disables colors (the default)
+	ColorNever ColorMode = iota
+
+	// ColorAuto enables colors for outputs that are terminals
+	ColorAuto
+
+	// ColorAlways enables colors for all outputs
+	ColorAlways
+)
+
+var (
+	colorMode int32
+	ttys      sync.Map
+)
+
+// SetColorMode sets whether the default text output renders severities in
+// color and dims the caller and context.
+func SetColorMode(mode ColorMode) {
+	atomic.StoreInt32(&colorMode, int32(mode))
+}
+
+// GetColorMode returns the current ColorMode
+func GetColorMode() ColorMode {
+	return ColorMode(atomic.LoadInt32(&colorMode))
+}
+
+// colorFormatter is implemented by Formatters that support the ColorMode
+type colorFormatter interface {
+	Formatter
+
+	// format formats the given entry, using color if colored is true
+	format(e Entry, colored bool) []byte
+}
+
+// useColor indicates whether or not output written to out should be colored
+func useColor(out io.Writer) bool {
+	switch GetColorMode() {
+	case ColorAlways:
+		return true
+	case ColorAuto:
+		return isTerminal(out)
+	default:
+		return false
+	}
+}
+
+// isTerminal indicates whether out is a terminal, caching the result per file
+func isTerminal(out io.Writer) bool {
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	if result, found := ttys.Load(f); found {
+		return result.(bool)
+	}
+	result := false
+	if fi, err := f.Stat(); err == nil {
+		result = fi.Mode()&os.ModeCharDevice != 0
+	}
+	ttys.Store(f, result)
+	return result
+}
+
+// severityColor returns the ANSI escape sequence for coloring the given
+// severity.
+func severityColor(severity Severity) string {
+	switch {
+	case severity >= FATAL:
+		return ansiBold + ansiRed
+	case severity >= ERROR:
+		return ansiRed
+	case severity >= WARN:
+		return ansiYellow
+	case severity >= INFO:
+		return ansiGreen
+	case severity >= DEBUG:
+		return ansiCyan
+	default:
+		return ansiBlue
+	}
+}
+
+func writeSeverity(buf *bytes.Buffer, severity Severity, colored bool) {
+	if !colored {
+		buf.WriteString(severity.String())
+		return
+	}
+	buf.WriteString(severityColor(severity))
+	buf.WriteString(severity.String())
+	buf.WriteString(ansiReset)
+}
@@ -0,0 +1,47 @@
+package golog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorAlways(t *testing.T) {
+	SetColorMode(ColorAlways)
+	defer SetColorMode(ColorNever)
+
+	out := &bytes.Buffer{}
+	SetOutputs(out, out)
+	l := LoggerFor("myprefix")
+	l.Debug("Hello world")
+	defer ops.Begin("name").End()
+	l.Debugf("Hello %v", true)
+	l.Error("Hello error")
+	lines := regexp.MustCompile(`go:[0-9]+`).ReplaceAllString(out.String(), "go:1")
+	assert.Equal(t, "\x1b[36mDEBUG\x1b[0m myprefix: \x1b[2mcolor_test.go:1\x1b[0m Hello world\n"+
+		"\x1b[36mDEBUG\x1b[0m myprefix: \x1b[2mcolor_test.go:1\x1b[0m Hello true\x1b[2m [op=name root_op=name]\x1b[0m\n"+
+		"\x1b[31mERROR\x1b[0m myprefix: \x1b[2mcolor_test.go:1\x1b[0m Hello error\x1b[2m [op=name root_op=name]\x1b[0m\n", lines)
+}
+
+func TestColorAuto(t *testing.T) {
+	SetColorMode(ColorAuto)
+	defer SetColorMode(ColorNever)
+
+	out := &bytes.Buffer{}
+	SetOutputs(ioutil.Discard, out)
+	LoggerFor("myprefix").Debug("Hello world")
+	assert.NotContains(t, out.String(), "\x1b", "buffer is not a terminal")
+
+	f, err := ioutil.TempFile("", "colortest")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	assert.False(t, useColor(f), "regular file is not a terminal")
+}
@@ -0,0 +1,142 @@
+package golog
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	contextExtractorsMx sync.RWMutex
+	contextExtractors   []ContextExtractor
+
+	fallbackLoggerOnce sync.Once
+	fallbackLogger     Logger
+)
+
+type loggerContextKey struct{}
+
+// ContextExtractor extracts fields from a context.Context, which are included
+// in the context of entries logged with the Ctx methods of Logger, e.g.
+// DebugCtx. It returns nil if there's nothing to extract.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// RegisterContextExtractor registers a ContextExtractor that is applied to
+// the contexts passed to all loggers. Fields from later extractors take
+// precedence over earlier ones, and all of them take precedence over the
+// logger's own fields.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	contextExtractorsMx.Lock()
+	contextExtractors = append(contextExtractors, extractor)
+	contextExtractorsMx.Unlock()
+}
+
+// ContextValue returns a ContextExtractor that includes the context value with
+// the given key as the given field, if it's set. For example:
+//
+//	golog.RegisterContextExtractor(golog.ContextValue("request_id", requestIDKey))
+func ContextValue(field string, key interface{}) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		if value := ctx.Value(key); value != nil {
+			return map[string]interface{}{field: value}
+		}
+		return nil
+	}
+}
+
+// DeadlineExtractor is a ContextExtractor that includes the deadline of the
+// context, if it has one, as the "deadline" field.
+func DeadlineExtractor(ctx context.Context) map[string]interface{} {
+	if deadline, ok := ctx.Deadline(); ok {
+		return map[string]interface{}{"deadline": deadline}
+	}
+	return nil
+}
+
+// NewContext returns a copy of ctx that carries the given Logger, for example
+// a per-request logger enriched with WithField, which downstream code
+// retrieves with FromContext.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, l)
+}
+
+// FromContext returns the Logger carried by ctx, or a Logger without prefix if
+// there is none.
+func FromContext(ctx context.Context) Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey{}).(Logger); ok {
+			return l
+		}
+	}
+	fallbackLoggerOnce.Do(func() {
+		fallbackLogger = LoggerFor("")
+	})
+	return fallbackLogger
+}
+
+// withContext returns a logger that includes the fields extracted from ctx,
+// or this logger if there aren't any.
+func (l *logger) withContext(ctx context.Context) *logger {
+	if ctx == nil {
+		return l
+	}
+	contextExtractorsMx.RLock()
+	extractors := contextExtractors
+	contextExtractorsMx.RUnlock()
+
+	var fields map[string]interface{}
+	for _, extractor := range extractors {
+		extracted := extractor(ctx)
+		if len(extracted) == 0 {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]interface{}, len(l.fields)+len(extracted))
+			for key, value := range l.fields {
+				fields[key] = value
+			}
+		}
+		for key, value := range extracted {
+			fields[key] = value
+		}
+	}
+	if fields == nil {
+		return l
+	}
+	return l.child(l.prefix, fields)
+}
+
+func (l *logger) TraceCtx(ctx context.Context, arg interface{}) {
+	if l.IsEnabled(TRACE) {
+		l.withContext(ctx).print(l.getOutputs().DebugOut, 4, TRACE, arg)
+	}
+}
+
+func (l *logger) DebugCtx(ctx context.Context, arg interface{}) {
+	if l.enabled(DEBUG) {
+		l.withContext(ctx).print(l.getOutputs().DebugOut, 4, DEBUG, arg)
+	}
+}
+
+func (l *logger) InfoCtx(ctx context.Context, arg interface{}) {
+	if l.enabled(INFO) {
+		l.withContext(ctx).print(l.getOutputs().DebugOut, 4, INFO, arg)
+	}
+}
+
+func (l *logger) WarnCtx(ctx context.Context, arg interface{}) {
+	if l.enabled(WARN) || hasReporters() {
+		l.withContext(ctx).errorSkipFrames(arg, 1, WARN)
+	}
+}
+
+func (l *logger) ErrorCtx(ctx context.Context, arg interface{}) error {
+	err := l.withContext(ctx).errorSkipFrames(arg, 1, ERROR)
+	recordSpanError(ctx, err, ERROR)
+	return err
+}
+
+func (l *logger) FatalCtx(ctx context.Context, arg interface{}) {
+	err := l.withContext(ctx).errorSkipFrames(arg, 1, FATAL)
+	recordSpanError(ctx, err, FATAL)
+	l.fatal(err)
+}
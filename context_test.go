@@ -0,0 +1,55 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey string
+
+func TestContextExtractors(t *testing.T) {
+	debugOut := &bytes.Buffer{}
+	errorOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	RegisterContextExtractor(ContextValue("request_id", testContextKey("request")))
+	RegisterContextExtractor(DeadlineExtractor)
+
+	l := LoggerFor("myprefix").WithField("user", 5)
+	l.SetFormatter(&TextFormatter{})
+	ctx := context.WithValue(context.Background(), testContextKey("request"), "abc")
+	l.DebugCtx(ctx, "Hello")
+	l.InfoCtx(context.Background(), "world")
+	assert.Regexp(t, `^DEBUG myprefix: context_test.go:\d+ Hello \[request_id=abc user=5\]
+INFO myprefix: context_test.go:\d+ world \[user=5\]
+$`, debugOut.String())
+
+	deadline := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	err := l.ErrorCtx(ctx, "failed")
+	assert.EqualError(t, err, "failed")
+	assert.Regexp(t, `^ERROR myprefix: context_test.go:\d+ failed \[deadline=2030-01-01 00:00:00 \+0000 UTC request_id=abc user=5\]\n$`, errorOut.String())
+}
+
+func TestNewContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+
+	l := LoggerFor("myprefix").WithField("request", "abc")
+	l.SetFormatter(&TextFormatter{})
+	ctx := NewContext(context.Background(), l)
+	assert.Equal(t, l, FromContext(ctx))
+	FromContext(ctx).Debug("Hello")
+	assert.Regexp(t, `^DEBUG myprefix: context_test.go:\d+ Hello \[request=abc\]\n$`, out.String())
+
+	fallback := FromContext(context.Background())
+	assert.NotNil(t, fallback)
+	assert.Equal(t, fallback, FromContext(nil))
+}
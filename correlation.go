@@ -0,0 +1,73 @@
+package golog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// CorrelationIDHeader is the HTTP header that carries correlation IDs
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+var (
+	correlationCounter uint64
+)
+
+// NewCorrelationID generates a random 128 bit correlation ID, encoded as 32
+// hex digits.
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// Extremely unlikely, fall back to something that's still unique
+		// within this process.
+		return strconv.FormatInt(time.Now().UnixNano(), 16) + strconv.FormatUint(atomic.AddUint64(&correlationCounter, 1), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID returns a copy of ctx that carries the given correlation
+// ID, or a new one if id is empty, along with the ID. The Logger carried by
+// ctx, see FromContext, is replaced with one that includes the ID as the
+// "correlation_id" field. For example, in an HTTP handler:
+//
+//	ctx, _ := golog.WithCorrelationID(req.Context(), req.Header.Get(golog.CorrelationIDHeader))
+//	golog.FromContext(ctx).Debug("handling request")
+func WithCorrelationID(ctx context.Context, id string) (context.Context, string) {
+	if id == "" {
+		id = NewCorrelationID()
+	}
+	l := FromContext(ctx).WithField("correlation_id", id)
+	return NewContext(context.WithValue(ctx, correlationIDKey{}, id), l), id
+}
+
+// CorrelationID returns the correlation ID carried by ctx, or "" if there is
+// none.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// SetCorrelationHeader sets the CorrelationIDHeader of an outbound request to
+// the correlation ID carried by ctx, if there is one, so that the receiving
+// service can continue the correlation.
+func SetCorrelationHeader(ctx context.Context, h http.Header) {
+	if id := CorrelationID(ctx); id != "" {
+		h.Set(CorrelationIDHeader, id)
+	}
+}
+
+// CorrelationIDExtractor is a ContextExtractor that includes the correlation
+// ID carried by the context, if there is one, as the "correlation_id" field,
+// for loggers that aren't retrieved with FromContext.
+func CorrelationIDExtractor(ctx context.Context) map[string]interface{} {
+	if id := CorrelationID(ctx); id != "" {
+		return map[string]interface{}{"correlation_id": id}
+	}
+	return nil
+}
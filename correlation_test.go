@@ -0,0 +1,41 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationID(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+
+	id := NewCorrelationID()
+	assert.Regexp(t, `^[0-9a-f]{32}$`, id)
+	assert.NotEqual(t, id, NewCorrelationID())
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	ctx, id := WithCorrelationID(NewContext(context.Background(), l), "")
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, CorrelationID(ctx))
+	FromContext(ctx).Debug("Hello")
+	assert.Regexp(t, `^DEBUG myprefix: correlation_test.go:\d+ Hello \[correlation_id=`+id+`\]\n$`, out.String())
+
+	ctx, id = WithCorrelationID(context.Background(), "abc")
+	assert.Equal(t, "abc", id)
+	h := http.Header{}
+	SetCorrelationHeader(ctx, h)
+	assert.Equal(t, "abc", h.Get(CorrelationIDHeader))
+
+	h = http.Header{}
+	SetCorrelationHeader(context.Background(), h)
+	assert.Empty(t, h)
+	assert.Empty(t, CorrelationID(context.Background()))
+	assert.Equal(t, map[string]interface{}{"correlation_id": "abc"}, CorrelationIDExtractor(ctx))
+	assert.Nil(t, CorrelationIDExtractor(context.Background()))
+}
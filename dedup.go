@@ -0,0 +1,92 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Deduplicator is an output that collapses consecutive identical entries, with
+// the same prefix, severity and message, into a single entry followed by
+// "last message repeated N times", like syslogd does. The summary is written
+// when a different entry arrives, or once the window has passed since the
+// first repetition, so that repetitions are reported at least once per window
+// while they continue.
+type Deduplicator struct {
+	out    Sink
+	window time.Duration
+
+	mx       sync.Mutex
+	last     Entry
+	hasLast  bool
+	repeated int
+	timer    *time.Timer
+}
+
+// DedupOutput creates a Deduplicator that writes to out, reporting
+// repetitions at least once per window. If out isn't an EntryWriter, entries
+// are formatted with the Formatter of the logger that logged them.
+func DedupOutput(out io.Writer, window time.Duration) *Deduplicator {
+	return &Deduplicator{out: Sink{Out: out}, window: window}
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity.
+func (d *Deduplicator) Write(p []byte) (int, error) {
+	return len(p), d.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (d *Deduplicator) WriteEntry(e Entry) error {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	if d.hasLast && e.Prefix == d.last.Prefix && e.Severity == d.last.Severity && e.Message == d.last.Message {
+		d.repeated++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window, d.flush)
+		}
+		return nil
+	}
+	d.writeRepeated()
+	d.last, d.hasLast = e, true
+	return d.out.write(e)
+}
+
+// Close writes the number of repetitions of the last entry, if any
+func (d *Deduplicator) Close() error {
+	d.flush()
+	return nil
+}
+
+func (d *Deduplicator) flush() {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	d.writeRepeated()
+}
+
+// writeRepeated writes the number of repetitions of the last entry, if any.
+// It must be called with mx held.
+func (d *Deduplicator) writeRepeated() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.repeated == 0 {
+		return
+	}
+	err := d.out.write(Entry{
+		Time:      time.Now(),
+		Severity:  d.last.Severity,
+		Prefix:    d.last.Prefix,
+		File:      d.last.File,
+		Line:      d.last.Line,
+		Message:   fmt.Sprintf("last message repeated %d times", d.repeated),
+		formatter: d.last.formatter,
+	})
+	if err != nil {
+		errorOnLogging(err)
+	}
+	d.repeated = 0
+}
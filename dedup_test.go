@@ -0,0 +1,46 @@
+package golog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduplicator(t *testing.T) {
+	ring := NewRingBuffer(100)
+	d := DedupOutput(ring, time.Hour)
+	for i := 0; i < 4; i++ {
+		d.WriteEntry(Entry{Severity: ERROR, Prefix: "dialer", Line: 12, Message: "unable to dial"})
+	}
+	d.WriteEntry(Entry{Severity: DEBUG, Prefix: "dialer", Message: "unable to dial"})
+	d.WriteEntry(Entry{Severity: DEBUG, Prefix: "other", Message: "unable to dial"})
+	d.WriteEntry(Entry{Severity: DEBUG, Prefix: "other", Message: "connected"})
+	d.WriteEntry(Entry{Severity: DEBUG, Prefix: "other", Message: "connected"})
+	assert.NoError(t, d.Close())
+	assert.Equal(t, []string{
+		"unable to dial",
+		"last message repeated 3 times",
+		"unable to dial",
+		"unable to dial",
+		"connected",
+		"last message repeated 1 times",
+	}, ringMessages(ring))
+	summary := ring.Entries()[1]
+	assert.Equal(t, Severity(ERROR), summary.Severity)
+	assert.Equal(t, "dialer", summary.Prefix)
+	assert.Equal(t, 12, summary.Line)
+}
+
+func TestDeduplicatorWindow(t *testing.T) {
+	ring := NewRingBuffer(100)
+	d := DedupOutput(ring, 50*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		d.WriteEntry(Entry{Message: "same"})
+	}
+	time.Sleep(200 * time.Millisecond)
+	d.WriteEntry(Entry{Message: "same"})
+	assert.NoError(t, d.Close())
+	assert.Equal(t, []string{"same", "last message repeated 2 times", "last message repeated 1 times"}, ringMessages(ring),
+		"repetitions should be reported once per window while they continue")
+}
@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	devPrefixWidth = 16
+	devCallerWidth = 20
+)
+
+// DevFormatter is a Formatter intended for reading logs in a terminal during
+// development. It writes entries in aligned columns, with the time, severity,
+// prefix and caller followed by the message and the context values:
+//
+//	15:04:05.000 ERROR myprefix         file.go:12           Hello world  key=value
+//	                   at github.com/getlantern/golog.Example (file.go:12)
+//
+// Long prefixes and callers are truncated to fit their columns. Stack lines are
+// indented below the message. Colors are used according to the ColorMode, or
+// always if Color is set.
+//
+// Setting the environment variable GOLOG_FORMAT=dev selects the DevFormatter
+// with ColorAuto at startup.
+type DevFormatter struct {
+	// Color forces colored output regardless of the ColorMode
+	Color bool
+}
+
+func (f *DevFormatter) Format(e Entry) []byte {
+	return f.format(e, false)
+}
+
+func (f *DevFormatter) format(e Entry, colored bool) []byte {
+	colored = colored || f.Color
+	buf := &bytes.Buffer{}
+	timestamp := e.Time.Format("15:04:05.000")
+	buf.WriteString(timestamp)
+	buf.WriteByte(' ')
+	severity := e.Severity.String()
+	writeSeverity(buf, e.Severity, colored)
+	buf.WriteByte(' ')
+	if len(severity) < 5 {
+		buf.WriteString(strings.Repeat(" ", 5-len(severity)))
+	}
+	fmt.Fprintf(buf, "%-*s ", devPrefixWidth, truncateLeft(e.Prefix, devPrefixWidth))
+	if colored {
+		buf.WriteString(ansiDim)
+	}
+	caller := ""
+	if e.File != "" {
+		caller = e.File + ":" + strconv.Itoa(e.Line)
+	}
+	// Pad even without a caller to keep the messages aligned
+	fmt.Fprintf(buf, "%-*s", devCallerWidth, truncateLeft(caller, devCallerWidth))
+	if colored {
+		buf.WriteString(ansiReset)
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(e.Message)
+	for i, key := range sortedKeys(e.Context) {
+		if i == 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteByte(' ')
+		if colored {
+			buf.WriteString(ansiCyan)
+			buf.WriteString(key)
+			buf.WriteString(ansiReset)
+			buf.WriteString(ansiDim)
+			buf.WriteByte('=')
+			buf.WriteString(ansiReset)
+		} else {
+			buf.WriteString(key)
+			buf.WriteByte('=')
+		}
+		fmt.Fprintf(buf, "%v", e.Context[key])
+	}
+	buf.WriteByte('\n')
+	indent := strings.Repeat(" ", len(timestamp)+len(" ")+6)
+	for _, line := range e.Stack {
+		buf.WriteString(indent)
+		buf.WriteString(strings.TrimLeft(line, " "))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// truncateLeft truncates s to width by removing characters from the left
+func truncateLeft(s string, width int) string {
+	if len(s) <= width {
+		return s
+	}
+	return "…" + s[len(s)-width+1:]
+}
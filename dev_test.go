@@ -0,0 +1,31 @@
+package golog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDevFormatter(t *testing.T) {
+	e := Entry{
+		Time:     time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.UTC),
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"  at a", "Caused by: b"},
+		Context:  map[string]interface{}{"b": 2, "a": "1"},
+	}
+	assert.Equal(t, "15:04:05.123 ERROR myprefix         file.go:12           Hello world  a=1 b=2\n"+
+		"                   at a\n"+
+		"                   Caused by: b\n", string((&DevFormatter{}).Format(e)))
+
+	e.Severity = DEBUG
+	e.Prefix = "a.very.long.prefix.indeed"
+	e.File = "a_very_long_file_name.go"
+	e.Stack = nil
+	e.Context = map[string]interface{}{"a": "1"}
+	assert.Equal(t, "15:04:05.123 \x1b[36mDEBUG\x1b[0m …g.prefix.indeed \x1b[2m…ong_file_name.go:12\x1b[0m Hello world  \x1b[36ma\x1b[0m\x1b[2m=\x1b[0m1\n", string((&DevFormatter{Color: true}).Format(e)))
+}
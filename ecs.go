@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const ecsVersion = "1.6.0"
+
+// ECSFormatter is a Formatter that writes entries as single-line JSON objects
+// following the Elastic Common Schema, for example:
+//
+//	{"@timestamp":"2019-06-10T15:04:05.999Z","log.level":"error","message":"Hello world","ecs.version":"1.6.0","log.logger":"myprefix","log.origin.file.name":"file.go","log.origin.file.line":12,"labels":{"op":"name"}}
+//
+// Context values are written as labels (with dots in keys replaced by
+// underscores) and stack traces are written as error.stack_trace.
+type ECSFormatter struct{}
+
+func (f *ECSFormatter) Format(e Entry) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"@timestamp":`)
+	writeJSONString(buf, e.Time.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	buf.WriteString(`,"log.level":`)
+	writeJSONString(buf, strings.ToLower(e.Severity.String()))
+	buf.WriteString(`,"message":`)
+	writeJSONString(buf, e.Message)
+	buf.WriteString(`,"ecs.version":"` + ecsVersion + `","log.logger":`)
+	writeJSONString(buf, e.Prefix)
+	if e.File != "" {
+		buf.WriteString(`,"log.origin.file.name":`)
+		writeJSONString(buf, e.File)
+		fmt.Fprintf(buf, `,"log.origin.file.line":%d`, e.Line)
+	}
+	if len(e.Stack) > 0 {
+		buf.WriteString(`,"error.message":`)
+		writeJSONString(buf, e.Message)
+		if errorType, ok := e.Context["error_type"]; ok {
+			buf.WriteString(`,"error.type":`)
+			writeJSONString(buf, fmt.Sprint(errorType))
+		}
+		buf.WriteString(`,"error.stack_trace":`)
+		writeJSONString(buf, strings.Join(e.Stack, "\n"))
+	}
+	if len(e.Context) > 0 {
+		buf.WriteString(`,"labels":{`)
+		for i, key := range sortedKeys(e.Context) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, strings.Replace(key, ".", "_", -1))
+			buf.WriteByte(':')
+			writeJSONString(buf, fmt.Sprint(e.Context[key]))
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,41 @@
+package golog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECSFormatter(t *testing.T) {
+	e := Entry{
+		Time:     time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.FixedZone("CEST", 2*60*60)),
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"  at a", "  at b"},
+		Context:  map[string]interface{}{"error_type": "errors.Error", "my.var": 5},
+	}
+	b := (&ECSFormatter{}).Format(e)
+	assert.Equal(t, byte('\n'), b[len(b)-1])
+
+	var doc map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(b, &doc)) {
+		assert.Equal(t, map[string]interface{}{
+			"@timestamp":           "2019-06-10T13:04:05.123Z",
+			"log.level":            "error",
+			"message":              "Hello world",
+			"ecs.version":          "1.6.0",
+			"log.logger":           "myprefix",
+			"log.origin.file.name": "file.go",
+			"log.origin.file.line": float64(12),
+			"error.message":        "Hello world",
+			"error.type":           "errors.Error",
+			"error.stack_trace":    "  at a\n  at b",
+			"labels":               map[string]interface{}{"error_type": "errors.Error", "my_var": "5"},
+		}, doc)
+	}
+}
@@ -0,0 +1,180 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EmailOptions configures an EmailOutput
+type EmailOptions struct {
+	// Addr is the address of the SMTP server, e.g. "smtp.example.com:587"
+	Addr string
+
+	// Auth authenticates with the SMTP server if set, e.g. smtp.PlainAuth
+	Auth smtp.Auth
+
+	// From is the sender of digests
+	From string
+
+	// To lists the recipients of digests
+	To []string
+
+	// Subject is the start of the subject of digests, which is followed by the
+	// number of entries and the hostname. Defaults to "[golog]".
+	Subject string
+
+	// Interval is the minimum time between digests, defaults to 15 minutes
+	Interval time.Duration
+
+	// MinSeverity is the minimum severity of entries included in digests,
+	// defaults to ERROR.
+	MinSeverity Severity
+
+	// MaxEntries is the maximum number of entries in a digest, defaults to
+	// 100. Further entries are counted but omitted.
+	MaxEntries int
+
+	// Formatter formats each entry, defaults to a TextFormatter, which includes
+	// stack traces and context.
+	Formatter Formatter
+}
+
+// EmailOutput is an output that emails digests of ERROR and FATAL entries
+// over SMTP, for small deployments without an alerting stack. The first entry
+// is sent right away, and entries logged within Interval of the last digest
+// are aggregated into the next one, so that at most one email is sent per
+// Interval. Emails are sent on a background goroutine.
+type EmailOutput struct {
+	opts     EmailOptions
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+	sendMx   sync.Mutex
+
+	mx       sync.Mutex
+	pending  []Entry
+	omitted  int
+	lastSent time.Time
+	timer    *time.Timer
+	closed   bool
+}
+
+// NewEmailOutput creates an EmailOutput. Addr, From and To must be set in
+// opts.
+func NewEmailOutput(opts EmailOptions) *EmailOutput {
+	if opts.Subject == "" {
+		opts.Subject = "[golog]"
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Minute
+	}
+	if opts.MinSeverity == 0 {
+		opts.MinSeverity = ERROR
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 100
+	}
+	if opts.Formatter == nil {
+		opts.Formatter = &TextFormatter{}
+	}
+	return &EmailOutput{
+		opts:     opts,
+		sendMail: smtp.SendMail,
+		now:      time.Now,
+	}
+}
+
+// Write implements io.Writer. As EmailOutput is meant to be used as the error
+// output, each write is treated as the message of an ERROR entry.
+func (o *EmailOutput) Write(p []byte) (int, error) {
+	return len(p), o.WriteEntry(Entry{Time: time.Now(), Severity: ERROR, Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (o *EmailOutput) WriteEntry(e Entry) error {
+	if e.Severity < o.opts.MinSeverity {
+		return nil
+	}
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.closed {
+		return errClosed
+	}
+	if len(o.pending) < o.opts.MaxEntries {
+		o.pending = append(o.pending, e)
+	} else {
+		o.omitted++
+	}
+	if o.timer == nil {
+		delay := o.lastSent.Add(o.opts.Interval).Sub(o.now())
+		if delay < 0 {
+			delay = 0
+		}
+		o.timer = time.AfterFunc(delay, o.flush)
+	}
+	return nil
+}
+
+// Close sends a final digest with any pending entries, regardless of the
+// interval.
+func (o *EmailOutput) Close() error {
+	o.mx.Lock()
+	o.closed = true
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	o.mx.Unlock()
+	o.flush()
+	return nil
+}
+
+func (o *EmailOutput) flush() {
+	o.sendMx.Lock()
+	defer o.sendMx.Unlock()
+
+	o.mx.Lock()
+	entries, omitted := o.pending, o.omitted
+	o.pending, o.omitted = nil, 0
+	o.timer = nil
+	if len(entries) > 0 {
+		o.lastSent = o.now()
+	}
+	o.mx.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+	if err := o.sendMail(o.opts.Addr, o.opts.Auth, o.opts.From, o.opts.To, o.digest(entries, omitted)); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+// digest builds the email for the given entries
+func (o *EmailOutput) digest(entries []Entry, omitted int) []byte {
+	count := len(entries) + omitted
+	noun := "log entries"
+	if count == 1 {
+		noun = "log entry"
+	}
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %v\r\n", o.opts.From)
+	fmt.Fprintf(buf, "To: %v\r\n", strings.Join(o.opts.To, ", "))
+	fmt.Fprintf(buf, "Subject: %v %d %v on %v\r\n", o.opts.Subject, count, noun, hostname)
+	fmt.Fprintf(buf, "Date: %v\r\n", o.now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	for _, e := range entries {
+		fmt.Fprintf(buf, "--- %v\r\n", e.Time.Format(time.RFC3339))
+		formatted := strings.TrimSuffix(string(o.opts.Formatter.Format(e)), "\n")
+		buf.WriteString(strings.Replace(formatted, "\n", "\r\n", -1))
+		buf.WriteString("\r\n\r\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(buf, "(%d more omitted)\r\n", omitted)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,66 @@
+package golog
+
+import (
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailOutput(t *testing.T) {
+	var mx sync.Mutex
+	var sent []string
+	o := NewEmailOutput(EmailOptions{
+		Addr:       "smtp.example.com:25",
+		From:       "golog@example.com",
+		To:         []string{"a@example.com", "b@example.com"},
+		Interval:   time.Hour,
+		MaxEntries: 2,
+	})
+	o.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "smtp.example.com:25", addr)
+		assert.Equal(t, "golog@example.com", from)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, to)
+		mx.Lock()
+		sent = append(sent, string(msg))
+		mx.Unlock()
+		return nil
+	}
+	numSent := func() int {
+		mx.Lock()
+		defer mx.Unlock()
+		return len(sent)
+	}
+
+	ts := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	o.WriteEntry(Entry{Time: ts, Severity: DEBUG, Prefix: "myprefix", File: "file.go", Line: 1, Message: "ignored"})
+	o.WriteEntry(Entry{Time: ts, Severity: FATAL, Prefix: "myprefix", File: "file.go", Line: 2, Message: "first", Stack: []string{"at a"}, Context: map[string]interface{}{"cvarA": "a"}})
+	for i := 0; i < 100 && numSent() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !assert.Equal(t, 1, numSent(), "first entry should be sent right away") {
+		return
+	}
+	assert.Contains(t, sent[0], "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, sent[0], "Subject: [golog] 1 log entry on "+hostname+"\r\n")
+	assert.True(t, strings.HasSuffix(sent[0], "\r\n\r\n--- 2020-01-01T00:00:00Z\r\nFATAL myprefix: file.go:2 first [cvarA=a]\r\nFATAL myprefix: file.go:2 at a\r\n\r\n"), sent[0])
+
+	o.WriteEntry(Entry{Time: ts, Severity: ERROR, Message: "second"})
+	o.Write([]byte("third\n"))
+	o.WriteEntry(Entry{Time: ts, Severity: ERROR, Message: "fourth"})
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, numSent(), "entries within interval should be aggregated")
+
+	o.Close()
+	if assert.Equal(t, 2, numSent(), "close should send pending entries") {
+		assert.Contains(t, sent[1], "Subject: [golog] 3 log entries on ")
+		assert.Contains(t, sent[1], ": second\r\n")
+		assert.Contains(t, sent[1], ": third\r\n")
+		assert.NotContains(t, sent[1], "fourth")
+		assert.True(t, strings.HasSuffix(sent[1], "(1 more omitted)\r\n"))
+	}
+	assert.Equal(t, errClosed, o.WriteEntry(Entry{Severity: ERROR}))
+}
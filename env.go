@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// configureFromEnv applies the configuration given by environment variables:
+//
+//	GOLOG_LEVEL  - the level (see SetLevel), e.g. GOLOG_LEVEL=info
+//	GOLOG_FORMAT - the Formatter, one of text (the default), json or dev
+//
+// Tracing is enabled per logger with TRACE or GOLOG_TRACE (see
+// traceEnabledFor). Invalid values are reported on stderr and ignored.
+func configureFromEnv() {
+	if label := os.Getenv("GOLOG_LEVEL"); label != "" {
+		severity, err := ParseSeverity(label)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid GOLOG_LEVEL: %v\n", err)
+		} else {
+			SetLevel(severity)
+		}
+	}
+
+	switch format := os.Getenv("GOLOG_FORMAT"); strings.ToLower(format) {
+	case "":
+	case "text":
+		SetFormatter(nil)
+	case "json":
+		SetFormatter(&JSONFormatter{})
+	case "dev":
+		SetFormatter(&DevFormatter{})
+		SetColorMode(ColorAuto)
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid GOLOG_FORMAT %v, expected text, json or dev\n", format)
+	}
+}
+
+// traceEnabledFor indicates whether tracing is enabled for loggers with the
+// given prefix, which is the case if TRACE or GOLOG_TRACE is either true or a
+// comma-separated list of prefixes that includes prefix.
+func traceEnabledFor(prefix string) bool {
+	for _, name := range []string{"TRACE", "GOLOG_TRACE"} {
+		trace := os.Getenv(name)
+		if on, _ := strconv.ParseBool(trace); on {
+			return true
+		}
+		for _, p := range strings.Split(trace, ",") {
+			if prefix == strings.Trim(p, " ") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package golog
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureFromEnv(t *testing.T) {
+	defer os.Unsetenv("GOLOG_LEVEL")
+	defer os.Unsetenv("GOLOG_FORMAT")
+	defer SetLevel(0)
+	defer SetFormatter(nil)
+
+	os.Setenv("GOLOG_LEVEL", "warn")
+	os.Setenv("GOLOG_FORMAT", "JSON")
+	configureFromEnv()
+	assert.Equal(t, Severity(WARN), GetLevel())
+	assert.IsType(t, &JSONFormatter{}, GetFormatter())
+
+	os.Setenv("GOLOG_LEVEL", "bogus")
+	os.Setenv("GOLOG_FORMAT", "text")
+	SetFormatter(&DevFormatter{})
+	configureFromEnv()
+	assert.Equal(t, Severity(WARN), GetLevel(), "invalid level should be ignored")
+	assert.IsType(t, &TextFormatter{}, GetFormatter())
+}
+
+func TestTraceEnabledFor(t *testing.T) {
+	defer os.Unsetenv("GOLOG_TRACE")
+	trace, hadTrace := os.LookupEnv("TRACE")
+	os.Unsetenv("TRACE")
+	if hadTrace {
+		defer os.Setenv("TRACE", trace)
+	}
+
+	assert.False(t, traceEnabledFor("a"))
+	os.Setenv("GOLOG_TRACE", "a, b")
+	assert.True(t, traceEnabledFor("a"))
+	assert.True(t, traceEnabledFor("b"))
+	assert.False(t, traceEnabledFor("c"))
+	os.Setenv("GOLOG_TRACE", "true")
+	assert.True(t, traceEnabledFor("c"))
+}
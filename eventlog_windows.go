@@ -0,0 +1,171 @@
+package golog
+
+import (
+	"bytes"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	eventLogErrorType       = 0x0001
+	eventLogWarningType     = 0x0002
+	eventLogInformationType = 0x0004
+
+	eventLogRegistryKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+	eventLogMessageFile = `%SystemRoot%\System32\EventCreate.exe`
+
+	hkeyLocalMachine = 0x80000002
+	keySetValue      = 0x0002
+	regExpandSZ      = 2
+	regDWORD         = 4
+)
+
+var (
+	advapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	procRegisterEventSource   = advapi32.NewProc("RegisterEventSourceW")
+	procDeregisterEventSource = advapi32.NewProc("DeregisterEventSource")
+	procReportEvent           = advapi32.NewProc("ReportEventW")
+	procRegCreateKeyEx        = advapi32.NewProc("RegCreateKeyExW")
+	procRegSetValueEx         = advapi32.NewProc("RegSetValueExW")
+)
+
+// EventLogOptions configures an EventLogOutput
+type EventLogOptions struct {
+	// EventID is the event ID of each event, defaults to 1. When using a source
+	// registered with InstallEventLogSource, event IDs must be between 1 and
+	// 1000.
+	EventID uint32
+
+	// Formatter formats each event's message, defaults to a TextFormatter.
+	Formatter Formatter
+}
+
+// EventLogOutput is an output that writes entries to the Windows Application
+// event log. ERROR and FATAL entries are reported as errors, WARN entries as
+// warnings and everything else as information. It is typically used as the
+// error output so that only errors land in the event log, for example:
+//
+//	el, err := golog.NewEventLogOutput("myapp", nil)
+//	...
+//	golog.SetOutputs(el, os.Stderr)
+type EventLogOutput struct {
+	handle syscall.Handle
+	opts   EventLogOptions
+}
+
+// InstallEventLogSource registers source as an event source of the
+// Application event log using EventCreate.exe as its message file, so that
+// events are displayed without a "description cannot be found" warning. This
+// requires administrative privileges and typically happens at install time.
+func InstallEventLogSource(source string) error {
+	var key syscall.Handle
+	r, _, _ := procRegCreateKeyEx.Call(
+		hkeyLocalMachine,
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(eventLogRegistryKey+source))),
+		0, 0, 0,
+		keySetValue,
+		0,
+		uintptr(unsafe.Pointer(&key)),
+		0)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	defer syscall.RegCloseKey(key)
+
+	messageFile := syscall.StringToUTF16(eventLogMessageFile)
+	r, _, _ = procRegSetValueEx.Call(
+		uintptr(key),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("EventMessageFile"))),
+		0,
+		regExpandSZ,
+		uintptr(unsafe.Pointer(&messageFile[0])),
+		uintptr(len(messageFile)*2))
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	types := uint32(eventLogErrorType | eventLogWarningType | eventLogInformationType)
+	r, _, _ = procRegSetValueEx.Call(
+		uintptr(key),
+		uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr("TypesSupported"))),
+		0,
+		regDWORD,
+		uintptr(unsafe.Pointer(&types)),
+		4)
+	if r != 0 {
+		return syscall.Errno(r)
+	}
+	return nil
+}
+
+// NewEventLogOutput opens the event log for the given source. opts may be
+// nil.
+func NewEventLogOutput(source string, opts *EventLogOptions) (*EventLogOutput, error) {
+	h, _, err := procRegisterEventSource.Call(0, uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(source))))
+	if h == 0 {
+		return nil, err
+	}
+	el := &EventLogOutput{handle: syscall.Handle(h)}
+	if opts != nil {
+		el.opts = *opts
+	}
+	if el.opts.EventID == 0 {
+		el.opts.EventID = 1
+	}
+	if el.opts.Formatter == nil {
+		el.opts.Formatter = &TextFormatter{}
+	}
+	return el, nil
+}
+
+// Write implements io.Writer, reporting p as an error event
+func (el *EventLogOutput) Write(p []byte) (int, error) {
+	return len(p), el.report(eventLogErrorType, p)
+}
+
+// WriteEntry implements EntryWriter
+func (el *EventLogOutput) WriteEntry(e Entry) error {
+	return el.report(eventLogType(e.Severity), el.opts.Formatter.Format(e))
+}
+
+// Close closes the event log
+func (el *EventLogOutput) Close() error {
+	r, _, err := procDeregisterEventSource.Call(uintptr(el.handle))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+func (el *EventLogOutput) report(eventType uint16, msg []byte) error {
+	msg = bytes.TrimRight(msg, "\n")
+	s, err := syscall.UTF16PtrFromString(string(msg))
+	if err != nil {
+		return err
+	}
+	r, _, err := procReportEvent.Call(
+		uintptr(el.handle),
+		uintptr(eventType),
+		0,
+		uintptr(el.opts.EventID),
+		0,
+		1,
+		0,
+		uintptr(unsafe.Pointer(&s)),
+		0)
+	if r == 0 {
+		return err
+	}
+	return nil
+}
+
+// eventLogType maps severity to an event log event type
+func eventLogType(severity Severity) uint16 {
+	switch {
+	case severity >= ERROR:
+		return eventLogErrorType
+	case severity >= WARN:
+		return eventLogWarningType
+	default:
+		return eventLogInformationType
+	}
+}
@@ -0,0 +1,14 @@
+package golog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLogType(t *testing.T) {
+	assert.EqualValues(t, eventLogErrorType, eventLogType(FATAL))
+	assert.EqualValues(t, eventLogErrorType, eventLogType(ERROR))
+	assert.EqualValues(t, eventLogInformationType, eventLogType(DEBUG))
+	assert.EqualValues(t, eventLogInformationType, eventLogType(TRACE))
+}
@@ -0,0 +1,33 @@
+package golog
+
+import (
+	"io"
+	"os/exec"
+	"path/filepath"
+)
+
+// LogCommandOutput attaches the stdout and stderr of cmd to a Logger whose
+// prefix is the base name of the command, e.g. "git", so that each line the
+// command writes is logged at stdoutSeverity or stderrSeverity respectively,
+// with the field "stream" set to "stdout" or "stderr". Lines are buffered
+// separately for both streams, so output interleaved by the command isn't
+// mangled. Call the returned function once the command has finished to log
+// incomplete last lines, e.g.:
+//
+//	cmd := exec.Command("git", "fetch")
+//	flush := golog.LogCommandOutput(cmd, golog.DEBUG, golog.WARN)
+//	err := cmd.Run()
+//	flush()
+//
+// It must be called before starting the command.
+func LogCommandOutput(cmd *exec.Cmd, stdoutSeverity Severity, stderrSeverity Severity) (flush func()) {
+	l := LoggerFor(filepath.Base(cmd.Path)).WithoutCaller()
+	stdout := l.WithField("stream", "stdout").WriterAt(stdoutSeverity)
+	stderr := l.WithField("stream", "stderr").WriterAt(stderrSeverity)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return func() {
+		stdout.(io.Closer).Close()
+		stderr.(io.Closer).Close()
+	}
+}
@@ -0,0 +1,31 @@
+package golog
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogCommandOutput(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	cmd := exec.Command(sh, "-c", `printf 'first\nsec'; printf 'problem\n' >&2; printf 'ond\nlast'`)
+	flush := LogCommandOutput(cmd, DEBUG, WARN)
+	assert.NoError(t, cmd.Run())
+	flush()
+
+	assert.Equal(t, `DEBUG sh: first [stream=stdout]
+DEBUG sh: second [stream=stdout]
+DEBUG sh: last [stream=stdout]
+`, debugOut.String())
+	assert.Equal(t, "WARN sh: problem [stream=stderr]\n", errorOut.String())
+}
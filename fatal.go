@@ -0,0 +1,125 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	onFatal      atomic.Value
+	fatalOptions atomic.Value
+	fatalHooksMx sync.Mutex
+	fatalHooks   []*fatalHook
+	exitOnFatal  = os.Exit
+	flushOnFatal = Flush
+)
+
+type fatalHook struct {
+	fn func(err error)
+}
+
+func init() {
+	SetFatalOptions(FatalOptions{})
+}
+
+// FatalOptions configures the default OnFatal behavior, which exits the
+// process
+type FatalOptions struct {
+	// ExitCode is the status with which the process exits, defaults to 1. As
+	// exiting with 0 would report success, 0 is treated as 1.
+	ExitCode int
+
+	// FlushTimeout is the maximum time to wait for flushing the outputs (see
+	// Flush), so that the FATAL entry itself isn't lost in outputs like
+	// AsyncOutput or BufferedOutput, before exiting. Defaults to 5 seconds. A
+	// negative timeout exits without flushing.
+	FlushTimeout time.Duration
+}
+
+// SetFatalOptions configures the default OnFatal behavior
+func SetFatalOptions(opts FatalOptions) {
+	if opts.ExitCode == 0 {
+		opts.ExitCode = 1
+	}
+	if opts.FlushTimeout == 0 {
+		opts.FlushTimeout = 5 * time.Second
+	}
+	fatalOptions.Store(opts)
+}
+
+// OnFatal configures golog to call the given function on any FATAL error,
+// after the hooks registered with RegisterFatalHook, instead of the default
+// behavior of exiting the process.
+func OnFatal(fn func(err error)) {
+	onFatal.Store(fn)
+}
+
+// DefaultOnFatal enables the default behavior for OnFatal, which flushes the
+// outputs and exits the process as configured with SetFatalOptions
+func DefaultOnFatal() {
+	onFatal.Store(exit)
+}
+
+// RegisterFatalHook registers fn to be called on any FATAL error, for example
+// to release resources or notify other processes before exiting. Hooks are
+// called in the order in which they were registered, before the OnFatal
+// function. Returns a function that unregisters the hook.
+func RegisterFatalHook(fn func(err error)) (unregister func()) {
+	hook := &fatalHook{fn}
+	fatalHooksMx.Lock()
+	fatalHooks = append(fatalHooks, hook)
+	fatalHooksMx.Unlock()
+	return func() {
+		fatalHooksMx.Lock()
+		defer fatalHooksMx.Unlock()
+		for i, h := range fatalHooks {
+			if h == hook {
+				fatalHooks = append(fatalHooks[:i:i], fatalHooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// fatal runs the fatal hooks and then the OnFatal function
+func fatal(err error) {
+	fatalHooksMx.Lock()
+	hooks := append([]*fatalHook(nil), fatalHooks...)
+	fatalHooksMx.Unlock()
+	for _, hook := range hooks {
+		hook.fn(err)
+	}
+	fn := onFatal.Load().(func(err error))
+	fn(err)
+}
+
+// exit is the default OnFatal function
+func exit(err error) {
+	opts := fatalOptions.Load().(FatalOptions)
+	if opts.FlushTimeout > 0 {
+		if flushErr := flushWithin(opts.FlushTimeout); flushErr != nil {
+			errorOnLogging(flushErr)
+		}
+	}
+	exitOnFatal(opts.ExitCode)
+}
+
+// flushWithin flushes the outputs, giving up after timeout
+func flushWithin(timeout time.Duration) error {
+	flush := flushOnFatal
+	done := make(chan error, 1)
+	go func() {
+		done <- flush()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("flushing outputs timed out after %v", timeout)
+	}
+}
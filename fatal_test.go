@@ -0,0 +1,75 @@
+package golog
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFatalHooks(t *testing.T) {
+	reset := SetOutputs(ioutil.Discard, ioutil.Discard)
+	defer reset()
+	var calls []string
+	OnFatal(func(err error) {
+		calls = append(calls, "onFatal: "+err.Error())
+	})
+	defer DefaultOnFatal()
+
+	unregisterFirst := RegisterFatalHook(func(err error) {
+		calls = append(calls, "first: "+err.Error())
+	})
+	unregisterSecond := RegisterFatalHook(func(err error) {
+		calls = append(calls, "second")
+	})
+	defer unregisterSecond()
+
+	l := LoggerFor("fatal")
+	l.Fatal("boom")
+	unregisterFirst()
+	l.Fatal("again")
+	assert.Equal(t, []string{"first: boom", "second", "onFatal: boom", "second", "onFatal: again"}, calls)
+}
+
+func TestFatalExit(t *testing.T) {
+	reset := SetOutputs(ioutil.Discard, ioutil.Discard)
+	defer reset()
+	var exitCode int
+	flushed := make(chan bool, 1)
+	exitOnFatal = func(code int) { exitCode = code }
+	flushOnFatal = func() error {
+		flushed <- true
+		return nil
+	}
+	defer func() {
+		exitOnFatal = os.Exit
+		flushOnFatal = Flush
+		SetFatalOptions(FatalOptions{})
+	}()
+
+	l := LoggerFor("fatal")
+	l.Fatal("boom")
+	assert.Equal(t, 1, exitCode)
+	assert.Len(t, flushed, 1, "should flush by default")
+	<-flushed
+
+	SetFatalOptions(FatalOptions{ExitCode: 3, FlushTimeout: -1})
+	l.Fatal("boom")
+	assert.Equal(t, 3, exitCode)
+	assert.Empty(t, flushed, "negative timeout shouldn't flush")
+
+	block := make(chan bool)
+	defer close(block)
+	flushOnFatal = func() error {
+		<-block
+		return nil
+	}
+	exitCode = 0
+	SetFatalOptions(FatalOptions{ExitCode: 4, FlushTimeout: 10 * time.Millisecond})
+	start := time.Now()
+	l.Fatal("boom")
+	assert.Equal(t, 4, exitCode, "should exit when flushing times out")
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -0,0 +1,303 @@
+package golog
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/getlantern/context"
+	"github.com/getlantern/hidden"
+)
+
+var (
+	// globalFields holds the merge of userFields and processFields
+	globalFields   atomic.Value
+	globalFieldsMx sync.Mutex
+	userFields     map[string]interface{}
+	processFields  map[string]interface{}
+)
+
+func init() {
+	SetGlobalFields(nil)
+}
+
+// SetGlobalFields sets fields, like the version, environment or region, that
+// are included in the context of every entry logged by any logger, and in the
+// context passed to ErrorReporters. Fields of loggers and ops context values
+// take precedence over global fields with the same key, and global fields over
+// the process information set with SetProcessInfo. Pass nil to clear the
+// global fields.
+func SetGlobalFields(fields map[string]interface{}) {
+	copied := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		copied[key] = value
+	}
+	globalFieldsMx.Lock()
+	defer globalFieldsMx.Unlock()
+	userFields = copied
+	storeGlobalFields()
+}
+
+// storeGlobalFields stores the merge of the process fields and the fields set
+// with SetGlobalFields as the global fields. It must be called with
+// globalFieldsMx held.
+func storeGlobalFields() {
+	merged := make(map[string]interface{}, len(processFields)+len(userFields))
+	for key, value := range processFields {
+		merged[key] = value
+	}
+	for key, value := range userFields {
+		merged[key] = value
+	}
+	globalFields.Store(merged)
+}
+
+func (l *logger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(map[string]interface{}{key: value})
+}
+
+func (l *logger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+	return l.child(l.prefix, merged)
+}
+
+func (l *logger) With(keyvals ...interface{}) Logger {
+	fields := make(map[string]interface{}, (len(keyvals)+1)/2)
+	for len(keyvals) > 0 {
+		if f, ok := keyvals[0].(Field); ok {
+			fields[f.Key] = f
+			keyvals = keyvals[1:]
+			continue
+		}
+		key, ok := keyvals[0].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[0])
+		}
+		if len(keyvals) == 1 {
+			fields[key] = "(MISSING)"
+			break
+		}
+		fields[key] = keyvals[1]
+		keyvals = keyvals[2:]
+	}
+	return l.WithFields(fields)
+}
+
+func (l *logger) Named(name string) Logger {
+	prefix := name
+	if l.prefix != "" {
+		prefix = l.prefix + "." + name
+	}
+	return l.child(prefix, l.fields)
+}
+
+func (l *logger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+	fields := make(map[string]interface{}, len(l.fields)+3)
+	for key, value := range l.fields {
+		fields[key] = value
+	}
+	for key, value := range errorFields(err) {
+		fields[key] = value
+	}
+	c := l.child(l.prefix, fields)
+	c.err = err
+	return c
+}
+
+// child creates a logger with the given prefix and fields that inherits this
+// logger's Formatter and filters.
+func (l *logger) child(prefix string, fields map[string]interface{}) *logger {
+	c := &logger{
+		prefix:     prefix,
+		parent:     l,
+		fields:     fields,
+		err:        l.err,
+		callerSkip: l.callerSkip,
+		callerFile: l.callerFile,
+		callerLine: l.callerLine,
+		noCaller:   l.noCaller,
+		withStack:  l.withStack,
+		stackOpts:  l.stackOpts,
+		traceOn:    l.traceOn,
+		printStack: l.printStack,
+		noReport:   l.noReport,
+		noRegister: l.noRegister,
+		pc:         make([]uintptr, 10),
+	}
+	if prefix != l.prefix {
+		c.traceOn = traceEnabledFor(prefix)
+		if !c.noRegister {
+			registerPrefix(prefix)
+		}
+	}
+	c.minSeverity()
+	return c
+}
+
+// addFields adds this logger's fields to the given context values,
+// overwriting existing values.
+func (l *logger) addFields(values map[string]interface{}) map[string]interface{} {
+	if len(l.fields) == 0 {
+		return values
+	}
+	if values == nil {
+		values = make(map[string]interface{}, len(l.fields))
+	}
+	for key, value := range l.fields {
+		values[key] = value
+	}
+	return values
+}
+
+// addGlobalFields adds the global fields to the given context values without
+// overwriting existing values.
+func addGlobalFields(values map[string]interface{}) map[string]interface{} {
+	fields := globalFields.Load().(map[string]interface{})
+	if len(fields) == 0 {
+		return values
+	}
+	if values == nil {
+		values = make(map[string]interface{}, len(fields))
+	}
+	for key, value := range fields {
+		if _, exists := values[key]; !exists {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// maxErrorChain bounds the number of errors walked in a chain of causes, to
+// guard against cyclic or pathologically deep chains
+const maxErrorChain = 100
+
+type unwrapper interface {
+	Unwrap() error
+}
+
+// multiUnwrapper is implemented by errors wrapping several errors, like those
+// returned by errors.Join or fmt.Errorf with multiple %w verbs
+type multiUnwrapper interface {
+	Unwrap() []error
+}
+
+// causer is implemented by errors from github.com/pkg/errors and similar
+// packages that predate Unwrap
+type causer interface {
+	Cause() error
+}
+
+// errorFields returns the normalized context values describing err. Errors
+// that fill in their own context, like those from github.com/getlantern/errors,
+// provide their own values, also when they're wrapped by other errors, with
+// the values of outer errors taking precedence. Missing values are filled in
+// with the cleaned text of err as "error" and the type of the root cause as
+// "error_type".
+func errorFields(err error) map[string]interface{} {
+	fields := make(context.Map)
+	chain := errorChain(err)
+	for i := len(chain) - 1; i > 0; i-- {
+		if contextual, ok := chain[i].(context.Contextual); ok {
+			contextual.Fill(fields)
+		}
+	}
+	// the text of wrapped errors doesn't describe err as a whole
+	delete(fields, "error")
+	if contextual, ok := err.(context.Contextual); ok {
+		contextual.Fill(fields)
+	}
+	if _, found := fields["error"]; !found {
+		fields["error"] = hidden.Clean(err.Error())
+	}
+	if _, found := fields["error_type"]; !found {
+		fields["error_type"] = strings.TrimPrefix(reflect.TypeOf(chain[len(chain)-1]).String(), "*")
+	}
+	return fields
+}
+
+// unwrapCause returns the single cause wrapped by err, if any
+func unwrapCause(err error) error {
+	switch e := err.(type) {
+	case unwrapper:
+		return e.Unwrap()
+	case causer:
+		return e.Cause()
+	default:
+		return nil
+	}
+}
+
+// errorChain returns err followed by the causes it wraps, depth first. Causes
+// are found with Unwrap() error, Unwrap() []error and Cause() error.
+func errorChain(err error) []error {
+	chain := []error{err}
+	for i := 0; i < len(chain) && len(chain) < maxErrorChain; i++ {
+		var causes []error
+		if joined, ok := chain[i].(multiUnwrapper); ok {
+			causes = joined.Unwrap()
+		} else if cause := unwrapCause(chain[i]); cause != nil {
+			causes = []error{cause}
+		}
+		// insert the causes right after the error that wraps them
+		rest := append([]error(nil), chain[i+1:]...)
+		chain = chain[:i+1]
+		for _, cause := range causes {
+			if cause != nil {
+				chain = append(chain, cause)
+			}
+		}
+		chain = append(chain, rest...)
+	}
+	if len(chain) > maxErrorChain {
+		chain = chain[:maxErrorChain]
+	}
+	return chain
+}
+
+// causeLines renders err and its causes as "Caused by:" lines. MultiLine
+// errors render themselves, including their causes and stack traces, while
+// other errors are rendered without the text of the cause they wrap. Errors
+// wrapping several errors, like those from errors.Join, aren't rendered
+// themselves, only the errors they wrap are.
+func causeLines(err error) []string {
+	return appendCauseLines(nil, err, 0)
+}
+
+func appendCauseLines(lines []string, err error, depth int) []string {
+	if depth >= maxErrorChain {
+		return lines
+	}
+	if ml, ok := err.(MultiLine); ok {
+		first, rest := multiLines(ml)
+		return append(append(lines, causePrefix+first), rest...)
+	}
+	if joined, ok := err.(multiUnwrapper); ok {
+		for _, e := range joined.Unwrap() {
+			if e != nil {
+				lines = appendCauseLines(lines, e, depth+1)
+			}
+		}
+		return lines
+	}
+	text := err.Error()
+	cause := unwrapCause(err)
+	if cause != nil {
+		text = strings.TrimSuffix(text, ": "+cause.Error())
+	}
+	lines = append(lines, causePrefix+hidden.Clean(text))
+	if cause != nil {
+		lines = appendCauseLines(lines, cause, depth+1)
+	}
+	return lines
+}
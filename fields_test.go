@@ -0,0 +1,191 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	withUser := l.WithField("user", 5)
+	withMore := withUser.WithFields(map[string]interface{}{"request": "abc", "cvarA": "field"})
+
+	op := ops.Begin("name").Set("cvarA", "a")
+	withMore.Debug("Hello")
+	withUser.Debug("world")
+	l.Debug("plain")
+	op.End()
+
+	assert.Regexp(t, `^DEBUG myprefix: fields_test.go:\d+ Hello \[cvarA=field op=name request=abc root_op=name user=5\]
+DEBUG myprefix: fields_test.go:\d+ world \[cvarA=a op=name root_op=name user=5\]
+DEBUG myprefix: fields_test.go:\d+ plain \[cvarA=a op=name root_op=name\]
+$`, out.String())
+}
+
+func TestWithFieldsInheritance(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	child := l.WithField("a", 1)
+	l.SetFormatter(&customFormatter{})
+	l.AddFilter(func(e Entry) (Entry, bool) {
+		e.Message += " parent"
+		return e, true
+	})
+	child.AddFilter(func(e Entry) (Entry, bool) {
+		e.Message += " child"
+		return e, true
+	})
+	child.Debug("Hello")
+	assert.Regexp(t, `^DEBUG\|myprefix\|fields_test.go\|\d+\|Hello parent child\|0\n$`, out.String(),
+		"child should use the parent's formatter and apply its filters after the parent's")
+}
+
+func TestNamedAndWith(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	sub := l.With("user", 5, 6, "six", "dangling").Named("sub")
+	subsub := sub.Named("subsub").With("request", "abc")
+	sub.Debug("Hello")
+	subsub.Debug("world")
+	LoggerFor("").Named("top").Debug("plain")
+
+	assert.Regexp(t, `^DEBUG myprefix.sub: fields_test.go:\d+ Hello \[6=six dangling=\(MISSING\) user=5\]
+DEBUG myprefix.sub.subsub: fields_test.go:\d+ world \[6=six dangling=\(MISSING\) request=abc user=5\]
+DEBUG top: fields_test.go:\d+ plain
+$`, out.String())
+
+	SetPrefixLevel("myprefix.sub.subsub", ERROR)
+	defer ResetPrefixLevels()
+	before := out.String()
+	subsub.Debug("hidden")
+	assert.Equal(t, before, out.String(), "named loggers should respect prefix levels")
+}
+
+func TestGlobalFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	globals := map[string]interface{}{"env": "prod", "region": "eu", "user": "global"}
+	SetGlobalFields(globals)
+	defer SetGlobalFields(nil)
+	globals["env"] = "changed"
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	l.WithField("user", 5).Debug("Hello")
+	SetGlobalFields(nil)
+	l.Debug("world")
+
+	assert.Regexp(t, `^DEBUG myprefix: fields_test.go:\d+ Hello \[env=prod region=eu user=5\]
+DEBUG myprefix: fields_test.go:\d+ world
+$`, out.String())
+}
+
+func TestWithError(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	assert.Equal(t, l, l.WithError(nil))
+
+	root := &os.PathError{Op: "open", Path: "/tmp/x", Err: syscall.ENOENT}
+	wrapped := fmt.Errorf("loading config: %w", root)
+	l.WithError(wrapped).WithField("user", 5).Debug("startup failed")
+	assert.Regexp(t, `^DEBUG myprefix: fields_test.go:\d+ startup failed \[error=loading config: open /tmp/x: no such file or directory error_type=syscall.Errno user=5\]
+DEBUG myprefix: fields_test.go:\d+ Caused by: loading config
+DEBUG myprefix: fields_test.go:\d+ Caused by: open /tmp/x
+DEBUG myprefix: fields_test.go:\d+ Caused by: no such file or directory
+$`, out.String())
+
+	out.Reset()
+	l.WithError(errors.New("connect failed")).Named("sub").Debug("giving up")
+	assert.Regexp(t, `^DEBUG myprefix.sub: fields_test.go:\d+ giving up \[error=connect failed error_location=\S+ \(fields_test.go:\d+\) error_text=connect failed error_type=errors.Error\]
+DEBUG myprefix.sub: fields_test.go:\d+ Caused by: connect failed
+DEBUG myprefix.sub: fields_test.go:\d+   at github.com/getlantern/golog.TestWithError \(fields_test.go:\d+\)
+`, out.String())
+}
+
+type joinedError []error
+
+func (e joinedError) Error() string {
+	texts := make([]string, 0, len(e))
+	for _, err := range e {
+		texts = append(texts, err.Error())
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (e joinedError) Unwrap() []error {
+	return e
+}
+
+type causeError struct {
+	msg   string
+	cause error
+}
+
+func (e *causeError) Error() string {
+	return e.msg + ": " + e.cause.Error()
+}
+
+func (e *causeError) Cause() error {
+	return e.cause
+}
+
+func TestWithErrorChains(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	joined := joinedError{
+		fmt.Errorf("closing: %w", &causeError{"flushing", syscall.EPIPE}),
+		&os.PathError{Op: "remove", Path: "/tmp/x", Err: syscall.ENOENT},
+	}
+	l.WithError(joined).Debug("cleanup failed")
+	assert.Regexp(t, `^DEBUG myprefix: fields_test.go:\d+ cleanup failed \[error=closing: flushing: broken pipe
+remove /tmp/x: no such file or directory error_type=syscall.Errno\]
+DEBUG myprefix: fields_test.go:\d+ Caused by: closing
+DEBUG myprefix: fields_test.go:\d+ Caused by: flushing
+DEBUG myprefix: fields_test.go:\d+ Caused by: broken pipe
+DEBUG myprefix: fields_test.go:\d+ Caused by: remove /tmp/x
+DEBUG myprefix: fields_test.go:\d+ Caused by: no such file or directory
+$`, out.String())
+
+	out.Reset()
+	l.WithError(fmt.Errorf("retrying: %w", errors.New("connect failed").With("host", "example.com"))).Debug("giving up")
+	assert.Regexp(t, `^DEBUG myprefix: fields_test.go:\d+ giving up \[error=retrying: connect failed error_location=\S+ \(fields_test.go:\d+\) error_text=connect failed error_type=errors.Error host=example.com\]
+DEBUG myprefix: fields_test.go:\d+ Caused by: retrying
+DEBUG myprefix: fields_test.go:\d+ Caused by: connect failed
+DEBUG myprefix: fields_test.go:\d+   at github.com/getlantern/golog.TestWithErrorChains \(fields_test.go:\d+\)
+`, out.String())
+
+	cyclic := &causeError{msg: "cyclic"}
+	cyclic.cause = cyclic
+	assert.Len(t, errorChain(cyclic), maxErrorChain)
+}
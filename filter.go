@@ -0,0 +1,76 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	filtersMx sync.Mutex
+	filters   atomic.Value
+)
+
+// Filter is called with each entry before it is formatted and written. It
+// returns the entry to write, which may have been rewritten or enriched, and
+// whether or not to write it at all. Filters may be called concurrently from
+// multiple goroutines and must not modify the Context or Stack of the given
+// entry in place, as these may be shared, but may replace them with copies.
+//
+// Filters can also make routing decisions, for example by adding a context
+// value that an EntryWriter output acts on.
+type Filter func(e Entry) (Entry, bool)
+
+// AddFilter adds a Filter that is applied to entries of all loggers, before
+// the loggers' own filters.
+func AddFilter(f Filter) {
+	filtersMx.Lock()
+	defer filtersMx.Unlock()
+	current, _ := filters.Load().([]Filter)
+	filters.Store(append(append([]Filter(nil), current...), f))
+}
+
+// ResetFilters removes all filters added with AddFilter
+func ResetFilters() {
+	filtersMx.Lock()
+	defer filtersMx.Unlock()
+	filters.Store([]Filter(nil))
+}
+
+func (l *logger) AddFilter(f Filter) {
+	l.filtersMx.Lock()
+	defer l.filtersMx.Unlock()
+	current, _ := l.filters.Load().([]Filter)
+	l.filters.Store(append(append([]Filter(nil), current...), f))
+}
+
+// filter applies the global filters and then the filters of this logger's
+// ancestors and itself to e, returning the resulting entry and whether or not
+// it should be written.
+func (l *logger) filter(e Entry) (Entry, bool) {
+	global, _ := filters.Load().([]Filter)
+	e, ok := applyFilters(global, e)
+	if !ok {
+		return e, false
+	}
+	var lineage []*logger
+	for ancestor := l; ancestor != nil; ancestor = ancestor.parent {
+		lineage = append(lineage, ancestor)
+	}
+	for i := len(lineage) - 1; i >= 0; i-- {
+		own, _ := lineage[i].filters.Load().([]Filter)
+		if e, ok = applyFilters(own, e); !ok {
+			return e, false
+		}
+	}
+	return e, true
+}
+
+func applyFilters(fs []Filter, e Entry) (Entry, bool) {
+	for _, f := range fs {
+		var ok bool
+		if e, ok = f(e); !ok {
+			return e, false
+		}
+	}
+	return e, true
+}
@@ -0,0 +1,38 @@
+package golog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilters(t *testing.T) {
+	out := &bytes.Buffer{}
+	SetOutputs(ioutil.Discard, out)
+	defer ResetFilters()
+
+	var order []string
+	AddFilter(func(e Entry) (Entry, bool) {
+		order = append(order, "global")
+		return e, !strings.Contains(e.Message, "secret")
+	})
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&customFormatter{})
+	l.AddFilter(func(e Entry) (Entry, bool) {
+		order = append(order, "logger")
+		e.Message = strings.ToUpper(e.Message)
+		return e, true
+	})
+	other := LoggerFor("other")
+	other.SetFormatter(&customFormatter{})
+
+	l.Debug("hello")
+	assert.Equal(t, []string{"global", "logger"}, order)
+	l.Debug("secret")
+	assert.Equal(t, []string{"global", "logger", "global"}, order, "filters after a drop shouldn't be called")
+	other.Debug("world")
+	assert.Equal(t, "DEBUG|myprefix|filter_test.go|32|HELLO|0\nDEBUG|other|filter_test.go|36|world|0\n", out.String())
+}
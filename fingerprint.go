@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+)
+
+// fingerprintFrames is the number of frames from the top of an error's stack
+// trace that contribute to its fingerprint
+const fingerprintFrames = 3
+
+// fingerprintNoise matches the parts of error messages that vary between
+// occurrences of the same failure, like numbers, hex values and UUIDs
+var fingerprintNoise = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|0[xX][0-9a-fA-F]+|\b[0-9a-fA-F]*[0-9][0-9a-fA-F]*[a-fA-F][0-9a-fA-F]*\b|[0-9]+`)
+
+// Fingerprint returns a stable hash of err that's the same for all occurrences
+// of the same failure, for grouping them. It's made from the type of the root
+// cause, the functions of the top frames of the error's stack trace, if it has
+// one (like errors from github.com/getlantern/errors), and its message with
+// numbers, hex values and UUIDs normalized. Errors from
+// github.com/getlantern/errors contribute their parameter-less description
+// instead of their message. Reported errors carry their fingerprint as the
+// "error_fingerprint" context value.
+func Fingerprint(err error) string {
+	fields := errorFields(err)
+	h := fnv.New64a()
+	writePart := func(part string) {
+		h.Write([]byte(strconv.Itoa(len(part))))
+		h.Write([]byte{':'})
+		h.Write([]byte(part))
+	}
+	writePart(fmt.Sprint(fields["error_type"]))
+	writePart(fingerprintNoise.ReplaceAllString(fmt.Sprint(fields["error"]), "#"))
+	for _, function := range topFunctions(err, fingerprintFrames) {
+		writePart(function)
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// topFunctions returns the functions of up to n frames from the top of the
+// stack trace of the first MultiLine error in err's chain
+func topFunctions(err error, n int) []string {
+	for _, e := range errorChain(err) {
+		ml, ok := e.(MultiLine)
+		if !ok {
+			continue
+		}
+		_, lines := multiLines(ml)
+		var functions []string
+		for _, line := range lines {
+			if len(functions) == n || !isFrame(line) {
+				break
+			}
+			function, _, _ := parseFrame(line)
+			functions = append(functions, function)
+		}
+		return functions
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package golog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func failReading(id int) error {
+	return errors.New("unable to read block %v", id)
+}
+
+func failWriting(id int) error {
+	return errors.New("unable to read block %v", id)
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := Fingerprint(fmt.Errorf("request 1234 for 0xdeadbeef failed"))
+	assert.Len(t, fp, 16)
+	assert.Equal(t, fp, Fingerprint(fmt.Errorf("request 99 for 0x1f failed")), "numbers and hex values should be normalized")
+	assert.Equal(t, Fingerprint(fmt.Errorf("user 3f2504e0-4f89-11d3-9a0c-0305e82c3301 not found")),
+		Fingerprint(fmt.Errorf("user 6ba7b810-9dad-11d1-80b4-00c04fd430c8 not found")), "UUIDs should be normalized")
+	assert.NotEqual(t, fp, Fingerprint(fmt.Errorf("response 1234 for 0xdeadbeef failed")))
+	assert.NotEqual(t, Fingerprint(fmt.Errorf("failed")), Fingerprint(&causeError{"failed", fmt.Errorf("cause")}), "types should differ")
+
+	assert.Equal(t, Fingerprint(failReading(1)), Fingerprint(failReading(2)), "same location and description should match")
+	assert.NotEqual(t, Fingerprint(failReading(1)), Fingerprint(failWriting(1)), "different locations should differ")
+	assert.Equal(t, Fingerprint(fmt.Errorf("retrying: %w", failReading(1))), Fingerprint(fmt.Errorf("retrying: %w", failReading(2))))
+}
+
+func TestReportFingerprint(t *testing.T) {
+	var fingerprints []interface{}
+	reportersMutex.Lock()
+	oldReporters := reporters
+	reporters = []ErrorReporter{func(err error, severity Severity, ctx map[string]interface{}) {
+		fingerprints = append(fingerprints, ctx["error_fingerprint"])
+	}}
+	reportersMutex.Unlock()
+	defer func() {
+		reportersMutex.Lock()
+		reporters = oldReporters
+		reportersMutex.Unlock()
+	}()
+
+	reset := SetOutputs(ioutil.Discard, ioutil.Discard)
+	defer reset()
+
+	l := LoggerFor("fingerprint")
+	l.Errorf("connection %d reset", 1)
+	l.Errorf("connection %d reset", 2)
+	if assert.Len(t, fingerprints, 2) {
+		assert.Equal(t, fingerprints[0], fingerprints[1])
+		assert.Len(t, fingerprints[0], 16)
+	}
+}
@@ -0,0 +1,237 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FluentdOptions configures a FluentdOutput
+type FluentdOptions struct {
+	// Tag is the tag of entries without a prefix and the prefix of the tag of
+	// all other entries, which are tagged Tag.prefix. Defaults to "golog".
+	Tag string
+
+	// RequireAck requests an acknowledgement for each batch, which is resent
+	// if it isn't acknowledged within AckTimeout.
+	RequireAck bool
+
+	// AckTimeout is how long to wait for an acknowledgement, defaults to 10
+	// seconds.
+	AckTimeout time.Duration
+
+	// MaxRetries is the number of times a batch is resent if sending fails,
+	// defaults to 3.
+	MaxRetries int
+
+	// QueueSize is the maximum number of entries waiting to be sent, defaults
+	// to 10000. Entries logged while the queue is full are dropped.
+	// Dropped returns how many were.
+	QueueSize int
+
+	// BatchSize is the maximum number of entries per batch, defaults to 512.
+	BatchSize int
+
+	// FlushInterval is the maximum time entries wait before being sent,
+	// defaults to 5 seconds.
+	FlushInterval time.Duration
+}
+
+// FluentdOutput is an output that sends entries to Fluentd or Fluent Bit using
+// the forward protocol. Each entry is sent as a record with severity, message,
+// caller and stack fields plus its context values, tagged with its prefix.
+// Entries are sent in batches on a background goroutine, reconnecting as
+// necessary.
+type FluentdOutput struct {
+	*batcher
+	addr string
+	opts FluentdOptions
+	conn net.Conn
+	dec  *MsgpackDecoder
+}
+
+// NewFluentdOutput creates a FluentdOutput that sends to the forward input
+// listening on the given TCP address. The connection is established lazily.
+// opts may be nil.
+func NewFluentdOutput(addr string, opts *FluentdOptions) *FluentdOutput {
+	o := &FluentdOutput{addr: addr}
+	if opts != nil {
+		o.opts = *opts
+	}
+	if o.opts.Tag == "" {
+		o.opts.Tag = "golog"
+	}
+	if o.opts.AckTimeout <= 0 {
+		o.opts.AckTimeout = 10 * time.Second
+	}
+	if o.opts.MaxRetries == 0 {
+		o.opts.MaxRetries = 3
+	}
+	if o.opts.QueueSize <= 0 {
+		o.opts.QueueSize = 10000
+	}
+	if o.opts.BatchSize <= 0 {
+		o.opts.BatchSize = 512
+	}
+	if o.opts.FlushInterval <= 0 {
+		o.opts.FlushInterval = 5 * time.Second
+	}
+	o.batcher = newBatcher(o.opts.QueueSize, o.opts.BatchSize, o.opts.FlushInterval, o.send)
+	return o
+}
+
+// Write implements io.Writer, sending each write as the message of a record
+// without severity.
+func (o *FluentdOutput) Write(p []byte) (int, error) {
+	return len(p), o.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (o *FluentdOutput) WriteEntry(e Entry) error {
+	return o.add(e)
+}
+
+// Close sends all queued entries and closes the connection
+func (o *FluentdOutput) Close() error {
+	o.batcher.Close()
+	if o.conn != nil {
+		return o.conn.Close()
+	}
+	return nil
+}
+
+func (o *FluentdOutput) send(batch []Entry) {
+	var tags []string
+	byTag := make(map[string][]Entry)
+	for _, e := range batch {
+		tag := o.opts.Tag
+		if e.Prefix != "" {
+			tag += "." + e.Prefix
+		}
+		if _, found := byTag[tag]; !found {
+			tags = append(tags, tag)
+		}
+		byTag[tag] = append(byTag[tag], e)
+	}
+
+	for _, tag := range tags {
+		chunk := ""
+		if o.opts.RequireAck {
+			chunk = newChunkID()
+		}
+		msg := encodeForward(tag, byTag[tag], chunk)
+		var err error
+		for attempt := 0; attempt <= o.opts.MaxRetries; attempt++ {
+			if err = o.sendMessage(msg, chunk); err == nil {
+				break
+			}
+			o.disconnect()
+		}
+		if err != nil {
+			errorOnLogging(err)
+		}
+	}
+}
+
+// sendMessage sends msg and waits for its ack if chunk isn't empty
+func (o *FluentdOutput) sendMessage(msg []byte, chunk string) error {
+	if o.conn == nil {
+		conn, err := net.DialTimeout("tcp", o.addr, o.opts.AckTimeout)
+		if err != nil {
+			return err
+		}
+		o.conn = conn
+		o.dec = NewMsgpackDecoder(conn)
+	}
+	if _, err := o.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+	o.conn.SetReadDeadline(time.Now().Add(o.opts.AckTimeout))
+	defer o.conn.SetReadDeadline(time.Time{})
+	resp, err := o.dec.decodeValue()
+	if err != nil {
+		return err
+	}
+	ack, _ := resp.(map[string]interface{})
+	if ack["ack"] != chunk {
+		return fmt.Errorf("unexpected ack from fluentd: %v", resp)
+	}
+	return nil
+}
+
+func (o *FluentdOutput) disconnect() {
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+		o.dec = nil
+	}
+}
+
+// encodeForward encodes entries in Forward mode:
+//
+//	[tag, [[time, record], ...], {"chunk": chunk}]
+func encodeForward(tag string, entries []Entry, chunk string) []byte {
+	buf := &bytes.Buffer{}
+	if chunk != "" {
+		writeMsgpackArrayHeader(buf, 3)
+	} else {
+		writeMsgpackArrayHeader(buf, 2)
+	}
+	writeMsgpackString(buf, tag)
+	writeMsgpackArrayHeader(buf, len(entries))
+	for _, e := range entries {
+		writeMsgpackArrayHeader(buf, 2)
+		writeFluentdEventTime(buf, e.Time)
+		writeMsgpackMap(buf, fluentdRecord(e))
+	}
+	if chunk != "" {
+		writeMsgpackMapHeader(buf, 1)
+		writeMsgpackString(buf, "chunk")
+		writeMsgpackString(buf, chunk)
+	}
+	return buf.Bytes()
+}
+
+// fluentdRecord converts e into a record, with the entry's own fields taking
+// precedence over context values of the same name.
+func fluentdRecord(e Entry) map[string]interface{} {
+	record := make(map[string]interface{}, len(e.Context)+4)
+	for key, value := range e.Context {
+		record[key] = value
+	}
+	record["message"] = e.Message
+	if e.Severity != 0 {
+		record["severity"] = e.Severity.String()
+	}
+	if e.File != "" {
+		record["caller"] = e.File + ":" + strconv.Itoa(e.Line)
+	}
+	if len(e.Stack) > 0 {
+		record["stack"] = strings.Join(e.Stack, "\n")
+	}
+	return record
+}
+
+// writeFluentdEventTime writes t as a Fluentd EventTime, which is msgpack
+// extension type 0 holding seconds and nanoseconds.
+func writeFluentdEventTime(buf *bytes.Buffer, t time.Time) {
+	buf.WriteByte(0xd7)
+	buf.WriteByte(0x00)
+	binary.Write(buf, binary.BigEndian, uint32(t.Unix()))
+	binary.Write(buf, binary.BigEndian, uint32(t.Nanosecond()))
+}
+
+func newChunkID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
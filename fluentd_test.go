@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFluentdOutput(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+	messages := make(chan []interface{}, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		d := NewMsgpackDecoder(conn)
+		for {
+			v, err := d.decodeValue()
+			if err != nil {
+				return
+			}
+			msg := v.([]interface{})
+			messages <- msg
+			buf := writeAck(msg[2].(map[string]interface{})["chunk"].(string))
+			conn.Write(buf)
+		}
+	}()
+
+	o := NewFluentdOutput(l.Addr().String(), &FluentdOptions{Tag: "app", RequireAck: true})
+	ts := time.Unix(1560171845, 123456789)
+	o.WriteEntry(Entry{
+		Time:     ts,
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"at a", "at b"},
+		Context:  map[string]interface{}{"cvarA": "a", "message": "overridden"},
+	})
+	o.Write([]byte("plain\n"))
+	assert.NoError(t, o.Close())
+
+	first := <-messages
+	assert.Equal(t, "app.myprefix", first[0])
+	assert.Equal(t, []interface{}{
+		[]interface{}{ts, map[string]interface{}{
+			"severity": "ERROR",
+			"message":  "Hello world",
+			"caller":   "file.go:12",
+			"stack":    "at a\nat b",
+			"cvarA":    "a",
+		}},
+	}, first[1])
+
+	second := <-messages
+	assert.Equal(t, "app", second[0])
+	records := second[1].([]interface{})
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, map[string]interface{}{"message": "plain"}, records[0].([]interface{})[1])
+	}
+}
+
+func writeAck(chunk string) []byte {
+	buf := &bytes.Buffer{}
+	writeMsgpackMap(buf, map[string]interface{}{"ack": chunk})
+	return buf.Bytes()
+}
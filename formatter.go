@@ -0,0 +1,167 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/getlantern/hidden"
+)
+
+var (
+	formatter atomic.Value
+)
+
+// Entry is a single log entry as passed to a Formatter.
+type Entry struct {
+	// Time is the time at which the entry was logged
+	Time time.Time
+
+	// Severity is the severity of the entry
+	Severity Severity
+
+	// Prefix is the prefix of the Logger that logged the entry
+	Prefix string
+
+	// File is the base name of the source file from which the entry was logged
+	File string
+
+	// Line is the line number from which the entry was logged
+	Line int
+
+	// Message is the logged message. For MultiLine arguments like errors, this
+	// is only the first line.
+	Message string
+
+	// Stack contains the remaining lines of MultiLine arguments, for example the
+	// stack trace and causes of an error.
+	Stack []string
+
+	// Context contains the context values (from ops and errors) associated with
+	// the entry.
+	Context map[string]interface{}
+
+	header *header
+
+	// formatter is the Formatter of the logger that logged the entry, if it
+	// has its own, for EntryWriters that end up formatting it
+	formatter Formatter
+}
+
+// Formatter formats log entries for writing to an output. The default
+// Formatter is a TextFormatter. Formatters may be called concurrently from
+// multiple goroutines.
+type Formatter interface {
+	// Format returns the formatted entry, including any trailing newline. All
+	// lines of the entry, including its Stack, are returned together so that
+	// they're written to the output with a single Write and don't interleave
+	// with entries logged by other goroutines.
+	Format(e Entry) []byte
+}
+
+// EntryWriter is an optional interface for outputs that consume entries in
+// structured form. If an output passed to SetOutputs implements EntryWriter,
+// loggers call WriteEntry instead of formatting the entry and calling Write.
+type EntryWriter interface {
+	// WriteEntry writes the given entry
+	WriteEntry(e Entry) error
+}
+
+// formattedEntryWriter is implemented by outputs that need an entry's
+// severity but write it formatted with the Formatter of the logger writing it
+type formattedEntryWriter interface {
+	writeFormatted(e Entry, formatted []byte) error
+}
+
+type formatterHolder struct {
+	Formatter
+}
+
+// SetFormatter sets the Formatter used by all loggers that don't have their
+// own Formatter. Pass nil to go back to the default TextFormatter.
+func SetFormatter(f Formatter) {
+	if f == nil {
+		f = &TextFormatter{}
+	}
+	formatter.Store(&formatterHolder{f})
+}
+
+// GetFormatter returns the package-level Formatter
+func GetFormatter() Formatter {
+	return formatter.Load().(*formatterHolder).Formatter
+}
+
+// newEntry builds an Entry for the given arg, expanding MultiLine arguments
+// into Message and Stack and appending the causes of an error attached with
+// WithError to the Stack.
+func (l *logger) newEntry(severity Severity, file string, line int, arg interface{}) Entry {
+	e := Entry{
+		Time:     time.Now(),
+		Severity: severity,
+		Prefix:   l.prefix,
+		File:     file,
+		Line:     line,
+		Context:  addGlobalFields(l.addFields(contextValues(arg, false))),
+		header:   l.header(severity),
+	}
+	if arg != nil {
+		if ml, isMultiline := arg.(MultiLine); isMultiline {
+			e.Message, e.Stack = multiLines(ml)
+		} else {
+			e.Message = cleanHidden(fmt.Sprintf("%v", arg))
+		}
+	}
+	if l.err != nil {
+		e.Stack = append(e.Stack, causeLines(l.err)...)
+	}
+	e.Stack = l.getStackOptions().apply(e.Stack)
+	return e
+}
+
+// multiLines returns the first line printed by ml and the remaining lines
+func multiLines(ml MultiLine) (string, []string) {
+	var first string
+	var rest []string
+	var buf bytes.Buffer
+	mlp := ml.MultiLinePrinter()
+	for isFirst := true; ; isFirst = false {
+		more := mlp(&buf)
+		if isFirst {
+			first = cleanHidden(buf.String())
+		} else {
+			rest = append(rest, cleanHidden(buf.String()))
+		}
+		buf.Reset()
+		if !more {
+			return first, rest
+		}
+	}
+}
+
+// cleanHidden removes hidden data from s like hidden.Clean, but without
+// allocating if there isn't any, which is recognizable by its NUL delimiters.
+func cleanHidden(s string) string {
+	if strings.IndexByte(s, 0) < 0 {
+		return s
+	}
+	return hidden.Clean(s)
+}
+
+// copyBytes returns a copy of the contents of buf, for returning formatted
+// entries from buffers that go back to the bufferPool.
+func copyBytes(buf *bytes.Buffer) []byte {
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+// sortedKeys returns the keys of the given context values in sorted order
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type customFormatter struct{}
+
+func (f *customFormatter) Format(e Entry) []byte {
+	return []byte(fmt.Sprintf("%v|%v|%v|%d|%v|%d\n", e.Severity, e.Prefix, e.File, e.Line, e.Message, len(e.Stack)))
+}
+
+func TestCustomFormatter(t *testing.T) {
+	SetFormatter(&customFormatter{})
+	defer SetFormatter(nil)
+
+	out := newBuffer()
+	SetOutputs(out, ioutil.Discard)
+	LoggerFor("myprefix").Error(errors.New("Hello world"))
+	assert.Equal(t, "ERROR|myprefix|formatter_test.go|999|Hello world|999\n", out.String())
+}
+
+func TestDefaultFormatter(t *testing.T) {
+	SetFormatter(nil)
+	assert.IsType(t, &TextFormatter{}, GetFormatter())
+}
+
+type entryRecorder struct {
+	entries []Entry
+}
+
+func (r *entryRecorder) Write(p []byte) (int, error) {
+	panic("Write should not be called on an EntryWriter")
+}
+
+func (r *entryRecorder) WriteEntry(e Entry) error {
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func TestEntryWriter(t *testing.T) {
+	r := &entryRecorder{}
+	SetOutputs(ioutil.Discard, r)
+	defer SetOutputs(ioutil.Discard, ioutil.Discard)
+	LoggerFor("myprefix").Debugf("Hello %v", "world")
+	if assert.Len(t, r.entries, 1) {
+		assert.Equal(t, DEBUG, int(r.entries[0].Severity))
+		assert.Equal(t, "myprefix", r.entries[0].Prefix)
+		assert.Equal(t, "Hello world", r.entries[0].Message)
+	}
+}
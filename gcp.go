@@ -0,0 +1,98 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CloudLoggingFormatter is a Formatter that writes entries as single-line JSON
+// objects in the structured logging format understood by Google Cloud
+// Logging. On Cloud Run, App Engine, Cloud Functions and GKE, entries written
+// to stdout or stderr using this Formatter are ingested by the logging agent,
+// for example:
+//
+//	{"time":"2019-06-10T15:04:05.999999999Z","severity":"ERROR","message":"Hello world","logging.googleapis.com/sourceLocation":{"file":"file.go","line":"12"},"logging.googleapis.com/labels":{"prefix":"myprefix"},"op":"name"}
+//
+// Severities are mapped to Cloud Logging severities, the caller is attached as
+// the sourceLocation and context values are written as top-level fields of the
+// jsonPayload. The trace_id and span_id context values link entries to Cloud
+// Trace. Stack traces are appended to the message so that Error Reporting
+// picks them up.
+type CloudLoggingFormatter struct {
+	// ProjectID is the project used to qualify trace IDs, defaults to the value
+	// of the GOOGLE_CLOUD_PROJECT environment variable.
+	ProjectID string
+}
+
+func (f *CloudLoggingFormatter) Format(e Entry) []byte {
+	projectID := f.ProjectID
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"time":`)
+	writeJSONString(buf, e.Time.UTC().Format("2006-01-02T15:04:05.999999999Z07:00"))
+	buf.WriteString(`,"severity":"`)
+	buf.WriteString(cloudLoggingSeverity(e.Severity))
+	buf.WriteString(`","message":`)
+	if len(e.Stack) > 0 {
+		writeJSONString(buf, e.Message+"\n"+strings.Join(e.Stack, "\n"))
+	} else {
+		writeJSONString(buf, e.Message)
+	}
+	if e.File != "" {
+		buf.WriteString(`,"logging.googleapis.com/sourceLocation":{"file":`)
+		writeJSONString(buf, e.File)
+		fmt.Fprintf(buf, `,"line":"%d"}`, e.Line)
+	}
+	if e.Prefix != "" {
+		buf.WriteString(`,"logging.googleapis.com/labels":{"prefix":`)
+		writeJSONString(buf, e.Prefix)
+		buf.WriteByte('}')
+	}
+	for _, key := range sortedKeys(e.Context) {
+		value := e.Context[key]
+		switch key {
+		case "trace_id":
+			trace := fmt.Sprint(value)
+			if projectID != "" {
+				trace = "projects/" + projectID + "/traces/" + trace
+			}
+			buf.WriteString(`,"logging.googleapis.com/trace":`)
+			writeJSONString(buf, trace)
+		case "span_id":
+			buf.WriteString(`,"logging.googleapis.com/spanId":`)
+			writeJSONString(buf, fmt.Sprint(value))
+		case "time", "severity", "message":
+			// don't clobber the entry's own fields
+		default:
+			buf.WriteByte(',')
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			writeJSONValue(buf, value)
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// cloudLoggingSeverity maps the given Severity to a Cloud Logging LogSeverity
+func cloudLoggingSeverity(s Severity) string {
+	switch {
+	case s >= FATAL:
+		return "CRITICAL"
+	case s >= ERROR:
+		return "ERROR"
+	case s >= WARN:
+		return "WARNING"
+	case s >= INFO:
+		return "INFO"
+	case s > 0:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
@@ -0,0 +1,48 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudLoggingFormatter(t *testing.T) {
+	f := &CloudLoggingFormatter{ProjectID: "myproject"}
+	b := f.Format(Entry{
+		Time:     time.Date(2019, 6, 10, 15, 4, 5, 123000000, time.UTC),
+		Severity: FATAL,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"  at a", "  at b"},
+		Context: map[string]interface{}{
+			"cvarA":    "a",
+			"count":    5,
+			"trace_id": "0af7651916cd43dd8448eb211c80319c",
+			"span_id":  "b7ad6b7169203331",
+			"severity": "ignored",
+		},
+	})
+	assert.True(t, bytes.HasSuffix(b, []byte("}\n")))
+	var result map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(b, &result)) {
+		assert.Equal(t, map[string]interface{}{
+			"time":                                  "2019-06-10T15:04:05.123Z",
+			"severity":                              "CRITICAL",
+			"message":                               "Hello world\n  at a\n  at b",
+			"logging.googleapis.com/sourceLocation": map[string]interface{}{"file": "file.go", "line": "12"},
+			"logging.googleapis.com/labels":         map[string]interface{}{"prefix": "myprefix"},
+			"logging.googleapis.com/trace":          "projects/myproject/traces/0af7651916cd43dd8448eb211c80319c",
+			"logging.googleapis.com/spanId":         "b7ad6b7169203331",
+			"cvarA":                                 "a",
+			"count":                                 float64(5),
+		}, result)
+	}
+
+	b = (&CloudLoggingFormatter{}).Format(Entry{Severity: DEBUG, Message: "plain"})
+	assert.Contains(t, string(b), `"severity":"DEBUG","message":"plain"}`)
+}
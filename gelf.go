@@ -0,0 +1,102 @@
+package golog
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	hostname, _ = os.Hostname()
+)
+
+// GELFFormatter is a Formatter that writes entries as GELF 1.1 messages for
+// consumption by Graylog. Context values are written as additional fields (e.g.
+// _cvarA) and stack traces are written as the full_message.
+//
+// When writing to a UDP GELF input, each entry is written with a single call
+// to Write, so a net.Conn obtained from net.Dial("udp", addr) can be used
+// directly as an output. Messages larger than a single datagram are not
+// chunked, so use TCP (with NullTerminated) if that's a concern.
+type GELFFormatter struct {
+	// Host is the reported host, defaults to the hostname of this machine.
+	Host string
+
+	// NullTerminated terminates each message with a NUL byte as required by
+	// GELF over TCP. By default, messages are terminated with a newline.
+	NullTerminated bool
+}
+
+func (f *GELFFormatter) Format(e Entry) []byte {
+	host := f.Host
+	if host == "" {
+		host = hostname
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"version":"1.1","host":`)
+	writeJSONString(buf, host)
+	buf.WriteString(`,"short_message":`)
+	writeJSONString(buf, e.Message)
+	if len(e.Stack) > 0 {
+		buf.WriteString(`,"full_message":`)
+		writeJSONString(buf, e.Message+"\n"+strings.Join(e.Stack, "\n"))
+	}
+	buf.WriteString(`,"timestamp":`)
+	buf.WriteString(strconv.FormatFloat(float64(e.Time.UnixNano())/1e9, 'f', 3, 64))
+	buf.WriteString(`,"level":`)
+	buf.WriteString(strconv.Itoa(syslogSeverity(e.Severity)))
+	buf.WriteString(`,"_prefix":`)
+	writeJSONString(buf, e.Prefix)
+	if e.File != "" {
+		buf.WriteString(`,"_file":`)
+		writeJSONString(buf, e.File)
+		buf.WriteString(`,"_line":`)
+		buf.WriteString(strconv.Itoa(e.Line))
+	}
+	for _, key := range sortedKeys(e.Context) {
+		buf.WriteString(`,"_`)
+		buf.WriteString(gelfFieldName(key))
+		buf.WriteString(`":`)
+		writeJSONValue(buf, e.Context[key])
+	}
+	buf.WriteByte('}')
+	if f.NullTerminated {
+		buf.WriteByte(0)
+	} else {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// gelfFieldName replaces characters that aren't allowed in GELF additional
+// field names and avoids the reserved _id field.
+func gelfFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+	if name == "id" {
+		return "id_"
+	}
+	return name
+}
+
+// syslogSeverity maps the given Severity to the corresponding syslog severity
+// as defined in RFC 5424.
+func syslogSeverity(s Severity) int {
+	switch {
+	case s >= FATAL:
+		return 2 // critical
+	case s >= ERROR:
+		return 3 // error
+	case s >= WARN:
+		return 4 // warning
+	case s >= INFO:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
@@ -0,0 +1,47 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGELFFormatter(t *testing.T) {
+	SetFormatter(&GELFFormatter{Host: "myhost"})
+	defer SetFormatter(nil)
+
+	out := &bytes.Buffer{}
+	SetOutputs(out, ioutil.Discard)
+	l := LoggerFor("myprefix")
+	defer ops.Begin("name").Set("cvarA", "a").Set("id", 5).End()
+	l.Error(errors.New("world"))
+
+	var msg map[string]interface{}
+	if assert.NoError(t, json.Unmarshal([]byte(out.String()), &msg)) {
+		assert.Equal(t, "1.1", msg["version"])
+		assert.Equal(t, "myhost", msg["host"])
+		assert.Equal(t, "world", msg["short_message"])
+		assert.Contains(t, msg["full_message"], "world\n  at github.com/getlantern/golog.TestGELFFormatter")
+		assert.Equal(t, float64(3), msg["level"])
+		assert.Equal(t, "myprefix", msg["_prefix"])
+		assert.Equal(t, "gelf_test.go", msg["_file"])
+		assert.Equal(t, "a", msg["_cvarA"])
+		assert.Equal(t, float64(5), msg["_id_"])
+		assert.Nil(t, msg["_id"])
+	}
+}
+
+func TestGELFFormatterNullTerminated(t *testing.T) {
+	b := (&GELFFormatter{NullTerminated: true}).Format(Entry{Severity: DEBUG, Message: "Hello"})
+	assert.Equal(t, byte(0), b[len(b)-1])
+	var msg map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(b[:len(b)-1], &msg)) {
+		assert.Equal(t, hostname, msg["host"])
+		assert.Equal(t, float64(7), msg["level"])
+	}
+}
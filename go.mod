@@ -3,7 +3,7 @@ module github.com/getlantern/golog
 go 1.12
 
 require (
-	github.com/getlantern/context v0.0.0-20190109183933-c447772a6520 // indirect
+	github.com/getlantern/context v0.0.0-20190109183933-c447772a6520
 	github.com/getlantern/errors v1.0.1
 	github.com/getlantern/hex v0.0.0-20190417191902-c6586a6fe0b7 // indirect
 	github.com/getlantern/hidden v0.0.0-20190325191715-f02dbb02be55
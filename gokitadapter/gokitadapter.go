@@ -0,0 +1,106 @@
+// Package gokitadapter provides an implementation of the Logger interface of
+// github.com/go-kit/log that logs through golog, so that go-kit based services
+// can keep their call sites, like level.Info(logger).Log("msg", "started"),
+// while gaining golog's outputs and ErrorReporters. For example:
+//
+//	var logger log.Logger = gokitadapter.NewLogger(golog.LoggerFor("myservice"))
+//	logger = log.With(logger, "caller", log.DefaultCaller)
+//
+// The go-kit Logger interface only has the method Log(keyvals ...interface{})
+// error, so this package doesn't depend on go-kit.
+//
+// The key "level", as added by the go-kit level package, determines the
+// severity, which is INFO for entries without a level. The value of the key
+// "msg" is the message, an error under the key "err" or "error" is attached
+// with Logger.WithError so that its causes are logged too, and a "caller"
+// like "main.go:12", as added by log.DefaultCaller, is used as the file and
+// line of the entry. All other keys become context values.
+package gokitadapter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getlantern/golog"
+)
+
+// Logger is a go-kit log.Logger that logs to a golog.Logger
+type Logger struct {
+	l golog.Logger
+}
+
+// NewLogger creates a Logger that logs to l
+func NewLogger(l golog.Logger) *Logger {
+	return &Logger{l: l}
+}
+
+// Log implements the go-kit log.Logger interface. It always returns nil.
+func (k *Logger) Log(keyvals ...interface{}) error {
+	l := k.l
+	severity := golog.Severity(golog.INFO)
+	var msg string
+	hasCaller := false
+	fields := make(map[string]interface{}, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		switch key {
+		case "level":
+			if s, err := golog.ParseSeverity(fmt.Sprint(value)); err == nil {
+				severity = s
+				continue
+			}
+		case "msg":
+			msg = fmt.Sprint(value)
+			continue
+		case "caller":
+			if file, line, ok := parseCaller(fmt.Sprint(value)); ok {
+				l = l.WithCaller(file, line)
+				hasCaller = true
+				continue
+			}
+		case "err", "error":
+			if err, ok := value.(error); ok {
+				l = l.WithError(err)
+				continue
+			}
+		}
+		fields[key] = value
+	}
+	if !hasCaller {
+		l = l.WithoutCaller()
+	}
+	if len(fields) > 0 {
+		l = l.WithFields(fields)
+	}
+	switch {
+	case severity >= golog.ERROR:
+		l.Error(msg)
+	case severity >= golog.WARN:
+		l.Warn(msg)
+	case severity >= golog.INFO:
+		l.Info(msg)
+	case severity >= golog.DEBUG:
+		l.Debug(msg)
+	default:
+		l.Trace(msg)
+	}
+	return nil
+}
+
+// parseCaller parses a caller like "main.go:12"
+func parseCaller(caller string) (string, int, bool) {
+	i := strings.LastIndexByte(caller, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(caller[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return caller[:i], line, true
+}
@@ -0,0 +1,38 @@
+package gokitadapter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+)
+
+// levelValue mimics the values of the go-kit level package
+type levelValue string
+
+func (v levelValue) String() string {
+	return string(v)
+}
+
+func TestLogger(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := golog.SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	l := NewLogger(golog.LoggerFor("gokitadapter"))
+	assert.NoError(t, l.Log("level", levelValue("debug"), "caller", "main.go:12", "msg", "starting", "port", 8080))
+	l.Log("event", "started", "dangling")
+	l.Log("level", levelValue("warn"), "msg", "slow")
+	l.Log("level", levelValue("error"), "msg", "failed", "err", errors.New("boom"))
+
+	assert.Equal(t, `DEBUG gokitadapter: main.go:12 starting [port=8080]
+INFO gokitadapter:  [dangling=(MISSING) event=started]
+`, debugOut.String())
+	assert.Equal(t, `WARN gokitadapter: slow
+ERROR gokitadapter: failed [error=boom error_type=errors.errorString]
+ERROR gokitadapter: Caused by: boom
+`, errorOut.String())
+}
@@ -1,13 +1,17 @@
-// Package golog implements logging functions that log errors to stderr and
-// debug messages to stdout. Trace logging is also supported.
+// Package golog implements logging functions that log errors and warnings to
+// stderr and info and debug messages to stdout. Trace logging is also
+// supported.
 // Trace logs go to stdout as well, but they are only written if the program
-// is run with environment variable "TRACE=true".
+// is run with environment variable "TRACE=true" (or "GOLOG_TRACE=true").
+// The environment variables GOLOG_LEVEL and GOLOG_FORMAT set the level and
+// format at startup, e.g. GOLOG_LEVEL=info GOLOG_FORMAT=json.
 // A stack dump will be printed after the message if "PRINT_STACK=true".
 package golog
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -15,19 +19,29 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/getlantern/errors"
-	"github.com/getlantern/hidden"
-	"github.com/getlantern/ops"
 	"github.com/oxtoacart/bpool"
 )
 
 const (
+	// TRACE is a trace Severity
+	TRACE = 100
+
+	// DEBUG is a debug Severity
+	DEBUG = 200
+
+	// INFO is an informational Severity
+	INFO = 300
+
+	// WARN is a warning Severity
+	WARN = 400
+
 	// ERROR is an error Severity
 	ERROR = 500
 
@@ -43,14 +57,36 @@ var (
 
 	bufferPool = bpool.NewBufferPool(200)
 
-	onFatal atomic.Value
+	severityLabels atomic.Value
+
+	// severities are all known severities, from least to most severe
+	severities = []Severity{TRACE, DEBUG, INFO, WARN, ERROR, FATAL}
 )
 
 // Severity is a level of error (higher values are more severe)
 type Severity int
 
+// String returns the label for this Severity, as configured with
+// SetSeverityLabels.
 func (s Severity) String() string {
+	if labels, _ := severityLabels.Load().(map[Severity]string); labels != nil {
+		if label, found := labels[s]; found {
+			return label
+		}
+	}
+	return s.defaultLabel()
+}
+
+func (s Severity) defaultLabel() string {
 	switch s {
+	case TRACE:
+		return "TRACE"
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARN"
 	case ERROR:
 		return "ERROR"
 	case FATAL:
@@ -60,10 +96,38 @@ func (s Severity) String() string {
 	}
 }
 
+// SetSeverityLabels overrides the labels with which severities are written by
+// all formatters, e.g. {ERROR: "WARNING"}. Severities not included in labels
+// keep their default label. Pass nil to restore the default labels.
+func SetSeverityLabels(labels map[Severity]string) {
+	copied := make(map[Severity]string, len(labels))
+	for severity, label := range labels {
+		copied[severity] = label
+	}
+	severityLabels.Store(copied)
+	atomic.AddUint64(&labelsVersion, 1)
+}
+
+// ParseSeverity parses the default label of a Severity (e.g. "debug") without
+// regard to case, or its numeric value (e.g. "200").
+func ParseSeverity(label string) (Severity, error) {
+	for _, s := range severities {
+		if strings.EqualFold(label, s.defaultLabel()) {
+			return s, nil
+		}
+	}
+	if i, err := strconv.Atoi(label); err == nil {
+		return Severity(i), nil
+	}
+	return 0, fmt.Errorf("unknown severity %v", label)
+}
+
 func init() {
 	DefaultOnFatal()
 	ResetOutputs()
 	ResetPrepender()
+	SetFormatter(nil)
+	configureFromEnv()
 }
 
 // SetPrepender sets a function to write something, e.g., the timestamp, before
@@ -110,19 +174,6 @@ func RegisterReporter(reporter ErrorReporter) {
 	reportersMutex.Unlock()
 }
 
-// OnFatal configures golog to call the given function on any FATAL error. By
-// default, golog calls os.Exit(1) on any FATAL error.
-func OnFatal(fn func(err error)) {
-	onFatal.Store(fn)
-}
-
-// DefaultOnFatal enables the default behavior for OnFatal
-func DefaultOnFatal() {
-	onFatal.Store(func(err error) {
-		os.Exit(1)
-	})
-}
-
 type outputs struct {
 	ErrorOut io.Writer
 	DebugOut io.Writer
@@ -138,11 +189,14 @@ type MultiLine interface {
 	MultiLinePrinter() func(buf *bytes.Buffer) bool
 }
 
-// ErrorReporter is a function to which the logger will report errors.
-// It the given error and corresponding message along with associated ops
-// context. This should return quickly as it executes on the critical code
-// path. The recommended approach is to buffer as much as possible and discard
-// new reports if the buffer becomes saturated.
+// ErrorReporter is a function to which the logger will report errors and
+// warnings. It the given error and corresponding message along with associated
+// context values, which includes the logger's prefix under the key "prefix"
+// and the error's Fingerprint under the key "error_fingerprint". Warnings are
+// reported with severity WARN, so reporters only interested in errors should
+// ignore severities below ERROR. This should return quickly as it executes on
+// the critical code path. The recommended approach is to buffer as much as
+// possible and discard new reports if the buffer becomes saturated.
 type ErrorReporter func(err error, severity Severity, ctx map[string]interface{})
 
 type Logger interface {
@@ -151,15 +205,29 @@ type Logger interface {
 	// Debugf logs to stdout
 	Debugf(message string, args ...interface{})
 
+	// Info logs to stdout
+	Info(arg interface{})
+	// Infof logs to stdout
+	Infof(message string, args ...interface{})
+
+	// Warn logs to stderr and reports the warning to the registered
+	// ErrorReporters with severity WARN
+	Warn(arg interface{})
+	// Warnf logs to stderr and reports the warning to the registered
+	// ErrorReporters with severity WARN
+	Warnf(message string, args ...interface{})
+
 	// Error logs to stderr
 	Error(arg interface{}) error
 	// Errorf logs to stderr. It returns the first argument that's an error, or
 	// a new error built using fmt.Errorf if none of the arguments are errors.
 	Errorf(message string, args ...interface{}) error
 
-	// Fatal logs to stderr and then exits with status 1
+	// Fatal logs to stderr, runs the fatal hooks and then calls the OnFatal
+	// function, which exits the process by default (see SetFatalOptions)
 	Fatal(arg interface{})
-	// Fatalf logs to stderr and then exits with status 1
+	// Fatalf logs to stderr, runs the fatal hooks and then calls the OnFatal
+	// function, which exits the process by default (see SetFatalOptions)
 	Fatalf(message string, args ...interface{})
 
 	// Trace logs to stderr only if TRACE=true
@@ -174,135 +242,331 @@ type Logger interface {
 	// prefix.
 	TraceOut() io.Writer
 
+	// IsEnabled indicates whether or not entries with the given severity are
+	// written by this Logger, for guarding the construction of expensive
+	// arguments. Calls at disabled severities return before any formatting,
+	// caller lookup or context retrieval, except for ERRORs and FATALs and,
+	// while ErrorReporters are registered, WARNs, which are still reported.
+	IsEnabled(severity Severity) bool
+
 	// IsTraceEnabled() indicates whether or not tracing is enabled for this
 	// logger.
 	IsTraceEnabled() bool
 
-	// AsStdLogger returns an standard logger
+	// AsStdLogger returns an standard logger that logs at ERROR
 	AsStdLogger() *log.Logger
+
+	// AsStdLoggerAt returns a standard logger that logs at the given severity
+	AsStdLoggerAt(severity Severity) *log.Logger
+
+	// AsSniffingStdLogger returns a standard logger that chooses the severity
+	// of each line from its leading token, like "WARN" or "ERROR:" (see
+	// SniffSeverity), and logs lines without such a token at defaultSeverity.
+	AsSniffingStdLogger(defaultSeverity Severity) *log.Logger
+
+	// WriterAt returns an io.Writer that logs each line written to it at the
+	// given severity, for plugging golog into APIs that only accept an
+	// io.Writer, like the Stdout and Stderr of an exec.Cmd. Incomplete lines
+	// are buffered until they're completed. The Writer also implements
+	// io.Closer, which logs an incomplete last line.
+	WriterAt(severity Severity) io.Writer
+
+	// SniffingWriter is like WriterAt, but chooses the severity of each line
+	// from its leading token like AsSniffingStdLogger, for capturing the output
+	// of programs and libraries that write their own severities, like klog.
+	// Lines without such a token are logged at defaultSeverity.
+	SniffingWriter(defaultSeverity Severity) io.Writer
+
+	// SetFormatter sets the Formatter used by this logger, overriding the
+	// package-level Formatter. Pass nil to go back to using the package-level
+	// Formatter.
+	SetFormatter(f Formatter)
+
+	// WithField returns a Logger that includes the given field in the context
+	// of all entries, in addition to the fields of this Logger. Fields take
+	// precedence over ContextProvider values with the same key.
+	WithField(key string, value interface{}) Logger
+
+	// WithFields returns a Logger that includes the given fields in the
+	// context of all entries, in addition to the fields of this Logger.
+	WithFields(fields map[string]interface{}) Logger
+
+	// With returns a Logger that includes the given alternating keys and
+	// values in the context of all entries, e.g. With("user", 5, "id", "abc").
+	// Keys that aren't strings are formatted with fmt.Sprint, and a trailing
+	// key without a value gets the value "(MISSING)". Typed Fields like
+	// String("user", name) can be mixed in and don't take a separate value.
+	With(keyvals ...interface{}) Logger
+
+	// TimeOperation returns a function that logs how long the given operation
+	// took at DEBUG, with the "operation" and "elapsed" fields, when called. It's
+	// meant to be deferred, e.g. defer l.TimeOperation("db.query")().
+	TimeOperation(operation string) func()
+
+	// TimeOperationOver is like TimeOperation, but only logs if the operation
+	// took longer than threshold, and does so at WARN.
+	TimeOperationOver(operation string, threshold time.Duration) func()
+
+	// WithError returns a Logger that includes the given error in the context
+	// of all entries as the normalized "error" and "error_type" fields, and
+	// appends its chain of causes to their stacks as "Caused by:" lines. This
+	// works the same for errors from github.com/getlantern/errors, which also
+	// contribute their own context even when wrapped, and for plain errors
+	// wrapped with fmt.Errorf, joined with errors.Join or implementing
+	// Cause() error. A nil error returns this Logger.
+	WithError(err error) Logger
+
+	// WithCallerSkip returns a Logger that skips the given number of
+	// additional stack frames when capturing the file and line of log calls,
+	// for use by helpers that wrap a Logger so that entries report the
+	// location of the helper's caller. Skips add up.
+	WithCallerSkip(skip int) Logger
+
+	// WithStack returns a Logger that appends the stack of the calling
+	// goroutine to every entry, at any severity, to show how a code path was
+	// reached. Use it sparingly, e.g. l.WithStack().Debug("unexpected state").
+	WithStack() Logger
+
+	// WithStackOptions returns a Logger that renders stack traces and causes
+	// with the given StackOptions instead of the package-level ones (see
+	// SetStackOptions), e.g. to disable stack traces for a noisy component.
+	WithStackOptions(opts StackOptions) Logger
+
+	// WithoutCaller returns a Logger that doesn't capture the file and line
+	// of log calls, saving the cost of runtime.Callers.
+	WithoutCaller() Logger
+
+	// WithCaller returns a Logger that reports the given file and line as the
+	// location of its log calls instead of capturing them, for adapters that
+	// receive the caller from another logging API, like the PC of a
+	// slog.Record.
+	WithCaller(file string, line int) Logger
+
+	// Named returns a Logger whose prefix is this Logger's prefix followed by
+	// a dot and the given name, e.g. "myprefix.sub", that inherits this
+	// Logger's fields, Formatter and filters.
+	Named(name string) Logger
+
+	// TraceCtx, DebugCtx, InfoCtx, WarnCtx, ErrorCtx and FatalCtx are like
+	// Trace, Debug, Info, Warn, Error and Fatal, but also include the fields
+	// extracted from ctx by the registered ContextExtractors.
+	TraceCtx(ctx context.Context, arg interface{})
+	DebugCtx(ctx context.Context, arg interface{})
+	InfoCtx(ctx context.Context, arg interface{})
+	WarnCtx(ctx context.Context, arg interface{})
+	ErrorCtx(ctx context.Context, arg interface{}) error
+	FatalCtx(ctx context.Context, arg interface{})
+
+	// AddFilter adds a Filter that is applied to entries of this logger, after
+	// the global filters added with AddFilter.
+	AddFilter(f Filter)
 }
 
 func LoggerFor(prefix string) Logger {
+	l := newLogger(prefix)
+	registerPrefix(prefix)
+	return l
+}
+
+// newLogger creates a logger with the given prefix without registering the
+// prefix with the AdminHandler
+func newLogger(prefix string) *logger {
 	l := &logger{
-		prefix: prefix + ": ",
+		prefix: prefix,
 		pc:     make([]uintptr, 10),
 	}
 
-	trace := os.Getenv("TRACE")
-	l.traceOn, _ = strconv.ParseBool(trace)
-	if !l.traceOn {
-		prefixes := strings.Split(trace, ",")
-		for _, p := range prefixes {
-			if prefix == strings.Trim(p, " ") {
-				l.traceOn = true
-				break
-			}
-		}
-	}
-	if l.traceOn {
-		l.traceOut = l.newTraceWriter()
-	} else {
-		l.traceOut = ioutil.Discard
-	}
+	l.traceOn = traceEnabledFor(prefix)
 
 	printStack := os.Getenv("PRINT_STACK")
 	l.printStack, _ = strconv.ParseBool(printStack)
-
+	// resolve the level up front so that logging doesn't have to
+	l.minSeverity()
 	return l
 }
 
 type logger struct {
 	prefix     string
+	parent     *logger
+	fields     map[string]interface{}
+	err        error
+	callerSkip int
+	callerFile string
+	callerLine int
+	noCaller   bool
+	withStack  bool
+	stackOpts  *StackOptions
 	traceOn    bool
+	traceMx    sync.Mutex
 	traceOut   io.Writer
 	printStack bool
 	outs       atomic.Value
+	formatter  atomic.Value
+	level      atomic.Value
+	filtersMx  sync.Mutex
+	filters    atomic.Value
 	pc         []uintptr
-	funcForPc  *runtime.Func
-}
-
-// attaches the file and line number corresponding to
-// the log message
-func (l *logger) linePrefix(skipFrames int) string {
-	runtime.Callers(skipFrames, l.pc)
-	funcForPc := runtime.FuncForPC(l.pc[0])
-	file, line := funcForPc.FileLine(l.pc[0] - 1)
-	return fmt.Sprintf("%s%s:%d ", l.prefix, filepath.Base(file), line)
-}
-
-func (l *logger) print(out io.Writer, skipFrames int, severity string, arg interface{}) {
-	buf := bufferPool.Get()
-	defer bufferPool.Put(buf)
-
-	GetPrepender()(buf)
-	linePrefix := l.linePrefix(skipFrames)
-	writeHeader := func() {
-		buf.WriteString(severity)
-		buf.WriteString(" ")
-		buf.WriteString(linePrefix)
-	}
-	if arg != nil {
-		ml, isMultiline := arg.(MultiLine)
-		if !isMultiline {
-			writeHeader()
-			fmt.Fprintf(buf, "%v", arg)
-			printContext(buf, arg)
-			buf.WriteByte('\n')
-		} else {
-			mlp := ml.MultiLinePrinter()
-			first := true
-			for {
-				writeHeader()
-				more := mlp(buf)
-				if first {
-					printContext(buf, arg)
-					first = false
-				}
-				buf.WriteByte('\n')
-				if !more {
-					break
-				}
-			}
-		}
+	onFatal    func(err error)
+	noReport   bool
+	noRegister bool
+	headers    atomic.Value
+}
+
+// callersEnabled reports whether this logger captures the callers of log
+// calls. When it doesn't, runtime.Callers and FuncForPC aren't called at all.
+func (l *logger) callersEnabled() bool {
+	return !l.noCaller && atomic.LoadInt32(&callersDisabled) == 0
+}
+
+// caller returns the base file name and line number of the log call, or
+// nothing if caller capture is disabled.
+func (l *logger) caller(skipFrames int) (string, int) {
+	if !l.callersEnabled() {
+		return "", 0
+	}
+	if l.callerFile != "" {
+		return l.callerFile, l.callerLine
+	}
+	n := runtime.Callers(skipFrames+l.callerSkip, l.pc)
+	if n == 0 {
+		// The stack is shallower than skipFrames (e.g. on the TraceOut goroutine),
+		// report the most recently recorded caller.
+		n = 1
+	}
+	frame, _ := runtime.CallersFrames(l.pc[:n]).Next()
+	return filepath.Base(frame.File), frame.Line
+}
+
+func (l *logger) SetFormatter(f Formatter) {
+	l.formatter.Store(&formatterHolder{f})
+}
+
+// getOutputs returns the outputs of this logger, which are the package-level
+// outputs unless set for this logger or one of its parents with setOutputs.
+func (l *logger) getOutputs() *outputs {
+	if o, ok := l.outs.Load().(*outputs); ok {
+		return o
+	}
+	if l.parent != nil {
+		return l.parent.getOutputs()
+	}
+	return GetOutputs()
+}
+
+// setOutputs sets the outputs of this logger and its children, instead of the
+// package-level outputs
+func (l *logger) setOutputs(errorOut io.Writer, debugOut io.Writer) {
+	l.outs.Store(&outputs{ErrorOut: errorOut, DebugOut: debugOut})
+}
+
+// getFormatter returns the Formatter to use for this logger
+func (l *logger) getFormatter() Formatter {
+	if f := l.ownFormatter(); f != nil {
+		return f
+	}
+	return GetFormatter()
+}
+
+// ownFormatter returns the Formatter set for this logger or the closest of
+// its parents with one, or nil if the package-level Formatter applies.
+func (l *logger) ownFormatter() Formatter {
+	if h, ok := l.formatter.Load().(*formatterHolder); ok && h.Formatter != nil {
+		return h.Formatter
+	}
+	if l.parent != nil {
+		return l.parent.ownFormatter()
+	}
+	return nil
+}
+
+// printEntry applies filters to the given entry and writes it to out, either
+// directly if out is an EntryWriter or else using this logger's Formatter.
+func (l *logger) printEntry(out io.Writer, e Entry) {
+	e, ok := l.filter(e)
+	if !ok {
+		return
+	}
+	e = limit(sanitize(e))
+	var err error
+	switch o := out.(type) {
+	case formattedEntryWriter:
+		err = o.writeFormatted(e, l.format(out, e))
+	case EntryWriter:
+		e.formatter = l.ownFormatter()
+		err = o.WriteEntry(e)
+	default:
+		_, err = out.Write(l.format(out, e))
 	}
-	b := []byte(hidden.Clean(buf.String()))
-	_, err := out.Write(b)
 	if err != nil {
 		errorOnLogging(err)
 	}
-	if l.printStack {
+	if l.printStack && l.callersEnabled() {
 		l.doPrintStack()
 	}
 }
 
-func (l *logger) printf(out io.Writer, skipFrames int, severity string, err error, message string, args ...interface{}) {
-	buf := bufferPool.Get()
-	defer bufferPool.Put(buf)
+// format formats the given entry for writing to out
+func (l *logger) format(out io.Writer, e Entry) []byte {
+	f := l.getFormatter()
+	if cf, ok := f.(colorFormatter); ok {
+		return cf.format(e, useColor(out))
+	}
+	return f.Format(e)
+}
 
-	GetPrepender()(buf)
-	linePrefix := l.linePrefix(skipFrames)
-	buf.WriteString(severity)
-	buf.WriteString(" ")
-	buf.WriteString(linePrefix)
-	fmt.Fprintf(buf, message, args...)
-	printContext(buf, err)
-	buf.WriteByte('\n')
-	b := []byte(hidden.Clean(buf.String()))
-	_, err2 := out.Write(b)
-	if err2 != nil {
-		errorOnLogging(err2)
+func (l *logger) print(out io.Writer, skipFrames int, severity Severity, arg interface{}) {
+	if !l.enabled(severity) {
+		return
 	}
-	if l.printStack {
-		l.doPrintStack()
+	file, line := l.caller(skipFrames)
+	e := l.newEntry(severity, file, line, arg)
+	if l.withStack {
+		e.Stack = append(e.Stack, l.getStackOptions().apply(stackLines(skipFrames+l.callerSkip))...)
 	}
+	l.printEntry(out, e)
+}
+
+func (l *logger) printf(out io.Writer, skipFrames int, severity Severity, message string, args ...interface{}) {
+	if !l.enabled(severity) {
+		return
+	}
+	file, line := l.caller(skipFrames)
+	e := l.newEntry(severity, file, line, nil)
+	e.Message = cleanHidden(fmt.Sprintf(message, args...))
+	if l.withStack {
+		e.Stack = append(e.Stack, l.getStackOptions().apply(stackLines(skipFrames+l.callerSkip))...)
+	}
+	l.printEntry(out, e)
 }
 
 func (l *logger) Debug(arg interface{}) {
-	l.print(GetOutputs().DebugOut, 4, "DEBUG", arg)
+	l.print(l.getOutputs().DebugOut, 4, DEBUG, arg)
 }
 
 func (l *logger) Debugf(message string, args ...interface{}) {
-	l.printf(GetOutputs().DebugOut, 4, "DEBUG", nil, message, args...)
+	l.printf(l.getOutputs().DebugOut, 4, DEBUG, message, args...)
+}
+
+func (l *logger) Info(arg interface{}) {
+	l.print(l.getOutputs().DebugOut, 4, INFO, arg)
+}
+
+func (l *logger) Infof(message string, args ...interface{}) {
+	l.printf(l.getOutputs().DebugOut, 4, INFO, message, args...)
+}
+
+func (l *logger) Warn(arg interface{}) {
+	if !l.enabled(WARN) && !hasReporters() {
+		return
+	}
+	l.errorSkipFrames(arg, 1, WARN)
+}
+
+func (l *logger) Warnf(message string, args ...interface{}) {
+	if !l.enabled(WARN) && !hasReporters() {
+		return
+	}
+	l.errorSkipFrames(fmt.Errorf(message, args...), 1, WARN)
 }
 
 func (l *logger) Error(arg interface{}) error {
@@ -314,16 +578,23 @@ func (l *logger) Errorf(message string, args ...interface{}) error {
 }
 
 func (l *logger) Fatal(arg interface{}) {
-	fatal(l.errorSkipFrames(arg, 1, FATAL))
+	l.fatal(l.errorSkipFrames(arg, 1, FATAL))
 }
 
 func (l *logger) Fatalf(message string, args ...interface{}) {
-	fatal(l.errorSkipFrames(errors.NewOffset(1, message, args...), 1, FATAL))
+	l.fatal(l.errorSkipFrames(errors.NewOffset(1, message, args...), 1, FATAL))
 }
 
-func fatal(err error) {
-	fn := onFatal.Load().(func(err error))
-	fn(err)
+// fatal calls the onFatal function of this logger or its closest parent that
+// has one, or else the package-level fatal hooks and OnFatal function
+func (l *logger) fatal(err error) {
+	for c := l; c != nil; c = c.parent {
+		if c.onFatal != nil {
+			c.onFatal(err)
+			return
+		}
+	}
+	fatal(err)
 }
 
 func (l *logger) errorSkipFrames(arg interface{}, skipFrames int, severity Severity) error {
@@ -334,27 +605,48 @@ func (l *logger) errorSkipFrames(arg interface{}, skipFrames int, severity Sever
 	default:
 		err = fmt.Errorf("%v", e)
 	}
-	l.print(GetOutputs().ErrorOut, skipFrames+4, severity.String(), err)
-	return report(err, severity)
+	l.print(l.getOutputs().ErrorOut, skipFrames+4, severity, err)
+	if l.noReport {
+		return err
+	}
+	return report(err, severity, l.prefix, l.fields)
 }
 
 func (l *logger) Trace(arg interface{}) {
-	if l.traceOn {
-		l.print(GetOutputs().DebugOut, 4, "TRACE", arg)
+	if l.IsTraceEnabled() {
+		l.print(l.getOutputs().DebugOut, 4, TRACE, arg)
 	}
 }
 
 func (l *logger) Tracef(message string, args ...interface{}) {
-	if l.traceOn {
-		l.printf(GetOutputs().DebugOut, 4, "TRACE", nil, message, args...)
+	if l.IsTraceEnabled() {
+		l.printf(l.getOutputs().DebugOut, 4, TRACE, message, args...)
 	}
 }
 
 func (l *logger) TraceOut() io.Writer {
+	if !l.IsTraceEnabled() {
+		return ioutil.Discard
+	}
+	l.traceMx.Lock()
+	defer l.traceMx.Unlock()
+	if l.traceOut == nil {
+		l.traceOut = l.newTraceWriter()
+	}
 	return l.traceOut
 }
 
+func (l *logger) IsEnabled(severity Severity) bool {
+	if severity == TRACE && !l.IsTraceEnabled() {
+		return false
+	}
+	return l.enabled(severity)
+}
+
 func (l *logger) IsTraceEnabled() bool {
+	if resolved := l.resolveLevel(); resolved.traceFound {
+		return resolved.trace
+	}
 	return l.traceOn
 }
 
@@ -362,7 +654,7 @@ func (l *logger) newTraceWriter() io.Writer {
 	pr, pw := io.Pipe()
 	br := bufio.NewReader(pr)
 
-	if !l.traceOn {
+	if !l.IsTraceEnabled() {
 		return pw
 	}
 	go func() {
@@ -379,36 +671,24 @@ func (l *logger) newTraceWriter() io.Writer {
 
 		for {
 			line, err := br.ReadString('\n')
-			if err == nil {
-				// Log the line (minus the trailing newline)
-				l.print(GetOutputs().DebugOut, 6, "TRACE", line[:len(line)-1])
-			} else {
-				l.printf(GetOutputs().DebugOut, 6, "TRACE", nil, "TraceWriter closed due to unexpected error: %v", err)
+			if err != nil {
+				if l.IsTraceEnabled() {
+					l.printf(l.getOutputs().DebugOut, 6, TRACE, "TraceWriter closed due to unexpected error: %v", err)
+				}
 				return
 			}
+			if l.IsTraceEnabled() {
+				// Log the line (minus the trailing newline)
+				l.print(l.getOutputs().DebugOut, 6, TRACE, line[:len(line)-1])
+			}
 		}
 	}()
 
 	return pw
 }
 
-type errorWriter struct {
-	l *logger
-}
-
-// Write implements method of io.Writer, due to different call depth,
-// it will not log correct file and line prefix
-func (w *errorWriter) Write(p []byte) (n int, err error) {
-	s := string(p)
-	if s[len(s)-1] == '\n' {
-		s = s[:len(s)-1]
-	}
-	w.l.print(GetOutputs().ErrorOut, 6, "ERROR", s)
-	return len(p), nil
-}
-
 func (l *logger) AsStdLogger() *log.Logger {
-	return log.New(&errorWriter{l}, "", 0)
+	return l.AsStdLoggerAt(ERROR)
 }
 
 func (l *logger) doPrintStack() {
@@ -435,31 +715,14 @@ func errorOnLogging(err error) {
 	fmt.Fprintf(os.Stderr, "Unable to log: %v\n", err)
 }
 
-func printContext(buf *bytes.Buffer, err interface{}) {
-	// Note - we don't include globals when printing in order to avoid polluting the text log
-	values := ops.AsMap(err, false)
-	if len(values) == 0 {
-		return
-	}
-	buf.WriteString(" [")
-	var keys []string
-	for key := range values {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-	for i, key := range keys {
-		value := values[key]
-		if i > 0 {
-			buf.WriteString(" ")
-		}
-		buf.WriteString(key)
-		buf.WriteString("=")
-		fmt.Fprintf(buf, "%v", value)
-	}
-	buf.WriteByte(']')
+// hasReporters indicates whether or not any ErrorReporters are registered
+func hasReporters() bool {
+	reportersMutex.RLock()
+	defer reportersMutex.RUnlock()
+	return len(reporters) > 0
 }
 
-func report(err error, severity Severity) error {
+func report(err error, severity Severity, prefix string, fields map[string]interface{}) error {
 	var reportersCopy []ErrorReporter
 	reportersMutex.RLock()
 	if len(reporters) > 0 {
@@ -469,8 +732,16 @@ func report(err error, severity Severity) error {
 	reportersMutex.RUnlock()
 
 	if len(reportersCopy) > 0 {
-		ctx := ops.AsMap(err, true)
+		ctx := contextValues(err, true)
+		for key, value := range fields {
+			ctx[key] = value
+		}
+		ctx = limitValues(sanitizeValues(addGlobalFields(ctx)))
 		ctx["severity"] = severity.String()
+		ctx["error_fingerprint"] = Fingerprint(err)
+		if prefix != "" {
+			ctx["prefix"] = prefix
+		}
 		for _, reporter := range reportersCopy {
 			// We include globals when reporting
 			reporter(err, severity, ctx)
@@ -63,10 +63,17 @@ func TestReport(t *testing.T) {
 		// ignore (prevents test from exiting)
 	})
 
+	warnings := 0
 	errors := 0
 	fatals := 0
 	RegisterReporter(func(err error, severity Severity, ctx map[string]interface{}) {
+		if ctx["prefix"] != "reporting" {
+			// reporters can't be unregistered, ignore other tests
+			return
+		}
 		switch severity {
+		case WARN:
+			warnings++
 		case ERROR:
 			errors++
 		case FATAL:
@@ -74,8 +81,11 @@ func TestReport(t *testing.T) {
 		}
 	})
 	l := LoggerFor("reporting")
+	l.Info("Some info")
+	l.Warn("Some warning")
 	l.Error("Some error")
 	l.Fatal("Fatal error")
+	assert.Equal(t, 1, warnings)
 	assert.Equal(t, 1, errors)
 	assert.Equal(t, 1, fatals)
 }
@@ -90,6 +100,26 @@ func TestDebug(t *testing.T) {
 	assert.Equal(t, expected("DEBUG", expectedLog), out.String())
 }
 
+func TestInfo(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	l := LoggerFor("myprefix")
+	l.Info("Hello world")
+	defer ops.Begin("name").Set("cvarA", "a").Set("cvarB", "b").End()
+	l.Infof("Hello %v", true)
+	assert.Equal(t, expected("INFO", expectedLog), out.String())
+}
+
+func TestWarn(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(out, ioutil.Discard)
+	l := LoggerFor("myprefix")
+	l.Warn("Hello world")
+	defer ops.Begin("name").Set("cvarA", "a").Set("cvarB", "b").End()
+	l.Warnf("Hello %v", true)
+	assert.Equal(t, expected("WARN", expectedLog), out.String())
+}
+
 func TestError(t *testing.T) {
 	out := newBuffer()
 	SetOutputs(out, ioutil.Discard)
@@ -106,6 +136,21 @@ func TestError(t *testing.T) {
 	assert.Equal(t, expectedErrorLog, out.String())
 }
 
+func TestErrorSingleWrite(t *testing.T) {
+	out := &countingWriter{}
+	multiOut := &countingWriter{}
+	for _, o := range []io.Writer{out, MultiOutput(Sink{Out: multiOut})} {
+		reset := SetOutputs(o, ioutil.Discard)
+		l := LoggerFor("myprefix").WithStack()
+		l.Error(errors.New("Hello %v", errorReturner()))
+		reset()
+	}
+	for _, o := range []*countingWriter{out, multiOut} {
+		assert.True(t, strings.Count(o.String(), "\n") > 5, "should have logged the stack")
+		assert.Equal(t, 1, o.writes, "multi-line error should be written with a single Write")
+	}
+}
+
 func errorReturner() error {
 	defer ops.Begin("name").Set("cvarD", "d").End()
 	return errors.New("world")
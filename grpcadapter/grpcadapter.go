@@ -0,0 +1,238 @@
+//go:build grpc
+// +build grpc
+
+// Package grpcadapter provides gRPC interceptors that log every RPC through
+// golog with the fields "grpc.method", "grpc.code", "elapsed" and "peer". For
+// example:
+//
+//	l := golog.LoggerFor("myapp.grpc")
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcadapter.UnaryServerInterceptor(l, nil)),
+//		grpc.StreamInterceptor(grpcadapter.StreamServerInterceptor(l, nil)),
+//	)
+//
+// RPCs are logged at INFO if they succeed, at WARN if they fail with a code
+// that's usually caused by the client, like InvalidArgument or NotFound, and
+// at ERROR, which is also reported to the ErrorReporters, otherwise. Server
+// interceptors attach the logger, including the method, to the context of the
+// call, so that handlers can log with golog.FromContext(ctx).
+//
+// gRPC isn't a dependency of golog, so this package is only built with the
+// "grpc" build tag, e.g. go build -tags grpc, and requires google.golang.org/grpc
+// in the go.mod of the main module.
+package grpcadapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/getlantern/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Options configures the interceptors
+type Options struct {
+	// LogPayloads logs every request and response message at TRACE, if TRACE
+	// is enabled for the logger
+	LogPayloads bool
+}
+
+// UnaryServerInterceptor returns an interceptor that logs unary RPCs handled
+// by a server. opts may be nil.
+func UnaryServerInterceptor(l golog.Logger, opts *Options) grpc.UnaryServerInterceptor {
+	o := options(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		rl := callLogger(l, info.FullMethod, peerOf(ctx))
+		ctx = golog.NewContext(ctx, rl)
+		o.logPayload(rl, "request", req)
+		resp, err := handler(ctx, req)
+		if err == nil {
+			o.logPayload(rl, "response", resp)
+		}
+		logCall(rl, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that logs streaming RPCs
+// handled by a server once they end. opts may be nil.
+func StreamServerInterceptor(l golog.Logger, opts *Options) grpc.StreamServerInterceptor {
+	o := options(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		rl := callLogger(l, info.FullMethod, peerOf(ss.Context()))
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: golog.NewContext(ss.Context(), rl), l: rl, opts: o})
+		logCall(rl, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns an interceptor that logs unary RPCs made by
+// a client. opts may be nil.
+func UnaryClientInterceptor(l golog.Logger, opts *Options) grpc.UnaryClientInterceptor {
+	o := options(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		rl := callLogger(l, method, cc.Target())
+		o.logPayload(rl, "request", req)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if err == nil {
+			o.logPayload(rl, "response", reply)
+		}
+		logCall(rl, method, err, time.Since(start))
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that logs streaming RPCs made
+// by a client once the client has received the end of the stream or an error.
+// opts may be nil.
+func StreamClientInterceptor(l golog.Logger, opts *Options) grpc.StreamClientInterceptor {
+	o := options(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		rl := callLogger(l, method, cc.Target())
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			logCall(rl, method, err, time.Since(start))
+			return nil, err
+		}
+		return &clientStream{ClientStream: cs, l: rl, method: method, start: start, opts: o}, nil
+	}
+}
+
+func options(opts *Options) *Options {
+	if opts == nil {
+		return &Options{}
+	}
+	return opts
+}
+
+// logPayload logs msg at TRACE if enabled
+func (o *Options) logPayload(l golog.Logger, kind string, msg interface{}) {
+	if o.LogPayloads && l.IsTraceEnabled() {
+		l.Tracef("%v: %v", kind, msg)
+	}
+}
+
+// callLogger returns l with the method and peer of a call as fields
+func callLogger(l golog.Logger, method string, remote string) golog.Logger {
+	fields := map[string]interface{}{"grpc.method": method}
+	if remote != "" {
+		fields["peer"] = remote
+	}
+	return l.WithFields(fields)
+}
+
+// peerOf returns the address of the peer of a server call
+func peerOf(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// logCall logs the end of a call with the given error
+func logCall(l golog.Logger, method string, err error, elapsed time.Duration) {
+	code := status.Code(err)
+	l = l.WithoutCaller().WithFields(map[string]interface{}{
+		"grpc.code": code.String(),
+		"elapsed":   golog.Duration("elapsed", elapsed),
+	})
+	switch {
+	case code == codes.OK:
+		l.Infof("%v OK", method)
+	case clientCaused(code):
+		l.WithError(err).Warnf("%v %v", method, code)
+	default:
+		l.WithError(err).Error(fmt.Sprintf("%v %v", method, code))
+	}
+}
+
+// clientCaused reports whether calls failing with code were usually caused
+// by the client rather than the server
+func clientCaused(code codes.Code) bool {
+	switch code {
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange:
+		return true
+	default:
+		return false
+	}
+}
+
+// serverStream attaches the logger to the context of a stream and logs its
+// messages
+type serverStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	l    golog.Logger
+	opts *Options
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.opts.logPayload(s.l, "response", m)
+	}
+	return err
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.opts.logPayload(s.l, "request", m)
+	}
+	return err
+}
+
+// clientStream logs the messages of a client stream and its end
+type clientStream struct {
+	grpc.ClientStream
+	l        golog.Logger
+	method   string
+	start    time.Time
+	opts     *Options
+	doneOnce sync.Once
+}
+
+func (s *clientStream) SendMsg(m interface{}) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.opts.logPayload(s.l, "request", m)
+	} else if err != io.EOF {
+		s.done(err)
+	}
+	return err
+}
+
+func (s *clientStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch err {
+	case nil:
+		s.opts.logPayload(s.l, "response", m)
+	case io.EOF:
+		s.done(nil)
+	default:
+		s.done(err)
+	}
+	return err
+}
+
+// done logs the end of the stream once
+func (s *clientStream) done(err error) {
+	s.doneOnce.Do(func() {
+		logCall(s.l, s.method, err, time.Since(s.start))
+	})
+}
@@ -0,0 +1,51 @@
+//go:build grpc
+// +build grpc
+
+package grpcadapter
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := golog.SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	interceptor := UnaryServerInterceptor(golog.LoggerFor("grpcadapter"), nil)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+	info := &grpc.UnaryServerInfo{FullMethod: "/pkg.Service/Get"}
+
+	resp, err := interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		golog.FromContext(ctx).Debug("handling")
+		return "resp", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+	assert.Regexp(t, `^DEBUG grpcadapter: grpcadapter_test.go:\d+ handling \[grpc.method=/pkg.Service/Get peer=10.0.0.1:1234\]
+INFO grpcadapter: /pkg.Service/Get OK \[elapsed=\S+ grpc.code=OK grpc.method=/pkg.Service/Get peer=10.0.0.1:1234\]
+$`, debugOut.String())
+
+	_, err = interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "no such thing")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Regexp(t, `^WARN grpcadapter: /pkg.Service/Get NotFound \[elapsed=\S+ error=.*no such thing.* grpc.code=NotFound`, errorOut.String())
+
+	errorOut.Reset()
+	interceptor(ctx, "req", info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "broken")
+	})
+	assert.Regexp(t, `^ERROR grpcadapter: /pkg.Service/Get Internal `, errorOut.String())
+}
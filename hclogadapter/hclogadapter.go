@@ -0,0 +1,198 @@
+//go:build hclog
+// +build hclog
+
+// Package hclogadapter provides an hclog.Logger that logs through golog, so
+// that embedded HashiCorp components like raft, serf and go-plugin share
+// golog's formatting, outputs, severity levels and ErrorReporters. For
+// example:
+//
+//	raftConfig.Logger = hclogadapter.NewLogger("myapp.raft")
+//
+// Names of sub-loggers created with Named are mapped to golog prefixes, so a
+// logger named "snapshot" logs with the prefix "myapp.raft.snapshot" and its
+// level can be changed with golog.SetPrefixLevel like that of any other
+// prefix. SetLevel does the same for the prefix of the logger.
+//
+// hclog isn't a dependency of golog, so this package is only built with the
+// "hclog" build tag, e.g. go build -tags hclog, and requires
+// github.com/hashicorp/go-hclog in the go.mod of the main module.
+package hclogadapter
+
+import (
+	"io"
+	"log"
+
+	"github.com/getlantern/golog"
+	"github.com/hashicorp/go-hclog"
+)
+
+// off is the severity to which the level of prefixes is set for hclog.Off
+const off = golog.FATAL + 100
+
+type logger struct {
+	prefix  string
+	name    string
+	implied []interface{}
+	l       golog.Logger
+}
+
+// NewLogger creates an hclog.Logger that logs with the given golog prefix
+func NewLogger(prefix string) hclog.Logger {
+	return newLogger(prefix, "", nil)
+}
+
+func newLogger(prefix string, name string, implied []interface{}) *logger {
+	fullPrefix := prefix
+	if name != "" {
+		fullPrefix += "." + name
+	}
+	l := golog.LoggerFor(fullPrefix)
+	if len(implied) > 0 {
+		l = l.With(implied...)
+	}
+	return &logger{prefix: prefix, name: name, implied: implied, l: l}
+}
+
+// Severity returns the golog Severity at which entries of the given level are
+// logged
+func Severity(level hclog.Level) golog.Severity {
+	switch level {
+	case hclog.Trace:
+		return golog.TRACE
+	case hclog.Debug:
+		return golog.DEBUG
+	case hclog.Warn:
+		return golog.WARN
+	case hclog.Error:
+		return golog.ERROR
+	case hclog.Off:
+		return off
+	default:
+		return golog.INFO
+	}
+}
+
+func (h *logger) Log(level hclog.Level, msg string, args ...interface{}) {
+	h.log(Severity(level), msg, args)
+}
+
+func (h *logger) Trace(msg string, args ...interface{}) { h.log(golog.TRACE, msg, args) }
+func (h *logger) Debug(msg string, args ...interface{}) { h.log(golog.DEBUG, msg, args) }
+func (h *logger) Info(msg string, args ...interface{})  { h.log(golog.INFO, msg, args) }
+func (h *logger) Warn(msg string, args ...interface{})  { h.log(golog.WARN, msg, args) }
+func (h *logger) Error(msg string, args ...interface{}) { h.log(golog.ERROR, msg, args) }
+
+// log logs msg with args, attaching errors under the key "error" or "err"
+// with WithError. It must be called directly by the methods of hclog.Logger so
+// that entries report the location of their callers.
+func (h *logger) log(severity golog.Severity, msg string, args []interface{}) {
+	if severity >= off {
+		return
+	}
+	l := h.l.WithCallerSkip(2)
+	if len(args) > 0 {
+		var keyvals []interface{}
+		for i := 0; i < len(args); i += 2 {
+			if i+1 < len(args) {
+				if err, ok := args[i+1].(error); ok && (args[i] == "error" || args[i] == "err") {
+					l = l.WithError(err)
+					continue
+				}
+				keyvals = append(keyvals, args[i], args[i+1])
+			} else {
+				keyvals = append(keyvals, args[i])
+			}
+		}
+		if len(keyvals) > 0 {
+			l = l.With(keyvals...)
+		}
+	}
+	switch {
+	case severity >= golog.ERROR:
+		l.Error(msg)
+	case severity >= golog.WARN:
+		l.Warn(msg)
+	case severity >= golog.INFO:
+		l.Info(msg)
+	case severity >= golog.DEBUG:
+		l.Debug(msg)
+	default:
+		l.Trace(msg)
+	}
+}
+
+func (h *logger) IsTrace() bool { return h.l.IsEnabled(golog.TRACE) }
+func (h *logger) IsDebug() bool { return h.l.IsEnabled(golog.DEBUG) }
+func (h *logger) IsInfo() bool  { return h.l.IsEnabled(golog.INFO) }
+func (h *logger) IsWarn() bool  { return h.l.IsEnabled(golog.WARN) }
+func (h *logger) IsError() bool { return h.l.IsEnabled(golog.ERROR) }
+
+func (h *logger) ImpliedArgs() []interface{} {
+	return h.implied
+}
+
+func (h *logger) With(args ...interface{}) hclog.Logger {
+	implied := append(append([]interface{}(nil), h.implied...), args...)
+	return newLogger(h.prefix, h.name, implied)
+}
+
+func (h *logger) Name() string {
+	return h.name
+}
+
+func (h *logger) Named(name string) hclog.Logger {
+	if h.name != "" {
+		name = h.name + "." + name
+	}
+	return newLogger(h.prefix, name, h.implied)
+}
+
+func (h *logger) ResetNamed(name string) hclog.Logger {
+	return newLogger(h.prefix, name, h.implied)
+}
+
+// SetLevel sets the level of this logger's golog prefix, see
+// golog.SetPrefixLevel
+func (h *logger) SetLevel(level hclog.Level) {
+	if level == hclog.NoLevel {
+		level = hclog.DefaultLevel
+	}
+	golog.SetPrefixLevel(h.fullPrefix(), Severity(level))
+}
+
+func (h *logger) GetLevel() hclog.Level {
+	for _, level := range []hclog.Level{hclog.Trace, hclog.Debug, hclog.Info, hclog.Warn, hclog.Error} {
+		if h.l.IsEnabled(Severity(level)) {
+			return level
+		}
+	}
+	return hclog.Off
+}
+
+func (h *logger) fullPrefix() string {
+	if h.name == "" {
+		return h.prefix
+	}
+	return h.prefix + "." + h.name
+}
+
+// StandardLogger returns a standard logger that logs at the ForceLevel of
+// opts, or INFO if it's not set, or that infers the levels of lines from
+// their leading token if InferLevels is set
+func (h *logger) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	if opts == nil {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+	severity := golog.Severity(golog.INFO)
+	if opts.ForceLevel != hclog.NoLevel {
+		severity = Severity(opts.ForceLevel)
+	}
+	if opts.InferLevels {
+		return h.l.AsSniffingStdLogger(severity)
+	}
+	return h.l.AsStdLoggerAt(severity)
+}
+
+func (h *logger) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	return h.StandardLogger(opts).Writer()
+}
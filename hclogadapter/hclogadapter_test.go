@@ -0,0 +1,41 @@
+//go:build hclog
+// +build hclog
+
+package hclogadapter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := golog.SetOutputs(errorOut, debugOut)
+	defer reset()
+	defer golog.ResetPrefixLevels()
+
+	var l hclog.Logger = NewLogger("hclogadapter")
+	l.Info("starting", "port", 8080)
+	sub := l.Named("raft").With("node", "a")
+	sub.Debug("elected")
+	sub.Error("failed", "error", errors.New("boom"))
+	assert.Equal(t, "raft", sub.Name())
+	assert.Equal(t, []interface{}{"node", "a"}, sub.ImpliedArgs())
+
+	sub.SetLevel(hclog.Warn)
+	assert.Equal(t, hclog.Warn, sub.GetLevel())
+	assert.False(t, sub.IsDebug())
+	sub.Debug("suppressed")
+
+	assert.Regexp(t, `^INFO hclogadapter: hclogadapter_test.go:\d+ starting \[port=8080\]
+DEBUG hclogadapter.raft: hclogadapter_test.go:\d+ elected \[node=a\]
+$`, debugOut.String())
+	assert.Regexp(t, `^ERROR hclogadapter.raft: hclogadapter_test.go:\d+ failed \[error=boom error_type=errors.errorString node=a\]
+`, errorOut.String())
+}
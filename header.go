@@ -0,0 +1,47 @@
+package golog
+
+import (
+	"sync/atomic"
+)
+
+// labelsVersion is incremented whenever the severity labels change, which
+// invalidates precomputed headers
+var labelsVersion uint64
+
+// header is the precomputed "SEVERITY prefix: " segment with which the
+// TextFormatter starts each line of entries logged by a logger at a severity.
+type header struct {
+	severity Severity
+	prefix   string
+	labels   uint64
+	text     string
+}
+
+// header returns the precomputed header for entries logged by this logger at
+// the given severity, computing and caching it on first use.
+func (l *logger) header(severity Severity) *header {
+	version := atomic.LoadUint64(&labelsVersion)
+	headers, _ := l.headers.Load().(map[Severity]*header)
+	if h := headers[severity]; h != nil && h.labels == version {
+		return h
+	}
+	h := &header{
+		severity: severity,
+		prefix:   l.prefix,
+		labels:   version,
+		text:     severity.String() + " " + l.prefix + ": ",
+	}
+	updated := make(map[Severity]*header, len(headers)+1)
+	for s, existing := range headers {
+		updated[s] = existing
+	}
+	updated[severity] = h
+	l.headers.Store(updated)
+	return h
+}
+
+// matches reports whether h is still the header for e, which isn't the case if
+// a filter changed its severity or prefix or the severity labels changed.
+func (h *header) matches(e Entry) bool {
+	return h != nil && h.severity == e.Severity && h.prefix == e.Prefix && h.labels == atomic.LoadUint64(&labelsVersion)
+}
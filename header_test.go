@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrecomputedHeader(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix").WithoutCaller()
+	l.Debug("first")
+	h := l.(*logger).header(DEBUG)
+	assert.Equal(t, "DEBUG myprefix: ", h.text)
+	assert.True(t, h == l.(*logger).header(DEBUG), "header should be cached")
+
+	SetSeverityLabels(map[Severity]string{DEBUG: "D"})
+	l.Debug("relabeled")
+	SetSeverityLabels(nil)
+
+	l.(*logger).AddFilter(func(e Entry) (Entry, bool) {
+		e.Prefix = "rewritten"
+		return e, true
+	})
+	l.Debug("filtered")
+
+	assert.Equal(t, `DEBUG myprefix: first
+D myprefix: relabeled
+DEBUG rewritten: filtered
+`, out.String())
+}
@@ -0,0 +1,178 @@
+package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxRetryBackoff = time.Minute
+)
+
+// HTTPOptions configures an HTTPOutput
+type HTTPOptions struct {
+	// Headers are added to each request, e.g. for authentication
+	Headers map[string]string
+
+	// Formatter formats each entry as a line of the request body, defaults to
+	// a JSONFormatter.
+	Formatter Formatter
+
+	// Gzip compresses request bodies with gzip
+	Gzip bool
+
+	// MaxRetries is the number of times a failed request is retried, defaults
+	// to 3. Requests are retried if they fail with a network error, a 5xx
+	// status or a 429 status.
+	MaxRetries int
+
+	// RetryBackoff is how long to wait before the first retry, defaults to 1
+	// second. The wait doubles with each subsequent retry, up to a minute.
+	RetryBackoff time.Duration
+
+	// QueueSize is the maximum number of entries waiting to be sent, defaults
+	// to 10000. Entries logged while the queue is full are dropped.
+	// Dropped returns how many were.
+	QueueSize int
+
+	// BatchSize is the maximum number of entries per request, defaults to 512.
+	BatchSize int
+
+	// FlushInterval is the maximum time entries wait before being sent,
+	// defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// Client is the http.Client used for sending, defaults to a client with a
+	// 30 second timeout.
+	Client *http.Client
+}
+
+func (opts *HTTPOptions) applyDefaults() {
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 10000
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 512
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+}
+
+// HTTPOutput is an output that POSTs entries in batches to an HTTP(S)
+// endpoint as newline delimited JSON (NDJSON), or whatever the configured
+// Formatter produces. Entries are sent on a background goroutine.
+type HTTPOutput struct {
+	*batcher
+	endpoint string
+	opts     HTTPOptions
+}
+
+// NewHTTPOutput creates an HTTPOutput that POSTs to the given endpoint. opts
+// may be nil.
+func NewHTTPOutput(endpoint string, opts *HTTPOptions) *HTTPOutput {
+	o := &HTTPOutput{endpoint: endpoint}
+	if opts != nil {
+		o.opts = *opts
+	}
+	o.opts.applyDefaults()
+	if o.opts.Formatter == nil {
+		o.opts.Formatter = &JSONFormatter{}
+	}
+	o.batcher = newBatcher(o.opts.QueueSize, o.opts.BatchSize, o.opts.FlushInterval, o.send)
+	return o
+}
+
+// Write implements io.Writer, sending each write as the message of an entry
+// without severity.
+func (o *HTTPOutput) Write(p []byte) (int, error) {
+	return len(p), o.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (o *HTTPOutput) WriteEntry(e Entry) error {
+	return o.add(e)
+}
+
+func (o *HTTPOutput) send(batch []Entry) {
+	buf := &bytes.Buffer{}
+	for _, e := range batch {
+		line := o.opts.Formatter.Format(e)
+		buf.Write(line)
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	if err := postWithRetry(o.endpoint, "application/x-ndjson", buf.Bytes(), &o.opts); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+// postWithRetry POSTs body to url, retrying with exponential backoff as
+// configured in opts.
+func postWithRetry(url string, contentType string, body []byte, opts *HTTPOptions) error {
+	encoding := ""
+	if opts.Gzip {
+		gzipped := &bytes.Buffer{}
+		gzw := gzip.NewWriter(gzipped)
+		gzw.Write(body)
+		gzw.Close()
+		body = gzipped.Bytes()
+		encoding = "gzip"
+	}
+
+	backoff := opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := post(url, contentType, encoding, body, opts)
+		if err == nil || !retry || attempt >= opts.MaxRetries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// post POSTs body to url, returning an error and whether or not the request
+// should be retried if it failed.
+func post(url string, contentType string, encoding string, body []byte, opts *HTTPOptions) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	for key, value := range opts.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected response status posting to %v: %v", url, resp.Status)
+}
@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPOutput(t *testing.T) {
+	var attempts int32
+	bodies := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", req.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", req.Header.Get("Content-Encoding"))
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+		gzr, err := gzip.NewReader(req.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		b, _ := ioutil.ReadAll(gzr)
+		bodies <- string(b)
+	}))
+	defer server.Close()
+
+	o := NewHTTPOutput(server.URL, &HTTPOptions{
+		Headers:      map[string]string{"Authorization": "Bearer token"},
+		Formatter:    &customFormatter{},
+		Gzip:         true,
+		RetryBackoff: time.Millisecond,
+	})
+	defer o.Close()
+
+	assert.NoError(t, o.WriteEntry(Entry{Severity: ERROR, Prefix: "myprefix", Message: "Hello world"}))
+	o.Write([]byte("plain\n"))
+	o.Flush()
+
+	assert.EqualValues(t, 2, atomic.LoadInt32(&attempts))
+	select {
+	case body := <-bodies:
+		assert.Equal(t, "ERROR|myprefix||0|Hello world|0\nUNKNOWN|||0|plain|0\n", body)
+	default:
+		t.Fatal("nothing sent")
+	}
+}
+
+func TestHTTPOutputNoRetryOnClientError(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		resp.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	opts := &HTTPOptions{RetryBackoff: time.Millisecond}
+	opts.applyDefaults()
+	err := postWithRetry(server.URL, "text/plain", []byte("hi"), opts)
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "400"))
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&attempts))
+}
@@ -0,0 +1,176 @@
+//go:build linux
+// +build linux
+
+package golog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var (
+	journalSocket = "/run/systemd/journal/socket"
+)
+
+// JournaldOptions configures a JournaldOutput
+type JournaldOptions struct {
+	// Identifier is the SYSLOG_IDENTIFIER of each entry, defaults to the name
+	// of the executable.
+	Identifier string
+}
+
+// JournaldOutput is an output that sends entries to the systemd journal using
+// its native protocol. Severities are mapped to journal priorities and instead
+// of being flattened into the MESSAGE, the caller is recorded as CODE_FILE and
+// CODE_LINE, the prefix as GOLOG_PREFIX, the stack as GOLOG_STACK and each
+// context value as a separate field named after its upper-cased key (e.g.
+// cvarA becomes CVARA).
+type JournaldOutput struct {
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+	identifier string
+}
+
+// NewJournaldOutput creates a JournaldOutput, failing if the journal isn't
+// available. opts may be nil.
+func NewJournaldOutput(opts *JournaldOptions) (*JournaldOutput, error) {
+	j := &JournaldOutput{
+		addr:       &net.UnixAddr{Name: journalSocket, Net: "unixgram"},
+		identifier: filepath.Base(os.Args[0]),
+	}
+	if opts != nil && opts.Identifier != "" {
+		j.identifier = opts.Identifier
+	}
+	if _, err := os.Stat(journalSocket); err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	j.conn = conn
+	return j, nil
+}
+
+// Write implements io.Writer, writing p as the MESSAGE of an entry with debug
+// priority.
+func (j *JournaldOutput) Write(p []byte) (int, error) {
+	buf := &bytes.Buffer{}
+	j.writeHeader(buf, DEBUG)
+	writeJournalField(buf, "MESSAGE", strings.TrimSuffix(string(p), "\n"))
+	return len(p), j.send(buf.Bytes())
+}
+
+// WriteEntry implements EntryWriter
+func (j *JournaldOutput) WriteEntry(e Entry) error {
+	buf := &bytes.Buffer{}
+	j.writeHeader(buf, e.Severity)
+	writeJournalField(buf, "MESSAGE", e.Message)
+	if e.Prefix != "" {
+		writeJournalField(buf, "GOLOG_PREFIX", e.Prefix)
+	}
+	if e.File != "" {
+		writeJournalField(buf, "CODE_FILE", e.File)
+		writeJournalField(buf, "CODE_LINE", strconv.Itoa(e.Line))
+	}
+	if len(e.Stack) > 0 {
+		writeJournalField(buf, "GOLOG_STACK", strings.Join(e.Stack, "\n"))
+	}
+	for _, key := range sortedKeys(e.Context) {
+		writeJournalField(buf, journalFieldName(key), fmt.Sprint(e.Context[key]))
+	}
+	return j.send(buf.Bytes())
+}
+
+// Close closes the connection to the journal
+func (j *JournaldOutput) Close() error {
+	return j.conn.Close()
+}
+
+func (j *JournaldOutput) writeHeader(buf *bytes.Buffer, severity Severity) {
+	writeJournalField(buf, "PRIORITY", strconv.Itoa(syslogSeverity(severity)))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", j.identifier)
+}
+
+// send sends the given message to the journal. Messages too large for a
+// datagram are written to a temporary file whose descriptor is passed to the
+// journal instead.
+func (j *JournaldOutput) send(msg []byte) error {
+	_, _, err := j.conn.WriteMsgUnix(msg, nil, j.addr)
+	if err == nil {
+		return nil
+	}
+	if !isMessageTooLarge(err) {
+		return err
+	}
+
+	file, err := ioutil.TempFile("/dev/shm", "golog-journal-")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		return err
+	}
+	if _, err := file.Write(msg); err != nil {
+		return err
+	}
+	_, _, err = j.conn.WriteMsgUnix(nil, syscall.UnixRights(int(file.Fd())), j.addr)
+	return err
+}
+
+func isMessageTooLarge(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	if !ok {
+		return false
+	}
+	sysErr, ok := opErr.Err.(*os.SyscallError)
+	if !ok {
+		return false
+	}
+	return sysErr.Err == syscall.EMSGSIZE || sysErr.Err == syscall.ENOBUFS
+}
+
+// writeJournalField writes a field using the journal's native protocol, which
+// requires values containing newlines to be written with an explicit length.
+func writeJournalField(buf *bytes.Buffer, name string, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// journalFieldName converts key into a valid journal field name, which may
+// only contain upper case letters, digits and underscores and must not start
+// with an underscore or digit.
+func journalFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if name == "" || name[0] == '_' || (name[0] >= '0' && name[0] <= '9') {
+		name = "X" + name
+	}
+	return name
+}
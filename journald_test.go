@@ -0,0 +1,69 @@
+//go:build linux
+// +build linux
+
+package golog
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournaldOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	oldSocket := journalSocket
+	journalSocket = filepath.Join(dir, "socket")
+	defer func() {
+		journalSocket = oldSocket
+	}()
+	l, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: journalSocket, Net: "unixgram"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+
+	j, err := NewJournaldOutput(&JournaldOptions{Identifier: "myapp"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer j.Close()
+
+	assert.NoError(t, j.WriteEntry(Entry{
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"at a", "at b"},
+		Context:  map[string]interface{}{"cvarA": "a", "1st-key": 1},
+	}))
+	b := make([]byte, 4096)
+	n, err := l.Read(b)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "PRIORITY=3\n"+
+			"SYSLOG_IDENTIFIER=myapp\n"+
+			"MESSAGE=Hello world\n"+
+			"GOLOG_PREFIX=myprefix\n"+
+			"CODE_FILE=file.go\n"+
+			"CODE_LINE=12\n"+
+			"GOLOG_STACK\n\x09\x00\x00\x00\x00\x00\x00\x00at a\nat b\n"+
+			"X1ST_KEY=1\n"+
+			"CVARA=a\n", string(b[:n]))
+	}
+
+	_, err = j.Write([]byte("plain\n"))
+	assert.NoError(t, err)
+	n, err = l.Read(b)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "PRIORITY=7\nSYSLOG_IDENTIFIER=myapp\nMESSAGE=plain\n", string(b[:n]))
+	}
+}
@@ -0,0 +1,261 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONFormatter is a Formatter that writes each entry as a single-line JSON
+// object, for example:
+//
+//	{"time":"2019-06-10T15:04:05.999999999Z","severity":"ERROR","prefix":"myprefix","caller":"file.go:12","message":"Hello world","context":{"op":"name"},"stack":"  at ..."}
+//
+// Context values that are strings, booleans or numbers are written as such,
+// all other values are written as strings using fmt.Sprint, unless
+// NestValues is set.
+type JSONFormatter struct {
+	// NestValues writes context values that are maps, slices, arrays or
+	// implement json.Marshaler as nested JSON, preserving their structure for
+	// query engines.
+	NestValues bool
+
+	// StructuredStack writes the stack as an array under the "error.stack"
+	// key instead of as a single string under "stack", with an object for
+	// each frame like {"function":"main.run","file":"main.go","line":12},
+	// {"caused_by":"message"} for the causes of errors and {"text":"…"} for
+	// any other lines.
+	StructuredStack bool
+}
+
+func (f *JSONFormatter) Format(e Entry) []byte {
+	buf := bufferPool.Get()
+	defer bufferPool.Put(buf)
+	buf.WriteString(`{"time":`)
+	writeJSONString(buf, e.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"severity":`)
+	writeJSONString(buf, e.Severity.String())
+	buf.WriteString(`,"prefix":`)
+	writeJSONString(buf, e.Prefix)
+	if e.File != "" {
+		buf.WriteString(`,"caller":`)
+		writeJSONString(buf, e.File+":"+strconv.Itoa(e.Line))
+	}
+	buf.WriteString(`,"message":`)
+	writeJSONString(buf, e.Message)
+	if len(e.Context) > 0 {
+		buf.WriteString(`,"context":`)
+		if f.NestValues {
+			writeNestedJSONValue(buf, e.Context)
+		} else {
+			writeJSONObject(buf, e.Context)
+		}
+	}
+	if len(e.Stack) > 0 {
+		if f.StructuredStack {
+			buf.WriteString(`,"error.stack":`)
+			writeJSONStack(buf, e.Stack)
+		} else {
+			buf.WriteString(`,"stack":`)
+			writeJSONString(buf, strings.Join(e.Stack, "\n"))
+		}
+	}
+	buf.WriteString("}\n")
+	return copyBytes(buf)
+}
+
+// writeJSONObject writes the given values as a JSON object with sorted keys
+func writeJSONObject(buf *bytes.Buffer, values map[string]interface{}) {
+	buf.WriteByte('{')
+	for i, key := range sortedKeys(values) {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, key)
+		buf.WriteByte(':')
+		writeJSONValue(buf, values[key])
+	}
+	buf.WriteByte('}')
+}
+
+// writeJSONValue writes strings, booleans, numbers and typed Fields as JSON
+// values and everything else as a JSON string using fmt.Sprint.
+func writeJSONValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		writeJSONString(buf, v)
+	case Field:
+		v.writeJSON(buf)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		writeJSONInt(buf, int64(v))
+	case int64:
+		writeJSONInt(buf, v)
+	case int8, int16, int32, uint, uint8, uint16, uint32, uint64, float32, float64:
+		writeJSON(buf, v)
+	default:
+		writeJSONString(buf, fmt.Sprint(v))
+	}
+}
+
+func writeJSONInt(buf *bytes.Buffer, i int64) {
+	var scratch [20]byte
+	buf.Write(strconv.AppendInt(scratch[:0], i, 10))
+}
+
+// writeNestedJSONValue is like writeJSONValue, but writes maps, slices and
+// arrays as nested JSON objects and arrays, and uses json.Marshaler if
+// implemented. Map keys are formatted with fmt.Sprint and sorted.
+func writeNestedJSONValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+		return
+	case Field:
+		if v.typ == anyField {
+			writeNestedJSONValue(buf, v.iface)
+		} else {
+			v.writeJSON(buf)
+		}
+		return
+	case json.Marshaler:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			buf.WriteString("null")
+			return
+		}
+		b, err := v.MarshalJSON()
+		if err == nil && json.Valid(b) {
+			if compactErr := json.Compact(buf, b); compactErr == nil {
+				return
+			}
+		}
+		writeJSONString(buf, fmt.Sprint(v))
+		return
+	case error, fmt.Stringer, []byte:
+		writeJSONValue(buf, v)
+		return
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]interface{}, rv.Len())
+		for _, key := range rv.MapKeys() {
+			k := fmt.Sprint(key.Interface())
+			keys = append(keys, k)
+			values[k] = rv.MapIndex(key).Interface()
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			writeNestedJSONValue(buf, values[key])
+		}
+		buf.WriteByte('}')
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			buf.WriteString("null")
+			return
+		}
+		buf.WriteByte('[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeNestedJSONValue(buf, rv.Index(i).Interface())
+		}
+		buf.WriteByte(']')
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			buf.WriteString("null")
+			return
+		}
+		writeJSONValue(buf, value)
+	default:
+		writeJSONValue(buf, value)
+	}
+}
+
+// writeJSONString writes s as a JSON string, escaping it the same way as
+// encoding/json without HTML escaping, but without allocating.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf.WriteString(s[start:i])
+			switch b {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(b)
+			case '\b':
+				buf.WriteString(`\b`)
+			case '\f':
+				buf.WriteString(`\f`)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[b>>4])
+				buf.WriteByte(hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteRune(utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSON writes the JSON encoding of v without escaping HTML characters
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		// Only happens for unsupported values like NaN, fall back to a string
+		writeJSONString(buf, fmt.Sprint(v))
+		return
+	}
+	// Remove trailing newline added by Encoder
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,124 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFormatter(t *testing.T) {
+	SetFormatter(&JSONFormatter{})
+	defer SetFormatter(nil)
+
+	out := newBuffer()
+	SetOutputs(out, out)
+	l := LoggerFor("myprefix")
+	defer ops.Begin("name").Set("cvarA", "a").Set("cvarB", 5).End()
+	l.Debugf("Hello %v", true)
+	l.Error(errors.New("world"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if !assert.Len(t, lines, 2) {
+		return
+	}
+
+	var debug map[string]interface{}
+	if assert.NoError(t, json.Unmarshal([]byte(lines[0]), &debug)) {
+		assert.Equal(t, "DEBUG", debug["severity"])
+		assert.Equal(t, "myprefix", debug["prefix"])
+		assert.Equal(t, "json_test.go:999", debug["caller"])
+		assert.Equal(t, "Hello true", debug["message"])
+		assert.Equal(t, map[string]interface{}{"cvarA": "a", "cvarB": float64(999), "op": "name", "root_op": "name"}, debug["context"])
+		assert.Nil(t, debug["stack"])
+	}
+
+	var errorEntry map[string]interface{}
+	if assert.NoError(t, json.Unmarshal([]byte(lines[1]), &errorEntry)) {
+		assert.Equal(t, "ERROR", errorEntry["severity"])
+		assert.Equal(t, "world", errorEntry["message"])
+		assert.Contains(t, errorEntry["stack"], "  at github.com/getlantern/golog.TestJSONFormatter")
+		assert.Equal(t, "errors.Error", errorEntry["context"].(map[string]interface{})["error_type"])
+	}
+}
+
+func TestPerLoggerFormatter(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&JSONFormatter{})
+	l.Debug("Hello world")
+	assert.True(t, strings.HasPrefix(out.String(), `{"time":`), out.String())
+
+	l.SetFormatter(nil)
+	out = newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	l.Debug("Hello world")
+	assert.Equal(t, "DEBUG myprefix: json_test.go:999 Hello world\n", out.String())
+}
+
+type testMarshaler struct{ Name string }
+
+func (m *testMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{ "name": "` + m.Name + `" }`), nil
+}
+
+func TestJSONFormatterNestValues(t *testing.T) {
+	e := Entry{
+		Severity: DEBUG,
+		Message:  "Hello",
+		Context: map[string]interface{}{
+			"map":       map[string]interface{}{"b": []int{1, 2}, "a": map[int]bool{1: true}},
+			"slice":     []interface{}{"x", nil, 1.5},
+			"marshaler": &testMarshaler{"bob"},
+			"nil":       (*testMarshaler)(nil),
+			"field":     Any("field", []string{"y"}),
+			"error":     fmt.Errorf("failed"),
+			"plain":     5,
+		},
+	}
+	nested := string((&JSONFormatter{NestValues: true}).Format(e))
+	assert.Contains(t, nested, `"context":{"error":"failed","field":["y"],"map":{"a":{"1":true},"b":[1,2]},"marshaler":{"name":"bob"},"nil":null,"plain":5,"slice":["x",null,1.5]}`)
+
+	flat := string((&JSONFormatter{}).Format(e))
+	assert.Contains(t, flat, `"slice":"[x <nil> 1.5]"`)
+}
+
+func TestJSONFormatterStructuredStack(t *testing.T) {
+	e := Entry{
+		Severity: ERROR,
+		Message:  "failed",
+		Stack: []string{
+			"  at main.a (main.go:1)",
+			"  ... 3 more frames",
+			"Caused by: cause",
+			"  at main.c (/src/main.go:3)",
+		},
+	}
+	out := (&JSONFormatter{StructuredStack: true}).Format(e)
+	assert.Contains(t, string(out), `,"error.stack":[{"function":"main.a","file":"main.go","line":1},{"text":"... 3 more frames"},{"caused_by":"cause"},{"function":"main.c","file":"/src/main.go","line":3}]}`)
+	assert.True(t, json.Valid(out))
+	assert.NotContains(t, string(out), `"stack":`)
+}
+
+func TestWriteJSONString(t *testing.T) {
+	var all []byte
+	for b := 0; b < 0x80; b++ {
+		all = append(all, byte(b))
+	}
+	for _, s := range []string{"", "plain", "quote\" backslash\\ <html> & tab\t", string(all), "héllo    wörld", "invalid \xff\xfe utf-8", "emoji 😀"} {
+		buf := &bytes.Buffer{}
+		writeJSONString(buf, s)
+		expected := &bytes.Buffer{}
+		enc := json.NewEncoder(expected)
+		enc.SetEscapeHTML(false)
+		enc.Encode(s)
+		assert.Equal(t, strings.TrimSuffix(expected.String(), "\n"), buf.String())
+	}
+}
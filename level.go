@@ -0,0 +1,218 @@
+package golog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	level int64
+
+	levelRulesMx sync.Mutex
+	levelRules   atomic.Value
+)
+
+func init() {
+	levelRules.Store(&levelRuleSet{traces: make(map[string]bool)})
+}
+
+// levelRuleSet holds the configured per-prefix levels, vmodule patterns and
+// per-prefix trace settings. It is immutable and replaced whenever the
+// configuration changes, which lets loggers cache the level resolved for their
+// prefix until then.
+type levelRuleSet struct {
+	prefixes map[string]Severity
+	patterns []levelPattern
+	traces   map[string]bool
+}
+
+// levelPattern is a vmodule rule
+type levelPattern struct {
+	pattern  string
+	severity Severity
+}
+
+// resolve finds the level configured for the given prefix. Levels set for the
+// exact prefix take precedence over patterns, which are tried in order.
+func (rs *levelRuleSet) resolve(prefix string) (Severity, bool) {
+	if severity, found := rs.prefixes[prefix]; found {
+		return severity, true
+	}
+	for _, p := range rs.patterns {
+		if matched, _ := path.Match(p.pattern, prefix); matched {
+			return p.severity, true
+		}
+	}
+	return 0, false
+}
+
+// resolvedLevel is the level and trace setting resolved for a logger's prefix
+// from a given levelRuleSet.
+type resolvedLevel struct {
+	rules      *levelRuleSet
+	severity   Severity
+	found      bool
+	trace      bool
+	traceFound bool
+}
+
+// SetLevel sets the minimum severity of entries written by all loggers, for
+// example SetLevel(INFO) to suppress DEBUG and TRACE entries. It can be
+// changed at any time. Errors below the level are still returned and reported
+// to ErrorReporters, they're just not written. TRACE entries additionally
+// require tracing to be enabled.
+func SetLevel(severity Severity) {
+	atomic.StoreInt64(&level, int64(severity))
+}
+
+// GetLevel returns the minimum severity of entries written, as set with
+// SetLevel. The default of 0 writes entries of all severities.
+func GetLevel() Severity {
+	return Severity(atomic.LoadInt64(&level))
+}
+
+// SetPrefixLevel sets the minimum severity of entries written by loggers with
+// the given prefix, overriding the level set with SetLevel in either
+// direction. For example, to quieten a chatty component while everything else
+// logs at DEBUG:
+//
+//	golog.SetLevel(golog.DEBUG)
+//	golog.SetPrefixLevel("flashlight.proxy", golog.ERROR)
+func SetPrefixLevel(prefix string, severity Severity) {
+	updateLevelRules(func(rs *levelRuleSet) {
+		rs.prefixes[prefix] = severity
+	})
+}
+
+// ResetPrefixLevels removes all levels set with SetPrefixLevel
+func ResetPrefixLevels() {
+	updateLevelRules(func(rs *levelRuleSet) {
+		rs.prefixes = make(map[string]Severity)
+	})
+}
+
+// EnableTrace enables tracing for loggers with the given prefix while the
+// process runs, including loggers that already exist, regardless of the TRACE
+// and GOLOG_TRACE environment variables.
+func EnableTrace(prefix string) {
+	setTrace(prefix, true)
+}
+
+// DisableTrace disables tracing for loggers with the given prefix while the
+// process runs, including loggers that already exist, regardless of the TRACE
+// and GOLOG_TRACE environment variables.
+func DisableTrace(prefix string) {
+	setTrace(prefix, false)
+}
+
+func setTrace(prefix string, on bool) {
+	updateLevelRules(func(rs *levelRuleSet) {
+		rs.traces[prefix] = on
+	})
+}
+
+// clearPrefix removes the level and trace setting of the given prefix
+func clearPrefix(prefix string) {
+	updateLevelRules(func(rs *levelRuleSet) {
+		delete(rs.prefixes, prefix)
+		delete(rs.traces, prefix)
+	})
+}
+
+// levelFor returns the minimum severity of entries written by loggers with the
+// given prefix.
+func levelFor(prefix string) Severity {
+	if severity, found := levelRules.Load().(*levelRuleSet).resolve(prefix); found {
+		return severity
+	}
+	return GetLevel()
+}
+
+// SetVModule sets the minimum severity of entries written by loggers whose
+// prefix matches glob patterns, in the style of glog's -vmodule flag. spec is
+// a comma-separated list of pattern=severity rules, for example:
+//
+//	proxy*=TRACE,dns=DEBUG
+//
+// Patterns use the syntax of path.Match and are tried in order, the first
+// match wins. Levels set with SetPrefixLevel take precedence over patterns.
+// Severities are parsed with ParseSeverity. An empty spec removes all
+// patterns.
+func SetVModule(spec string) error {
+	var patterns []levelPattern
+	for _, rule := range strings.Split(spec, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid vmodule rule %v, expected pattern=severity", rule)
+		}
+		pattern := strings.TrimSpace(parts[0])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid vmodule pattern %v: %v", pattern, err)
+		}
+		severity, err := ParseSeverity(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return err
+		}
+		patterns = append(patterns, levelPattern{pattern, severity})
+	}
+	updateLevelRules(func(rs *levelRuleSet) {
+		rs.patterns = patterns
+	})
+	return nil
+}
+
+// updateLevelRules replaces the current levelRuleSet with an updated copy
+func updateLevelRules(update func(rs *levelRuleSet)) {
+	levelRulesMx.Lock()
+	defer levelRulesMx.Unlock()
+	current := levelRules.Load().(*levelRuleSet)
+	updated := &levelRuleSet{
+		prefixes: make(map[string]Severity, len(current.prefixes)),
+		patterns: current.patterns,
+		traces:   make(map[string]bool, len(current.traces)),
+	}
+	for prefix, severity := range current.prefixes {
+		updated.prefixes[prefix] = severity
+	}
+	for prefix, on := range current.traces {
+		updated.traces[prefix] = on
+	}
+	update(updated)
+	levelRules.Store(updated)
+}
+
+// enabled indicates whether or not entries with the given severity are
+// written by this logger.
+func (l *logger) enabled(severity Severity) bool {
+	return severity >= l.minSeverity()
+}
+
+// minSeverity returns the minimum severity of entries written by this logger
+func (l *logger) minSeverity() Severity {
+	resolved := l.resolveLevel()
+	if resolved.found {
+		return resolved.severity
+	}
+	return GetLevel()
+}
+
+// resolveLevel returns the level and trace setting resolved for this logger's
+// prefix from the current levelRuleSet, resolving them again if it changed.
+func (l *logger) resolveLevel() *resolvedLevel {
+	rules := levelRules.Load().(*levelRuleSet)
+	resolved, _ := l.level.Load().(*resolvedLevel)
+	if resolved == nil || resolved.rules != rules {
+		resolved = &resolvedLevel{rules: rules}
+		resolved.severity, resolved.found = rules.resolve(l.prefix)
+		resolved.trace, resolved.traceFound = rules.traces[l.prefix]
+		l.level.Store(resolved)
+	}
+	return resolved
+}
@@ -0,0 +1,172 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLevel(t *testing.T) {
+	errorOut := newBuffer()
+	debugOut := newBuffer()
+	SetOutputs(errorOut, debugOut)
+	defer SetLevel(0)
+
+	l := LoggerFor("myprefix")
+	assert.Equal(t, Severity(0), GetLevel())
+	SetLevel(INFO)
+	assert.Equal(t, Severity(INFO), GetLevel())
+	l.Debug("debug")
+	l.Info("info")
+	assert.Equal(t, "INFO myprefix: level_test.go:999 info\n", debugOut.String())
+
+	SetLevel(ERROR)
+	l.Info("info")
+	l.Warn("warn")
+	err := l.Error("error")
+	assert.Error(t, err, "suppressed errors should still be returned")
+	assert.Equal(t, "INFO myprefix: level_test.go:999 info\n", debugOut.String())
+	assert.Contains(t, errorOut.String(), "ERROR myprefix: level_test.go:999 error")
+	assert.NotContains(t, errorOut.String(), "warn")
+
+	SetLevel(FATAL)
+	before := errorOut.String()
+	l.Error("error")
+	assert.Equal(t, before, errorOut.String())
+
+	SetLevel(0)
+	l.Debug("debug")
+	assert.Contains(t, debugOut.String(), "debug")
+}
+
+func TestSetPrefixLevel(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	defer SetLevel(0)
+	defer ResetPrefixLevels()
+
+	chatty := LoggerFor("chatty")
+	other := LoggerFor("other")
+	SetLevel(INFO)
+	SetPrefixLevel("chatty", ERROR)
+	SetPrefixLevel("verbose", DEBUG)
+	verbose := LoggerFor("verbose")
+	chatty.Info("chatty info")
+	other.Info("other info")
+	other.Debug("other debug")
+	verbose.Debug("verbose debug")
+	assert.Equal(t, "INFO other: level_test.go:999 other info\nDEBUG verbose: level_test.go:999 verbose debug\n", out.String())
+
+	ResetPrefixLevels()
+	chatty.Info("chatty info")
+	verbose.Debug("verbose debug")
+	assert.Equal(t, "INFO other: level_test.go:999 other info\nDEBUG verbose: level_test.go:999 verbose debug\nINFO chatty: level_test.go:999 chatty info\n", out.String())
+}
+
+func TestSetVModule(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	defer SetLevel(0)
+	defer SetVModule("")
+	defer ResetPrefixLevels()
+
+	SetLevel(INFO)
+	if !assert.NoError(t, SetVModule(" proxy*=DEBUG, proxy.dns=ERROR ,dns=warn")) {
+		return
+	}
+	SetPrefixLevel("proxy.quiet", FATAL)
+	LoggerFor("proxy.http").Debug("proxy debug")
+	LoggerFor("proxy.dns").Debug("proxy.dns debug")
+	LoggerFor("proxy.quiet").Error("proxy.quiet error")
+	LoggerFor("dns").Info("dns info")
+	LoggerFor("dns").Warn("dns warn")
+	LoggerFor("other").Info("other info")
+	assert.Equal(t, "DEBUG proxy.http: level_test.go:999 proxy debug\nDEBUG proxy.dns: level_test.go:999 proxy.dns debug\nINFO other: level_test.go:999 other info\n", out.String(),
+		"first matching pattern should win")
+
+	assert.Error(t, SetVModule("proxy"))
+	assert.Error(t, SetVModule("[=DEBUG"))
+	assert.Error(t, SetVModule("proxy=LOUD"))
+}
+
+func TestEnableTrace(t *testing.T) {
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	defer clearPrefix("traced")
+
+	l := LoggerFor("traced")
+	untraced := LoggerFor("untraced")
+	l.Trace("before")
+	assert.False(t, l.IsTraceEnabled())
+	EnableTrace("traced")
+	assert.True(t, l.IsTraceEnabled())
+	assert.False(t, untraced.IsTraceEnabled())
+	l.Trace("enabled")
+	untraced.Trace("untraced")
+	l.TraceOut().Write([]byte("writer\n"))
+	time.Sleep(50 * time.Millisecond)
+	DisableTrace("traced")
+	l.Trace("disabled")
+	l.TraceOut().Write([]byte("discarded\n"))
+	time.Sleep(50 * time.Millisecond)
+	assert.Regexp(t, `^TRACE traced: level_test.go:999 enabled\nTRACE traced: \S+:999 writer\n$`, out.String())
+}
+
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "expensive"
+}
+
+func TestDisabledFastPath(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+
+	providerCalls := 0
+	SetContextProvider(ContextProviderFunc(func() map[string]interface{} {
+		providerCalls++
+		return nil
+	}))
+	defer SetContextProvider(OpsContextProvider)
+	extractorCalls := 0
+	RegisterContextExtractor(func(ctx context.Context) map[string]interface{} {
+		extractorCalls++
+		return nil
+	})
+
+	l := LoggerFor("fastpath")
+	SetPrefixLevel("fastpath", ERROR)
+	defer ResetPrefixLevels()
+	assert.False(t, l.IsEnabled(TRACE))
+	assert.False(t, l.IsEnabled(DEBUG))
+	assert.False(t, l.IsEnabled(INFO))
+	assert.True(t, l.IsEnabled(ERROR))
+
+	arg := &countingStringer{}
+	l.Debug(arg)
+	l.Debugf("%v", arg)
+	l.Infof("%v", arg)
+	l.Trace(arg)
+	l.DebugCtx(context.Background(), arg)
+	l.InfoCtx(context.Background(), arg)
+	l.TraceCtx(context.Background(), arg)
+	assert.Zero(t, arg.calls)
+	assert.Zero(t, providerCalls)
+	assert.Zero(t, extractorCalls)
+	assert.Empty(t, out.String())
+
+	SetPrefixLevel("fastpath", DEBUG)
+	l.Debugf("%v", arg)
+	l.DebugCtx(context.Background(), arg)
+	assert.Equal(t, 2, arg.calls)
+	assert.Equal(t, 2, providerCalls)
+	assert.Equal(t, 1, extractorCalls)
+}
@@ -0,0 +1,105 @@
+package golog
+
+import (
+	"fmt"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+var (
+	limits atomic.Value
+)
+
+func init() {
+	SetLimits(Limits{})
+}
+
+// Limits protects outputs from oversized entries. Limits that are 0 aren't
+// enforced.
+type Limits struct {
+	// MaxMessageLength is the maximum length in bytes of messages. Longer
+	// messages are cut and marked with "...[truncated N bytes]".
+	MaxMessageLength int
+
+	// MaxValueLength is the maximum length in bytes of context values. Longer
+	// values are converted to strings if necessary, cut and marked like
+	// messages.
+	MaxValueLength int
+
+	// MaxFields is the maximum number of context values. Values beyond the
+	// limit are dropped in key order and their number is recorded as the
+	// "fields_dropped" context value.
+	MaxFields int
+}
+
+// SetLimits sets the Limits applied to every entry, after filters, redaction
+// and scrubbing and before any output sees the entry, as well as to the
+// context passed to ErrorReporters.
+func SetLimits(l Limits) {
+	limits.Store(l)
+}
+
+// limit applies the current Limits to the given entry
+func limit(e Entry) Entry {
+	l := limits.Load().(Limits)
+	if l.MaxMessageLength > 0 {
+		e.Message = truncate(e.Message, l.MaxMessageLength)
+	}
+	e.Context = l.apply(e.Context)
+	return e
+}
+
+// limitValues applies the current Limits to the given context values
+func limitValues(values map[string]interface{}) map[string]interface{} {
+	return limits.Load().(Limits).apply(values)
+}
+
+// apply returns the given context values with the field and value limits
+// applied, copying them if necessary.
+func (l Limits) apply(values map[string]interface{}) map[string]interface{} {
+	copied := false
+	if l.MaxFields > 0 && len(values) > l.MaxFields {
+		keys := sortedKeys(values)
+		limited := make(map[string]interface{}, l.MaxFields+1)
+		for _, key := range keys[:l.MaxFields] {
+			limited[key] = values[key]
+		}
+		limited["fields_dropped"] = len(keys) - l.MaxFields
+		values, copied = limited, true
+	}
+	if l.MaxValueLength <= 0 {
+		return values
+	}
+	for key, value := range values {
+		var s string
+		switch v := value.(type) {
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			continue
+		case string:
+			s = v
+		default:
+			s = fmt.Sprint(v)
+		}
+		if len(s) <= l.MaxValueLength {
+			continue
+		}
+		if !copied {
+			values, copied = copyValues(values), true
+		}
+		values[key] = truncate(s, l.MaxValueLength)
+	}
+	return values
+}
+
+// truncate cuts s to at most max bytes, not counting the truncation marker,
+// without splitting UTF-8 sequences.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%v...[truncated %d bytes]", s[:cut], len(s)-cut)
+}
@@ -0,0 +1,31 @@
+package golog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+	SetLimits(Limits{MaxMessageLength: 9, MaxValueLength: 5, MaxFields: 3})
+	defer SetLimits(Limits{})
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	blob := strings.Repeat("x", 100)
+	fields := map[string]interface{}{"a": blob, "b": []int{1, 2, 3}, "c": 123456789, "d": "dropped"}
+	l.WithFields(fields).Debug("héllo wörld, this is long")
+	assert.Regexp(t, `^DEBUG myprefix: limits_test.go:\d+ héllo w...\[truncated 19 bytes\] \[a=xxxxx...\[truncated 95 bytes\] b=\[1 2 ...\[truncated 2 bytes\] c=123456789 fields_dropped=1\]\n$`, out.String())
+	assert.Equal(t, blob, fields["a"], "fields passed in shouldn't be modified")
+
+	SetLimits(Limits{})
+	out.Reset()
+	l.WithFields(fields).Debug("héllo wörld, this is long")
+	assert.Contains(t, out.String(), "héllo wörld, this is long [a="+blob)
+}
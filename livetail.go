@@ -0,0 +1,230 @@
+package golog
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	liveTailBufferSize = 1000
+)
+
+// LiveTail is an output that streams entries to clients connected over HTTP,
+// so that operators can tail a running process from a browser or a tool like
+// websocat. It is both an output (add it to the outputs, e.g. using
+// MultiOutput) and an http.Handler that upgrades requests to WebSocket and
+// sends each entry as a text message.
+//
+// Clients can filter entries using query parameters:
+//
+//	severity - minimum severity, e.g. severity=error
+//	prefix   - only entries whose prefix starts with this, e.g. prefix=flashlight
+//	format   - json to receive entries formatted with the JSONFormatter
+//
+// Entries are dropped for clients that can't keep up. See SSEHandler for
+// streaming entries without WebSocket.
+//
+// As browsers let any web page open a WebSocket to any host, handshakes from
+// pages of another origin than the LiveTail's own are rejected, unless allowed
+// with AllowOrigins. Other than that, the handler has no access control of its
+// own, so it should only be exposed to operators.
+type LiveTail struct {
+	mx             sync.RWMutex
+	subscribers    map[*liveTailSubscriber]bool
+	history        *RingBuffer
+	allowedOrigins map[string]bool
+}
+
+type liveTailSubscriber struct {
+	minSeverity Severity
+	prefix      string
+	formatter   Formatter
+	entries     chan []byte
+}
+
+// NewLiveTail creates a LiveTail
+func NewLiveTail() *LiveTail {
+	return &LiveTail{subscribers: make(map[*liveTailSubscriber]bool)}
+}
+
+// NewLiveTailWithHistory creates a LiveTail that keeps the last size entries
+// and sends those matching a client's filters to it when it connects, before
+// the live entries.
+func NewLiveTailWithHistory(size int) *LiveTail {
+	lt := NewLiveTail()
+	lt.history = NewRingBuffer(size)
+	return lt
+}
+
+// AllowOrigins allows WebSocket handshakes from pages of the given origins,
+// like "https://ops.example.com", in addition to the LiveTail's own origin.
+// "*" allows all origins.
+func (lt *LiveTail) AllowOrigins(origins ...string) {
+	lt.mx.Lock()
+	defer lt.mx.Unlock()
+	if lt.allowedOrigins == nil {
+		lt.allowedOrigins = make(map[string]bool, len(origins))
+	}
+	for _, origin := range origins {
+		lt.allowedOrigins[origin] = true
+	}
+}
+
+// originAllowed reports whether req comes from the LiveTail's own origin, an
+// allowed origin or a client that isn't a browser and sends no Origin.
+func (lt *LiveTail) originAllowed(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+	lt.mx.RLock()
+	defer lt.mx.RUnlock()
+	return lt.allowedOrigins[origin] || lt.allowedOrigins["*"]
+}
+
+// Write implements io.Writer, sending each write as the message of an entry
+// without severity.
+func (lt *LiveTail) Write(p []byte) (int, error) {
+	return len(p), lt.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (lt *LiveTail) WriteEntry(e Entry) error {
+	lt.mx.RLock()
+	defer lt.mx.RUnlock()
+	if lt.history != nil {
+		lt.history.WriteEntry(e)
+	}
+	for sub := range lt.subscribers {
+		if !sub.matches(e) {
+			continue
+		}
+		select {
+		case sub.entries <- sub.formatter.Format(e):
+		default:
+			// client can't keep up, drop entry
+		}
+	}
+	return nil
+}
+
+// ServeHTTP implements http.Handler
+func (lt *LiveTail) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if !lt.originAllowed(req) {
+		http.Error(resp, "cross-origin request not allowed", http.StatusForbidden)
+		return
+	}
+	sub, err := newLiveTailSubscriber(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Subscribe before upgrading so that the client sees everything logged
+	// once the handshake completes.
+	lt.subscribe(sub)
+	defer lt.unsubscribe(sub)
+
+	conn, rw, err := upgradeWebsocket(resp, req)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Read frames from the client, replying to pings and stopping when the
+	// client closes the connection.
+	closed := make(chan struct{})
+	pings := make(chan []byte, 1)
+	go func() {
+		defer close(closed)
+		for {
+			opcode, payload, err := readWebsocketFrame(rw.Reader, wsMaxControlPayload)
+			if err != nil {
+				return
+			}
+			switch opcode {
+			case wsOpClose:
+				return
+			case wsOpPing:
+				select {
+				case pings <- payload:
+				default:
+				}
+			}
+		}
+	}()
+
+	for {
+		select {
+		case b := <-sub.entries:
+			if writeWebsocketFrame(rw.Writer, wsOpText, b) != nil {
+				return
+			}
+		case payload := <-pings:
+			if writeWebsocketFrame(rw.Writer, wsOpPong, payload) != nil {
+				return
+			}
+		case <-closed:
+			writeWebsocketFrame(rw.Writer, wsOpClose, nil)
+			return
+		}
+	}
+}
+
+func newLiveTailSubscriber(req *http.Request) (*liveTailSubscriber, error) {
+	query := req.URL.Query()
+	sub := &liveTailSubscriber{
+		prefix:    query.Get("prefix"),
+		formatter: &TextFormatter{},
+		entries:   make(chan []byte, liveTailBufferSize),
+	}
+	if severity := query.Get("severity"); severity != "" {
+		s, err := ParseSeverity(severity)
+		if err != nil {
+			return nil, err
+		}
+		sub.minSeverity = s
+	}
+	if query.Get("format") == "json" {
+		sub.formatter = &JSONFormatter{}
+	}
+	return sub, nil
+}
+
+// matches reports whether e passes the subscriber's filters
+func (sub *liveTailSubscriber) matches(e Entry) bool {
+	return e.Severity >= sub.minSeverity && strings.HasPrefix(e.Prefix, sub.prefix)
+}
+
+// subscribe subscribes sub to entries, first queueing the most recent entries
+// of the history that match its filters
+func (lt *LiveTail) subscribe(sub *liveTailSubscriber) {
+	lt.mx.Lock()
+	defer lt.mx.Unlock()
+	if lt.history != nil {
+		var recent []Entry
+		for _, e := range lt.history.Entries() {
+			if sub.matches(e) {
+				recent = append(recent, e)
+			}
+		}
+		if len(recent) > cap(sub.entries) {
+			recent = recent[len(recent)-cap(sub.entries):]
+		}
+		for _, e := range recent {
+			sub.entries <- sub.formatter.Format(e)
+		}
+	}
+	lt.subscribers[sub] = true
+}
+
+func (lt *LiveTail) unsubscribe(sub *liveTailSubscriber) {
+	lt.mx.Lock()
+	delete(lt.subscribers, sub)
+	lt.mx.Unlock()
+}
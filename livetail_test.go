@@ -0,0 +1,120 @@
+package golog
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveTailWebsocket(t *testing.T) {
+	lt := NewLiveTail()
+	server := httptest.NewServer(lt)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	conn.Write([]byte("GET /?severity=error&prefix=my HTTP/1.1\r\n" +
+		"Host: localhost\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"))
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	lt.WriteEntry(Entry{Severity: DEBUG, Prefix: "myprefix", Message: "too low"})
+	lt.WriteEntry(Entry{Severity: ERROR, Prefix: "other", Message: "wrong prefix"})
+	lt.WriteEntry(Entry{Severity: ERROR, Prefix: "myprefix", Message: "Hello world"})
+
+	opcode, payload, err := readWebsocketFrame(br, 1024)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, wsOpText, opcode)
+		assert.Equal(t, "ERROR myprefix: Hello world\n", string(payload))
+	}
+
+	// Ping, then close the connection
+	conn.Write(maskedFrame(wsOpPing, []byte("hi")))
+	opcode, payload, err = readWebsocketFrame(br, 1024)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, wsOpPong, opcode)
+		assert.Equal(t, "hi", string(payload))
+	}
+	conn.Write(maskedFrame(wsOpClose, nil))
+	opcode, _, err = readWebsocketFrame(br, 1024)
+	if assert.NoError(t, err) {
+		assert.EqualValues(t, wsOpClose, opcode)
+	}
+}
+
+func TestLiveTailBadRequest(t *testing.T) {
+	lt := NewLiveTail()
+	server := httptest.NewServer(lt)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+	resp, err = http.Get(server.URL + "?severity=bogus")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestLiveTailOrigin(t *testing.T) {
+	lt := NewLiveTail()
+	server := httptest.NewServer(lt)
+	defer server.Close()
+
+	handshake := func(origin string) int {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusSwitchingProtocols, handshake(""), "clients without an Origin should be allowed")
+	assert.Equal(t, http.StatusSwitchingProtocols, handshake(server.URL), "same origin should be allowed")
+	assert.Equal(t, http.StatusForbidden, handshake("https://evil.example.com"))
+
+	lt.AllowOrigins("https://ops.example.com")
+	assert.Equal(t, http.StatusSwitchingProtocols, handshake("https://ops.example.com"))
+	assert.Equal(t, http.StatusForbidden, handshake("https://evil.example.com"))
+}
+
+// maskedFrame builds a small frame as sent by a client
+func maskedFrame(opcode byte, payload []byte) []byte {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
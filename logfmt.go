@@ -0,0 +1,74 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogfmtFormatter is a Formatter that writes each entry as a single line of
+// logfmt-style key=value pairs, for example:
+//
+//	time=2019-06-10T15:04:05.999999999Z severity=ERROR prefix=myprefix caller=file.go:12 msg="Hello world" op=name
+//
+// Context values follow the standard keys, sorted by key. Values containing
+// whitespace, quotes, '=' or control characters are quoted.
+type LogfmtFormatter struct{}
+
+func (f *LogfmtFormatter) Format(e Entry) []byte {
+	buf := &bytes.Buffer{}
+	writeLogfmtPair(buf, "time", e.Time.Format(time.RFC3339Nano))
+	buf.WriteByte(' ')
+	writeLogfmtPair(buf, "severity", e.Severity.String())
+	buf.WriteByte(' ')
+	writeLogfmtPair(buf, "prefix", e.Prefix)
+	buf.WriteByte(' ')
+	if e.File != "" {
+		writeLogfmtPair(buf, "caller", e.File+":"+strconv.Itoa(e.Line))
+		buf.WriteByte(' ')
+	}
+	writeLogfmtPair(buf, "msg", e.Message)
+	for _, key := range sortedKeys(e.Context) {
+		buf.WriteByte(' ')
+		writeLogfmtPair(buf, key, fmt.Sprint(e.Context[key]))
+	}
+	if len(e.Stack) > 0 {
+		buf.WriteByte(' ')
+		writeLogfmtPair(buf, "stack", strings.Join(e.Stack, "\n"))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func writeLogfmtPair(buf *bytes.Buffer, key string, value string) {
+	buf.WriteString(strings.Map(logfmtKeyRune, key))
+	buf.WriteByte('=')
+	if logfmtNeedsQuoting(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// logfmtKeyRune replaces characters that aren't allowed in logfmt keys
+func logfmtKeyRune(r rune) rune {
+	if r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar {
+		return '_'
+	}
+	return r
+}
+
+func logfmtNeedsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || r == '=' || r == '"' || r == '\\' || r == unicode.ReplacementChar {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package golog
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogfmtFormatter(t *testing.T) {
+	SetFormatter(&LogfmtFormatter{})
+	defer SetFormatter(nil)
+
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	l := LoggerFor("myprefix")
+	defer ops.Begin("name").Set("cvarA", "a b").Set("cvar=B", `say "hi"`).Set("cvarC", "").End()
+	l.Debugf("Hello %v", true)
+
+	line := out.String()
+	assert.True(t, strings.HasPrefix(line, "time="), line)
+	assert.Equal(t, ` severity=DEBUG prefix=myprefix caller=logfmt_test.go:999 msg="Hello true" cvar_B="say \"hi\"" cvarA="a b" cvarC="" op=name root_op=name`+"\n", line[strings.Index(line, " "):])
+}
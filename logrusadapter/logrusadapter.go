@@ -0,0 +1,94 @@
+//go:build logrus
+// +build logrus
+
+// Package logrusadapter provides a logrus.Hook that forwards logrus entries to
+// golog, so that code bases can migrate from github.com/sirupsen/logrus
+// incrementally while all entries go to golog's outputs. To stop logrus from
+// writing entries itself, discard its own output:
+//
+//	logrus.AddHook(logrusadapter.NewHook(golog.LoggerFor("myapp")))
+//	logrus.SetOutput(ioutil.Discard)
+//
+// logrus isn't a dependency of golog, so this package is only built with the
+// "logrus" build tag, e.g. go build -tags logrus, and requires
+// github.com/sirupsen/logrus in the go.mod of the main module.
+//
+// Levels are mapped to golog severities, with Panic and Fatal entries being
+// logged at ERROR. logrus itself panics or exits after firing hooks. Fields
+// become context values, except for the error added with WithError, which is
+// attached with Logger.WithError so that its causes are logged too. If logrus
+// reports callers, the caller is used as the file and line of the entry.
+package logrusadapter
+
+import (
+	"path/filepath"
+
+	"github.com/getlantern/golog"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook that logs entries to a golog.Logger
+type Hook struct {
+	l golog.Logger
+}
+
+// NewHook creates a Hook that logs to l
+func NewHook(l golog.Logger) *Hook {
+	return &Hook{l: l}
+}
+
+// Severity returns the golog Severity at which entries of the given level are
+// logged
+func Severity(level logrus.Level) golog.Severity {
+	switch level {
+	case logrus.TraceLevel:
+		return golog.TRACE
+	case logrus.DebugLevel:
+		return golog.DEBUG
+	case logrus.InfoLevel:
+		return golog.INFO
+	case logrus.WarnLevel:
+		return golog.WARN
+	default:
+		return golog.ERROR
+	}
+}
+
+// Levels implements logrus.Hook, firing for all levels
+func (h *Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	l := h.l
+	if entry.Caller != nil {
+		l = l.WithCaller(filepath.Base(entry.Caller.File), entry.Caller.Line)
+	} else {
+		l = l.WithoutCaller()
+	}
+	if len(entry.Data) > 0 {
+		fields := make(map[string]interface{}, len(entry.Data))
+		for key, value := range entry.Data {
+			if err, ok := value.(error); ok && key == logrus.ErrorKey {
+				l = l.WithError(err)
+				continue
+			}
+			fields[key] = value
+		}
+		l = l.WithFields(fields)
+	}
+	switch Severity(entry.Level) {
+	case golog.TRACE:
+		l.Trace(entry.Message)
+	case golog.DEBUG:
+		l.Debug(entry.Message)
+	case golog.INFO:
+		l.Info(entry.Message)
+	case golog.WARN:
+		l.Warn(entry.Message)
+	default:
+		l.Error(entry.Message)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//go:build logrus
+// +build logrus
+
+package logrusadapter
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHook(t *testing.T) {
+	buf := &bytes.Buffer{}
+	reset := golog.SetOutputs(buf, buf)
+	defer reset()
+
+	log := logrus.New()
+	log.SetOutput(ioutil.Discard)
+	log.SetLevel(logrus.DebugLevel)
+	log.SetReportCaller(true)
+	log.AddHook(NewHook(golog.LoggerFor("logrusadapter")))
+	log.WithField("a", 1).Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+	log.WithError(errors.New("boom")).Error("failed")
+
+	assert.Regexp(t, `^DEBUG logrusadapter: logrusadapter_test.go:\d+ debug \[a=1\]
+INFO logrusadapter: logrusadapter_test.go:\d+ info
+WARN logrusadapter: logrusadapter_test.go:\d+ warn
+ERROR logrusadapter: logrusadapter_test.go:\d+ failed \[error=boom error_type=errors.errorString\]
+ERROR logrusadapter: logrusadapter_test.go:\d+ Caused by: boom
+$`, buf.String())
+}
+
+func TestSeverity(t *testing.T) {
+	assert.EqualValues(t, golog.TRACE, Severity(logrus.TraceLevel))
+	assert.EqualValues(t, golog.WARN, Severity(logrus.WarnLevel))
+	assert.EqualValues(t, golog.ERROR, Severity(logrus.PanicLevel))
+}
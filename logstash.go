@@ -0,0 +1,65 @@
+package golog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+var logstashReservedKeys = map[string]bool{
+	"@timestamp":       true,
+	"@version":         true,
+	"level":            true,
+	"level_value":      true,
+	"logger_name":      true,
+	"message":          true,
+	"caller_file_name": true,
+	"caller_line":      true,
+	"stack_trace":      true,
+}
+
+// LogstashFormatter is a Formatter that writes entries as single-line JSON
+// objects following the Logstash event schema, for example:
+//
+//	{"@timestamp":"2019-06-10T15:04:05.999Z","@version":"1","level":"ERROR","level_value":500,"logger_name":"myprefix","message":"Hello world","caller_file_name":"file.go","caller_line":12,"op":"name"}
+//
+// Context values are written as top-level fields. Context keys that clash with
+// the standard fields are prefixed with "ctx_". Stack traces are written as
+// stack_trace.
+type LogstashFormatter struct{}
+
+func (f *LogstashFormatter) Format(e Entry) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"@timestamp":`)
+	writeJSONString(buf, e.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	buf.WriteString(`,"@version":"1","level":`)
+	writeJSONString(buf, e.Severity.String())
+	buf.WriteString(`,"level_value":`)
+	buf.WriteString(strconv.Itoa(int(e.Severity)))
+	buf.WriteString(`,"logger_name":`)
+	writeJSONString(buf, e.Prefix)
+	buf.WriteString(`,"message":`)
+	writeJSONString(buf, e.Message)
+	if e.File != "" {
+		buf.WriteString(`,"caller_file_name":`)
+		writeJSONString(buf, e.File)
+		buf.WriteString(`,"caller_line":`)
+		buf.WriteString(strconv.Itoa(e.Line))
+	}
+	if len(e.Stack) > 0 {
+		buf.WriteString(`,"stack_trace":`)
+		writeJSONString(buf, strings.Join(e.Stack, "\n"))
+	}
+	for _, key := range sortedKeys(e.Context) {
+		buf.WriteByte(',')
+		if logstashReservedKeys[key] {
+			writeJSONString(buf, "ctx_"+key)
+		} else {
+			writeJSONString(buf, key)
+		}
+		buf.WriteByte(':')
+		writeJSONValue(buf, e.Context[key])
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,39 @@
+package golog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogstashFormatter(t *testing.T) {
+	e := Entry{
+		Time:     time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.UTC),
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"  at a", "  at b"},
+		Context:  map[string]interface{}{"op": "name", "message": "clash", "count": 5},
+	}
+	var event map[string]interface{}
+	if assert.NoError(t, json.Unmarshal((&LogstashFormatter{}).Format(e), &event)) {
+		assert.Equal(t, map[string]interface{}{
+			"@timestamp":       "2019-06-10T15:04:05.123Z",
+			"@version":         "1",
+			"level":            "ERROR",
+			"level_value":      float64(500),
+			"logger_name":      "myprefix",
+			"message":          "Hello world",
+			"caller_file_name": "file.go",
+			"caller_line":      float64(12),
+			"stack_trace":      "  at a\n  at b",
+			"op":               "name",
+			"ctx_message":      "clash",
+			"count":            float64(5),
+		}, event)
+	}
+}
@@ -0,0 +1,309 @@
+//go:build grpc
+// +build grpc
+
+package logstream
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getlantern/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errClosed = errors.New("logstream client closed")
+
+	// tailRetryInterval is the time Tail waits before reconnecting
+	tailRetryInterval = time.Second
+)
+
+// ClientOptions configures a Client
+type ClientOptions struct {
+	// ID identifies the client to the server, which uses it to ignore entries
+	// that are resent after reconnecting but were already received. Defaults
+	// to a random ID.
+	ID string
+
+	// Window is the maximum number of unacknowledged entries. The client
+	// stops sending once it's reached, until the server acknowledges entries.
+	// Defaults to 1000.
+	Window int
+
+	// MaxBuffered is the maximum number of entries held by the client,
+	// including unacknowledged ones, defaults to 10000. Entries written while
+	// it's reached are dropped and counted (see Dropped).
+	MaxBuffered int
+
+	// RetryInterval is the time between attempts to reconnect, defaults to
+	// 1 second
+	RetryInterval time.Duration
+
+	// FlushTimeout is the maximum time Flush waits for the server to
+	// acknowledge all entries, defaults to 5 seconds
+	FlushTimeout time.Duration
+}
+
+// Client is a golog output that pushes entries to a Server. It numbers
+// entries and keeps them until the server acknowledges them, and after
+// reconnecting resends those that weren't acknowledged.
+type Client struct {
+	conn   grpc.ClientConnInterface
+	opts   ClientOptions
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mx      sync.Mutex
+	cond    *sync.Cond
+	pending []Entry
+	sent    int
+	nextSeq uint64
+	dropped uint64
+	broken  bool
+	closed  bool
+}
+
+// NewClient creates a Client that pushes entries over conn. opts may be nil.
+func NewClient(conn grpc.ClientConnInterface, opts *ClientOptions) *Client {
+	c := &Client{conn: conn, done: make(chan struct{})}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.ID == "" {
+		c.opts.ID = randomID()
+	}
+	if c.opts.Window <= 0 {
+		c.opts.Window = 1000
+	}
+	if c.opts.MaxBuffered <= 0 {
+		c.opts.MaxBuffered = 10000
+	}
+	if c.opts.RetryInterval <= 0 {
+		c.opts.RetryInterval = time.Second
+	}
+	if c.opts.FlushTimeout <= 0 {
+		c.opts.FlushTimeout = 5 * time.Second
+	}
+	c.cond = sync.NewCond(&c.mx)
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	go c.run()
+	return c
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity
+func (c *Client) Write(p []byte) (int, error) {
+	return len(p), c.WriteEntry(golog.Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements golog.EntryWriter
+func (c *Client) WriteEntry(e golog.Entry) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.closed {
+		return errClosed
+	}
+	if len(c.pending) >= c.opts.MaxBuffered {
+		c.dropped++
+		return nil
+	}
+	c.nextSeq++
+	c.pending = append(c.pending, Entry{Sequence: c.nextSeq, Entry: e})
+	c.cond.Broadcast()
+	return nil
+}
+
+// Dropped returns the number of entries dropped because MaxBuffered was
+// reached
+func (c *Client) Dropped() uint64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.dropped
+}
+
+// Flush waits up to FlushTimeout for the server to acknowledge all entries
+func (c *Client) Flush() error {
+	timeout := c.opts.FlushTimeout
+	deadline := time.Now().Add(timeout)
+	timer := time.AfterFunc(timeout, func() {
+		c.mx.Lock()
+		c.cond.Broadcast()
+		c.mx.Unlock()
+	})
+	defer timer.Stop()
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for len(c.pending) > 0 {
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("%d entries not acknowledged within %v", len(c.pending), timeout)
+		}
+		c.cond.Wait()
+	}
+	return nil
+}
+
+// Close flushes the client and disconnects it. Entries that weren't
+// acknowledged by then are lost.
+func (c *Client) Close() error {
+	err := c.Flush()
+	c.mx.Lock()
+	c.closed = true
+	c.cond.Broadcast()
+	c.mx.Unlock()
+	c.cancel()
+	<-c.done
+	return err
+}
+
+// run pushes entries until the client is closed, reconnecting when the
+// stream fails
+func (c *Client) run() {
+	defer close(c.done)
+	for {
+		c.push()
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-time.After(c.opts.RetryInterval):
+		}
+	}
+}
+
+// push sends pending entries over a new stream until it fails or the client
+// is closed
+func (c *Client) push() error {
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(c.ctx, clientIDKey, c.opts.ID))
+	defer cancel()
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], pushMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	c.mx.Lock()
+	// resend everything that wasn't acknowledged
+	c.sent = 0
+	c.broken = false
+	c.mx.Unlock()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			var ack Ack
+			if err := stream.RecvMsg(&ack); err != nil {
+				c.mx.Lock()
+				c.broken = true
+				c.cond.Broadcast()
+				c.mx.Unlock()
+				recvErr <- err
+				return
+			}
+			c.ack(ack.Sequence)
+		}
+	}()
+
+	for {
+		c.mx.Lock()
+		for !c.broken && !c.closed && (c.sent == len(c.pending) || c.sent >= c.opts.Window) {
+			c.cond.Wait()
+		}
+		if c.broken || c.closed {
+			c.mx.Unlock()
+			break
+		}
+		e := c.pending[c.sent]
+		c.sent++
+		c.mx.Unlock()
+		// blocks while the server doesn't keep up, per gRPC flow control
+		if err := stream.SendMsg(&e); err != nil {
+			break
+		}
+	}
+	cancel()
+	return <-recvErr
+}
+
+// ack removes the entries up to and including seq from the pending entries
+func (c *Client) ack(seq uint64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	n := 0
+	for n < len(c.pending) && c.pending[n].Sequence <= seq {
+		n++
+	}
+	c.pending = c.pending[n:]
+	c.sent -= n
+	if c.sent < 0 {
+		c.sent = 0
+	}
+	c.cond.Broadcast()
+}
+
+// inFlight returns the number of entries sent but not acknowledged
+func (c *Client) inFlight() int {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.sent
+}
+
+// Tail streams the entries matching req from the server to fn, until ctx is
+// done or fn returns an error. When the connection is lost, it reconnects and
+// resumes after the last entry it received.
+func Tail(ctx context.Context, conn grpc.ClientConnInterface, req TailRequest, fn func(e Entry) error) error {
+	for {
+		err := tail(ctx, conn, &req, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != io.EOF && status.Code(err) != codes.Unavailable {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tailRetryInterval):
+		}
+	}
+}
+
+// tail streams entries over a single stream, updating req.ResumeAfter with
+// each entry received
+func tail(ctx context.Context, conn grpc.ClientConnInterface, req *TailRequest, fn func(e Entry) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := conn.NewStream(ctx, &serviceDesc.Streams[1], tailMethod, grpc.CallContentSubtype(codecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		var e Entry
+		if err := stream.RecvMsg(&e); err != nil {
+			return err
+		}
+		req.ResumeAfter = e.Sequence
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,269 @@
+//go:build grpc
+// +build grpc
+
+// Package logstream implements the LogStream gRPC service of
+// proto/logstream.proto, so that processes can stream their logs to a central
+// collector and tail the entries it collects. A collector registers a Server
+// with its grpc.Server:
+//
+//	collector := logstream.NewServer(&logstream.ServerOptions{Out: file})
+//	gs := grpc.NewServer()
+//	collector.Register(gs)
+//
+// and processes use a Client as one of their golog outputs:
+//
+//	client := logstream.NewClient(conn, nil)
+//	golog.SetOutputs(client, client)
+//	defer client.Close()
+//
+// Clients number their entries and keep them until the server acknowledges
+// them, stop sending once too many are unacknowledged and, after
+// reconnecting, resend everything that wasn't acknowledged. Tail resumes after
+// the last entry it received when reconnecting.
+//
+// Messages are encoded in the protocol buffers wire format of the schema, with
+// a codec registered under the content-subtype "logstream", so that golog
+// doesn't depend on generated code. Clients in other languages must send the
+// content-type application/grpc+logstream.
+//
+// gRPC isn't a dependency of golog, so this package is only built with the
+// "grpc" build tag, e.g. go build -tags grpc, and requires google.golang.org/grpc
+// in the go.mod of the main module.
+package logstream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/getlantern/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	codecName   = "logstream"
+	serviceName = "golog.logstream.v1.LogStream"
+	pushMethod  = "/" + serviceName + "/Push"
+	tailMethod  = "/" + serviceName + "/Tail"
+
+	// clientIDKey is the metadata key under which clients send their ID
+	clientIDKey = "golog-logstream-client"
+
+	pbVarint = 0
+	pbBytes  = 2
+)
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// Entry is a golog Entry with the sequence number assigned by the process
+// that sent it
+type Entry struct {
+	Sequence uint64
+	golog.Entry
+}
+
+// Ack acknowledges all entries up to and including Sequence
+type Ack struct {
+	Sequence uint64
+}
+
+// TailRequest selects the entries streamed by Tail
+type TailRequest struct {
+	// ResumeAfter resumes a previous tail after the given sequence, if the
+	// entries are still available. 0 only streams new entries.
+	ResumeAfter uint64
+
+	// MinSeverity is the minimum severity of the streamed entries
+	MinSeverity golog.Severity
+
+	// Prefix limits the streamed entries to those whose prefix starts with it
+	Prefix string
+}
+
+// matches reports whether e is selected by the request
+func (r *TailRequest) matches(e *Entry) bool {
+	return e.Severity >= r.MinSeverity && strings.HasPrefix(e.Prefix, r.Prefix)
+}
+
+// logStreamServer is the handler type of the service
+type logStreamServer interface {
+	push(stream grpc.ServerStream) error
+	tail(stream grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*logStreamServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       func(srv interface{}, stream grpc.ServerStream) error { return srv.(logStreamServer).push(stream) },
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Tail",
+			Handler:       func(srv interface{}, stream grpc.ServerStream) error { return srv.(logStreamServer).tail(stream) },
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/logstream.proto",
+}
+
+// message is implemented by the messages of the service
+type message interface {
+	marshal() []byte
+	unmarshal(b []byte) error
+}
+
+// codec encodes messages in the protocol buffers wire format
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("logstream: unable to marshal %T", v)
+	}
+	return m.marshal(), nil
+}
+
+func (codec) Unmarshal(b []byte, v interface{}) error {
+	m, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("logstream: unable to unmarshal %T", v)
+	}
+	return m.unmarshal(b)
+}
+
+func (codec) Name() string {
+	return codecName
+}
+
+// marshal reuses the encoding of golog's ProtobufFormatter, which writes the
+// same Entry message without the sequence, prefixed with its length
+func (e *Entry) marshal() []byte {
+	formatted := (&golog.ProtobufFormatter{}).Format(e.Entry)
+	_, n := binary.Uvarint(formatted)
+	buf := &bytes.Buffer{}
+	writeVarint(buf, 1, e.Sequence)
+	buf.Write(formatted[n:])
+	return buf.Bytes()
+}
+
+func (e *Entry) unmarshal(b []byte) error {
+	err := readFields(b, func(field int, varint uint64, _ []byte) {
+		if field == 1 {
+			e.Sequence = varint
+		}
+	})
+	if err != nil {
+		return err
+	}
+	prefixed := &bytes.Buffer{}
+	writeUvarint(prefixed, uint64(len(b)))
+	prefixed.Write(b)
+	e.Entry, err = golog.NewProtobufDecoder(prefixed).Decode()
+	return err
+}
+
+func (a *Ack) marshal() []byte {
+	buf := &bytes.Buffer{}
+	writeVarint(buf, 1, a.Sequence)
+	return buf.Bytes()
+}
+
+func (a *Ack) unmarshal(b []byte) error {
+	return readFields(b, func(field int, varint uint64, _ []byte) {
+		if field == 1 {
+			a.Sequence = varint
+		}
+	})
+}
+
+func (r *TailRequest) marshal() []byte {
+	buf := &bytes.Buffer{}
+	writeVarint(buf, 1, r.ResumeAfter)
+	// int32 values are sign extended
+	writeVarint(buf, 2, uint64(int64(int32(r.MinSeverity))))
+	if r.Prefix != "" {
+		writeUvarint(buf, 3<<3|pbBytes)
+		writeUvarint(buf, uint64(len(r.Prefix)))
+		buf.WriteString(r.Prefix)
+	}
+	return buf.Bytes()
+}
+
+func (r *TailRequest) unmarshal(b []byte) error {
+	return readFields(b, func(field int, varint uint64, value []byte) {
+		switch field {
+		case 1:
+			r.ResumeAfter = varint
+		case 2:
+			r.MinSeverity = golog.Severity(int32(varint))
+		case 3:
+			r.Prefix = string(value)
+		}
+	})
+}
+
+// readFields calls onField with the value of each varint and length delimited
+// field in msg, skipping fixed size fields
+func readFields(msg []byte, onField func(field int, varint uint64, b []byte)) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return io.ErrUnexpectedEOF
+		}
+		msg = msg[n:]
+		field := int(key >> 3)
+		switch key & 0x7 {
+		case pbVarint:
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[n:]
+			onField(field, v, nil)
+		case pbBytes:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return io.ErrUnexpectedEOF
+			}
+			onField(field, 0, msg[n:n+int(size)])
+			msg = msg[n+int(size):]
+		case 1:
+			if len(msg) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[8:]
+		case 5:
+			if len(msg) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&0x7)
+		}
+	}
+	return nil
+}
+
+// writeVarint writes a varint field, omitting it if zero as proto3 does
+func writeVarint(buf *bytes.Buffer, field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	writeUvarint(buf, uint64(field<<3|pbVarint))
+	writeUvarint(buf, v)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	buf.Write(b[:n])
+}
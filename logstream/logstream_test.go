@@ -0,0 +1,181 @@
+//go:build grpc
+// +build grpc
+
+package logstream
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type recorder struct {
+	mx      sync.Mutex
+	entries []golog.Entry
+	gate    chan bool
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (r *recorder) WriteEntry(e golog.Entry) error {
+	if r.gate != nil {
+		<-r.gate
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+func (r *recorder) messages() []string {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	var messages []string
+	for _, e := range r.entries {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func serve(t *testing.T, s *Server, addr string) (*grpc.Server, string) {
+	lis, err := net.Listen("tcp", addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	gs := grpc.NewServer()
+	s.Register(gs)
+	go gs.Serve(lis)
+	return gs, lis.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *grpc.ClientConn {
+	conn, err := grpc.Dial(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: backoff.Config{BaseDelay: 10 * time.Millisecond, Multiplier: 1, MaxDelay: 50 * time.Millisecond}}))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return conn
+}
+
+func TestCodec(t *testing.T) {
+	e := &Entry{Sequence: 42, Entry: golog.Entry{
+		Time:     time.Unix(0, 1560171845123456789),
+		Severity: golog.ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "failed",
+		Stack:    []string{"  at main.run (main.go:3)"},
+		Context:  map[string]interface{}{"op": "dial"},
+	}}
+	b, err := codec{}.Marshal(e)
+	assert.NoError(t, err)
+	var decoded Entry
+	assert.NoError(t, codec{}.Unmarshal(b, &decoded))
+	assert.EqualValues(t, 42, decoded.Sequence)
+	assert.Equal(t, e.Message, decoded.Message)
+	assert.Equal(t, e.Stack, decoded.Stack)
+	assert.Equal(t, e.Context, decoded.Context)
+	assert.True(t, e.Time.Equal(decoded.Time))
+
+	req := &TailRequest{ResumeAfter: 7, MinSeverity: golog.WARN, Prefix: "my"}
+	b, err = codec{}.Marshal(req)
+	assert.NoError(t, err)
+	var decodedReq TailRequest
+	assert.NoError(t, codec{}.Unmarshal(b, &decodedReq))
+	assert.Equal(t, *req, decodedReq)
+}
+
+func TestPushResume(t *testing.T) {
+	first := &recorder{}
+	gs, addr := serve(t, NewServer(&ServerOptions{Out: first, AckInterval: 10 * time.Millisecond}), "127.0.0.1:0")
+	conn := dial(t, addr)
+	defer conn.Close()
+	c := NewClient(conn, &ClientOptions{RetryInterval: 10 * time.Millisecond, FlushTimeout: 10 * time.Second})
+
+	for _, msg := range []string{"a", "b", "c"} {
+		c.WriteEntry(golog.Entry{Message: msg})
+	}
+	assert.NoError(t, c.Flush())
+	assert.Equal(t, []string{"a", "b", "c"}, first.messages())
+
+	gs.Stop()
+	c.WriteEntry(golog.Entry{Message: "d"})
+	c.WriteEntry(golog.Entry{Message: "e"})
+
+	second := &recorder{}
+	gs, _ = serve(t, NewServer(&ServerOptions{Out: second, AckInterval: 10 * time.Millisecond}), addr)
+	defer gs.Stop()
+	assert.NoError(t, c.Close())
+	assert.Equal(t, []string{"d", "e"}, second.messages(), "should only resend unacknowledged entries")
+}
+
+func TestPushBackpressure(t *testing.T) {
+	out := &recorder{gate: make(chan bool)}
+	gs, addr := serve(t, NewServer(&ServerOptions{Out: out, AckEvery: 1}), "127.0.0.1:0")
+	defer gs.Stop()
+	conn := dial(t, addr)
+	defer conn.Close()
+	c := NewClient(conn, &ClientOptions{Window: 5, MaxBuffered: 20})
+
+	for i := 0; i < 25; i++ {
+		assert.NoError(t, c.WriteEntry(golog.Entry{Message: "entry"}))
+	}
+	assert.EqualValues(t, 5, c.Dropped())
+	for i := 0; i < 100 && c.inFlight() < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 5, c.inFlight(), "shouldn't send more than the window while the server is blocked")
+
+	close(out.gate)
+	assert.NoError(t, c.Close())
+	assert.Len(t, out.messages(), 20)
+}
+
+func TestTailResume(t *testing.T) {
+	s := NewServer(&ServerOptions{HistorySize: 4})
+	gs, addr := serve(t, s, "127.0.0.1:0")
+	defer gs.Stop()
+	conn := dial(t, addr)
+	defer conn.Close()
+
+	for i, severity := range []golog.Severity{golog.INFO, golog.ERROR, golog.ERROR, golog.DEBUG, golog.ERROR, golog.ERROR} {
+		s.WriteEntry(golog.Entry{Severity: severity, Prefix: "app.db", Message: string(rune('a' + i))})
+	}
+	s.WriteEntry(golog.Entry{Severity: golog.ERROR, Prefix: "other", Message: "g"})
+
+	errDone := errors.New("done")
+	var received []Entry
+	err := Tail(context.Background(), conn, TailRequest{ResumeAfter: 1, MinSeverity: golog.ERROR, Prefix: "app"}, func(e Entry) error {
+		received = append(received, e)
+		if len(received) == 1 {
+			s.WriteEntry(golog.Entry{Severity: golog.ERROR, Prefix: "app.db", Message: "h"})
+		}
+		if len(received) == 3 {
+			return errDone
+		}
+		return nil
+	})
+	assert.Equal(t, errDone, err)
+	if assert.Len(t, received, 3) {
+		// entries 2 and 3 have been evicted from the history
+		assert.EqualValues(t, 5, received[0].Sequence)
+		assert.Equal(t, "e", received[0].Message)
+		assert.Equal(t, "f", received[1].Message)
+		assert.EqualValues(t, 8, received[2].Sequence)
+		assert.Equal(t, "h", received[2].Message)
+	}
+}
@@ -0,0 +1,262 @@
+//go:build grpc
+// +build grpc
+
+package logstream
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getlantern/golog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ServerOptions configures a Server
+type ServerOptions struct {
+	// Out receives the entries pushed by clients. If it isn't a
+	// golog.EntryWriter, entries are formatted with the global Formatter.
+	// Entries are only acknowledged once written. Optional.
+	Out io.Writer
+
+	// HistorySize is the number of recent entries kept for resuming tails,
+	// defaults to 1000
+	HistorySize int
+
+	// AckEvery is the number of entries after which pushed entries are
+	// acknowledged, defaults to 100. Entries are also acknowledged every
+	// AckInterval.
+	AckEvery int
+
+	// AckInterval is the maximum time for which pushed entries go
+	// unacknowledged, defaults to 1 second
+	AckInterval time.Duration
+}
+
+// Server implements the LogStream service. Entries pushed by clients are
+// written to its Out and, like entries written to the Server itself as a
+// golog output, numbered and made available to tails.
+type Server struct {
+	opts ServerOptions
+
+	mx      sync.Mutex
+	history []Entry
+	next    uint64
+	updated chan struct{}
+	clients map[string]uint64
+}
+
+// NewServer creates a Server. opts may be nil.
+func NewServer(opts *ServerOptions) *Server {
+	s := &Server{
+		next:    1,
+		updated: make(chan struct{}),
+		clients: make(map[string]uint64),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.HistorySize <= 0 {
+		s.opts.HistorySize = 1000
+	}
+	if s.opts.AckEvery <= 0 {
+		s.opts.AckEvery = 100
+	}
+	if s.opts.AckInterval <= 0 {
+		s.opts.AckInterval = time.Second
+	}
+	s.history = make([]Entry, s.opts.HistorySize)
+	return s
+}
+
+// Register registers the LogStream service with gs
+func (s *Server) Register(gs *grpc.Server) {
+	gs.RegisterService(&serviceDesc, s)
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity
+func (s *Server) Write(p []byte) (int, error) {
+	return len(p), s.WriteEntry(golog.Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements golog.EntryWriter, making e available to tails
+func (s *Server) WriteEntry(e golog.Entry) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	seq := s.next
+	s.next++
+	s.history[seq%uint64(len(s.history))] = Entry{Sequence: seq, Entry: e}
+	close(s.updated)
+	s.updated = make(chan struct{})
+	return nil
+}
+
+// after returns up to max entries of the history following the given
+// sequence, and a channel that's closed once more entries are written
+func (s *Server) after(seq uint64, max int) ([]Entry, <-chan struct{}) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	start := seq + 1
+	if oldest := s.oldest(); start < oldest {
+		start = oldest
+	}
+	var entries []Entry
+	for i := start; i < s.next && len(entries) < max; i++ {
+		entries = append(entries, s.history[i%uint64(len(s.history))])
+	}
+	return entries, s.updated
+}
+
+// oldest returns the sequence of the oldest entry in the history. It must be
+// called with mx held.
+func (s *Server) oldest() uint64 {
+	if s.next <= uint64(len(s.history)) {
+		return 1
+	}
+	return s.next - uint64(len(s.history))
+}
+
+// received reports whether the entry with the given sequence was already
+// received from the client with the given ID, e.g. before the client
+// reconnected and resent its unacknowledged entries
+func (s *Server) received(clientID string, seq uint64) bool {
+	if clientID == "" {
+		return false
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return seq <= s.clients[clientID]
+}
+
+// receive records that the entry with the given sequence was received from
+// the client with the given ID
+func (s *Server) receive(clientID string, seq uint64) {
+	if clientID == "" {
+		return
+	}
+	s.mx.Lock()
+	s.clients[clientID] = seq
+	s.mx.Unlock()
+}
+
+func (s *Server) push(stream grpc.ServerStream) error {
+	var clientID string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if ids := md.Get(clientIDKey); len(ids) > 0 {
+			clientID = ids[0]
+		}
+	}
+
+	var sendMx sync.Mutex
+	var handled, acked uint64
+	sendAck := func() error {
+		sendMx.Lock()
+		defer sendMx.Unlock()
+		if handled <= acked {
+			return nil
+		}
+		acked = handled
+		return stream.SendMsg(&Ack{Sequence: acked})
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(s.opts.AckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sendAck()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	// acks must not be sent after the handler returns
+	defer wg.Wait()
+	defer close(stop)
+
+	for {
+		var e Entry
+		err := stream.RecvMsg(&e)
+		if err == io.EOF {
+			return sendAck()
+		}
+		if err != nil {
+			return err
+		}
+		if !s.received(clientID, e.Sequence) {
+			if err := s.write(e.Entry); err != nil {
+				return status.Errorf(codes.Unavailable, "unable to write entry: %v", err)
+			}
+			s.receive(clientID, e.Sequence)
+			s.WriteEntry(e.Entry)
+		}
+		sendMx.Lock()
+		handled = e.Sequence
+		due := handled-acked >= uint64(s.opts.AckEvery)
+		sendMx.Unlock()
+		if due {
+			if err := sendAck(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// write writes e to Out, if set
+func (s *Server) write(e golog.Entry) error {
+	switch out := s.opts.Out.(type) {
+	case nil:
+		return nil
+	case golog.EntryWriter:
+		return out.WriteEntry(e)
+	default:
+		_, err := out.Write(golog.GetFormatter().Format(e))
+		return err
+	}
+}
+
+func (s *Server) tail(stream grpc.ServerStream) error {
+	var req TailRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	s.mx.Lock()
+	cursor := req.ResumeAfter
+	if cursor == 0 || cursor >= s.next {
+		// nothing to resume, e.g. after the server restarted
+		cursor = s.next - 1
+	}
+	s.mx.Unlock()
+
+	for {
+		entries, updated := s.after(cursor, 100)
+		for i := range entries {
+			cursor = entries[i].Sequence
+			if !req.matches(&entries[i]) {
+				continue
+			}
+			// blocks while the client doesn't keep up, per gRPC flow control
+			if err := stream.SendMsg(&entries[i]); err != nil {
+				return err
+			}
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		select {
+		case <-updated:
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LokiOptions configures a LokiOutput
+type LokiOptions struct {
+	// HTTPOptions configures batching, retries and compression. Its Formatter
+	// formats the log line of each entry and defaults to a LogfmtFormatter.
+	HTTPOptions
+
+	// Labels are static labels added to every stream, e.g. {"job": "myapp"}
+	Labels map[string]string
+
+	// Username and Password are used for HTTP basic authentication if
+	// Username is not empty.
+	Username string
+	Password string
+
+	// TenantID is sent as the X-Scope-OrgID header when using a multi-tenant
+	// Loki.
+	TenantID string
+}
+
+// LokiOutput is an output that pushes entries to Grafana Loki using its push
+// API. Each entry's prefix and severity are sent as the "prefix" and "level"
+// stream labels and the rest of the entry as the log line. Entries are pushed
+// in batches on a background goroutine.
+type LokiOutput struct {
+	*batcher
+	endpoint string
+	opts     LokiOptions
+}
+
+// NewLokiOutput creates a LokiOutput that pushes to the Loki at the given base
+// URL, for example "http://localhost:3100". opts may be nil.
+func NewLokiOutput(url string, opts *LokiOptions) *LokiOutput {
+	o := &LokiOutput{endpoint: strings.TrimSuffix(url, "/") + "/loki/api/v1/push"}
+	if opts != nil {
+		o.opts = *opts
+	}
+	o.opts.applyDefaults()
+	if o.opts.Formatter == nil {
+		o.opts.Formatter = &LogfmtFormatter{}
+	}
+	headers := make(map[string]string, len(o.opts.Headers)+2)
+	for key, value := range o.opts.Headers {
+		headers[key] = value
+	}
+	if o.opts.Username != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(o.opts.Username+":"+o.opts.Password))
+	}
+	if o.opts.TenantID != "" {
+		headers["X-Scope-OrgID"] = o.opts.TenantID
+	}
+	o.opts.Headers = headers
+	o.batcher = newBatcher(o.opts.QueueSize, o.opts.BatchSize, o.opts.FlushInterval, o.push)
+	return o
+}
+
+// Write implements io.Writer, pushing each write as the log line of an entry
+// without prefix or severity.
+func (o *LokiOutput) Write(p []byte) (int, error) {
+	return len(p), o.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (o *LokiOutput) WriteEntry(e Entry) error {
+	return o.add(e)
+}
+
+func (o *LokiOutput) push(batch []Entry) {
+	if err := postWithRetry(o.endpoint, "application/json", o.encode(batch), &o.opts.HTTPOptions); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+// encode encodes the given batch as a push request, with one stream per
+// distinct combination of prefix and severity.
+func (o *LokiOutput) encode(batch []Entry) []byte {
+	type streamKey struct {
+		prefix   string
+		severity Severity
+	}
+	var keys []streamKey
+	streams := make(map[streamKey][]Entry)
+	for _, e := range batch {
+		key := streamKey{e.Prefix, e.Severity}
+		if _, found := streams[key]; !found {
+			keys = append(keys, key)
+		}
+		streams[key] = append(streams[key], e)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"streams":[`)
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		labels := make(map[string]interface{}, len(o.opts.Labels)+2)
+		for name, value := range o.opts.Labels {
+			labels[name] = value
+		}
+		if key.prefix != "" {
+			labels["prefix"] = key.prefix
+		}
+		if key.severity != 0 {
+			labels["level"] = strings.ToLower(key.severity.String())
+		}
+		buf.WriteString(`{"stream":{`)
+		for j, name := range sortedKeys(labels) {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, name)
+			buf.WriteByte(':')
+			writeJSONString(buf, labels[name].(string))
+		}
+		buf.WriteString(`},"values":[`)
+		for j, e := range streams[key] {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`["`)
+			buf.WriteString(strconv.FormatInt(e.Time.UnixNano(), 10))
+			buf.WriteString(`",`)
+			writeJSONString(buf, strings.TrimSuffix(string(o.opts.Formatter.Format(e)), "\n"))
+			buf.WriteByte(']')
+		}
+		buf.WriteString(`]}`)
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes()
+}
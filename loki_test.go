@@ -0,0 +1,65 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLokiOutput(t *testing.T) {
+	requests := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/loki/api/v1/push", req.URL.Path)
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+		assert.Equal(t, "tenant", req.Header.Get("X-Scope-OrgID"))
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		requests <- body
+		resp.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	o := NewLokiOutput(server.URL+"/", &LokiOptions{
+		HTTPOptions: HTTPOptions{Formatter: &customFormatter{}},
+		Labels:      map[string]string{"job": "myapp"},
+		Username:    "user",
+		Password:    "pass",
+		TenantID:    "tenant",
+	})
+	defer o.Close()
+
+	o.WriteEntry(Entry{Time: time.Unix(0, 1), Severity: ERROR, Prefix: "myprefix", Message: "a"})
+	o.WriteEntry(Entry{Time: time.Unix(0, 2), Severity: DEBUG, Prefix: "myprefix", Message: "b"})
+	o.WriteEntry(Entry{Time: time.Unix(0, 3), Severity: ERROR, Prefix: "myprefix", Message: "c"})
+	o.Flush()
+
+	select {
+	case body := <-requests:
+		assert.Equal(t, map[string]interface{}{
+			"streams": []interface{}{
+				map[string]interface{}{
+					"stream": map[string]interface{}{"job": "myapp", "prefix": "myprefix", "level": "error"},
+					"values": []interface{}{
+						[]interface{}{"1", "ERROR|myprefix||0|a|0"},
+						[]interface{}{"3", "ERROR|myprefix||0|c|0"},
+					},
+				},
+				map[string]interface{}{
+					"stream": map[string]interface{}{"job": "myapp", "prefix": "myprefix", "level": "debug"},
+					"values": []interface{}{
+						[]interface{}{"2", "DEBUG|myprefix||0|b|0"},
+					},
+				},
+			},
+		}, body)
+	default:
+		t.Fatal("nothing pushed")
+	}
+}
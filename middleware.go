@@ -0,0 +1,124 @@
+package golog
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPMiddleware returns middleware that logs every request handled by the
+// wrapped handler with the fields "method", "path", "status", "elapsed",
+// "bytes" and "remote_addr", e.g.:
+//
+//	http.ListenAndServe(":8080", golog.HTTPMiddleware(golog.LoggerFor("myapp.http"))(mux))
+//
+// Requests are logged at INFO, at WARN if the status is 4xx and at ERROR,
+// which is also reported to the ErrorReporters, if it's 5xx. The request's
+// context carries l with the request's correlation ID (see WithCorrelationID),
+// which is taken from the CorrelationIDHeader of the request or generated and
+// returned in the same header of the response, so that handlers can log with
+// FromContext(req.Context()).
+//
+// Panics in the handler are recovered and logged at ERROR along with the stack
+// of the panic, and answered with a 500 if the handler hadn't written a
+// response yet. http.ErrAbortHandler is passed on to net/http.
+func HTTPMiddleware(l Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			ctx, id := WithCorrelationID(NewContext(req.Context(), l), req.Header.Get(CorrelationIDHeader))
+			resp.Header().Set(CorrelationIDHeader, id)
+			rec := &statusRecorder{ResponseWriter: resp}
+			rl := FromContext(ctx)
+			defer func() {
+				if p := recover(); p != nil {
+					if p == http.ErrAbortHandler {
+						panic(p)
+					}
+					rl.WithStack().Error(fmt.Errorf("panic serving %v %v: %v", req.Method, req.URL.Path, p))
+					if rec.status == 0 {
+						http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					}
+				}
+				logRequest(rl, req, rec, time.Since(start))
+			}()
+			next.ServeHTTP(rec, req.WithContext(ctx))
+		})
+	}
+}
+
+// logRequest logs a request handled by HTTPMiddleware
+func logRequest(l Logger, req *http.Request, rec *statusRecorder, elapsed time.Duration) {
+	status := rec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	l = l.WithoutCaller().WithFields(map[string]interface{}{
+		"method":      req.Method,
+		"path":        req.URL.Path,
+		"status":      status,
+		"elapsed":     Duration("elapsed", elapsed),
+		"bytes":       rec.bytes,
+		"remote_addr": req.RemoteAddr,
+	})
+	message := fmt.Sprintf("%v %v %d", req.Method, req.URL.Path, status)
+	switch {
+	case status >= 500:
+		l.Error(message)
+	case status >= 400:
+		l.Warn(message)
+	default:
+		l.Info(message)
+	}
+}
+
+// statusRecorder is an http.ResponseWriter that records the status and the
+// number of bytes of the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the underlying ResponseWriter does, so
+// that websockets like the one of LiveTail can be served behind the middleware
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T doesn't support hijacking", r.ResponseWriter)
+	}
+	if r.status == 0 {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return hj.Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter, for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(resp http.ResponseWriter, req *http.Request) {
+		FromContext(req.Context()).Debug("handling")
+		resp.Write([]byte("hello"))
+	})
+	mux.HandleFunc("/panic", func(resp http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})
+	h := HTTPMiddleware(LoggerFor("myprefix"))(mux)
+
+	req := httptest.NewRequest("GET", "/ok", nil)
+	req.Header.Set(CorrelationIDHeader, "abc")
+	resp := httptest.NewRecorder()
+	h.ServeHTTP(resp, req)
+	assert.Equal(t, "abc", resp.Header().Get(CorrelationIDHeader))
+	assert.Regexp(t, `^DEBUG myprefix: middleware_test.go:\d+ handling \[correlation_id=abc\]
+INFO myprefix: GET /ok 200 \[bytes=5 correlation_id=abc elapsed=\S+ method=GET path=/ok remote_addr=192.0.2.1:1234 status=200\]
+$`, debugOut.String())
+
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest("POST", "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.NotEmpty(t, resp.Header().Get(CorrelationIDHeader), "correlation ID should be generated")
+	assert.Regexp(t, `^WARN myprefix: POST /missing 404 \[bytes=19 correlation_id=[0-9a-f]{32} elapsed=\S+ method=POST path=/missing remote_addr=192.0.2.1:1234 status=404\]\n$`, errorOut.String())
+
+	errorOut.Reset()
+	resp = httptest.NewRecorder()
+	h.ServeHTTP(resp, httptest.NewRequest("GET", "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Regexp(t, `^ERROR myprefix: middleware.go:\d+ panic serving GET /panic: boom \[correlation_id=[0-9a-f]{32}\]\n`, errorOut.String())
+	assert.Regexp(t, `ERROR myprefix: middleware.go:\d+   at github.com/getlantern/golog.TestHTTPMiddleware.func2 \(middleware_test.go:\d+\)\n`, errorOut.String())
+	assert.Regexp(t, `\nERROR myprefix: GET /panic 500 \[bytes=22 `, errorOut.String())
+
+	assert.Panics(t, func() {
+		HTTPMiddleware(LoggerFor("myprefix"))(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			panic(http.ErrAbortHandler)
+		})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	})
+}
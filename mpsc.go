@@ -0,0 +1,79 @@
+package golog
+
+import (
+	"sync/atomic"
+)
+
+// entryQueue is a bounded lock-free queue of entries for multiple producers
+// and a single consumer, based on Dmitry Vyukov's bounded MPMC queue. Each slot
+// has a sequence number that tells producers and the consumer whether it's
+// free to write or ready to read, so that neither has to take a lock.
+type entryQueue struct {
+	_     [8]uint64 // padding against false sharing
+	head  uint64    // next position to write, shared by producers
+	_     [7]uint64
+	tail  uint64 // next position to read, only used by the consumer
+	_     [7]uint64
+	mask  uint64
+	slots []queueSlot
+}
+
+type queueSlot struct {
+	seq   uint64
+	entry Entry
+}
+
+// newEntryQueue creates an entryQueue with room for at least size entries,
+// rounded up to a power of two.
+func newEntryQueue(size int) *entryQueue {
+	capacity := uint64(1)
+	for capacity < uint64(size) {
+		capacity <<= 1
+	}
+	q := &entryQueue{
+		mask:  capacity - 1,
+		slots: make([]queueSlot, capacity),
+	}
+	for i := range q.slots {
+		q.slots[i].seq = uint64(i)
+	}
+	return q
+}
+
+// push adds e to the queue, returning false if the queue is full
+func (q *entryQueue) push(e Entry) bool {
+	pos := atomic.LoadUint64(&q.head)
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := atomic.LoadUint64(&slot.seq)
+		switch diff := int64(seq) - int64(pos); {
+		case diff == 0:
+			if atomic.CompareAndSwapUint64(&q.head, pos, pos+1) {
+				slot.entry = e
+				atomic.StoreUint64(&slot.seq, pos+1)
+				return true
+			}
+			pos = atomic.LoadUint64(&q.head)
+		case diff < 0:
+			// the slot still holds an unread entry from the previous lap
+			return false
+		default:
+			// another producer claimed pos
+			pos = atomic.LoadUint64(&q.head)
+		}
+	}
+}
+
+// pop removes the oldest entry from the queue, returning false if the queue
+// is empty. It must only be called by the consumer.
+func (q *entryQueue) pop() (Entry, bool) {
+	slot := &q.slots[q.tail&q.mask]
+	if int64(atomic.LoadUint64(&slot.seq))-int64(q.tail+1) < 0 {
+		return Entry{}, false
+	}
+	e := slot.entry
+	slot.entry = Entry{}
+	atomic.StoreUint64(&slot.seq, q.tail+q.mask+1)
+	q.tail++
+	return e, true
+}
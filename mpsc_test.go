@@ -0,0 +1,63 @@
+package golog
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryQueue(t *testing.T) {
+	q := newEntryQueue(3)
+	assert.Len(t, q.slots, 4, "size should be rounded up to a power of two")
+	_, ok := q.pop()
+	assert.False(t, ok)
+	for i := 0; i < 4; i++ {
+		assert.True(t, q.push(Entry{Line: i}))
+	}
+	assert.False(t, q.push(Entry{Line: 4}), "full queue should reject entries")
+	for lap := 0; lap < 3; lap++ {
+		for i := 0; i < 4; i++ {
+			e, ok := q.pop()
+			assert.True(t, ok)
+			assert.Equal(t, lap*4+i, e.Line)
+			assert.True(t, q.push(Entry{Line: (lap+1)*4 + i}))
+		}
+	}
+}
+
+func TestEntryQueueConcurrent(t *testing.T) {
+	q := newEntryQueue(64)
+	const producers, perProducer = 8, 10000
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < perProducer; {
+				if q.push(Entry{Prefix: strconv.Itoa(p), Line: i}) {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	next := make(map[string]int)
+	for received := 0; received < producers*perProducer; {
+		e, ok := q.pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		if !assert.Equal(t, next[e.Prefix], e.Line, "entries of each producer should arrive in order") {
+			return
+		}
+		next[e.Prefix]++
+		received++
+	}
+	wg.Wait()
+}
@@ -0,0 +1,351 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// MsgpackFormatter is a Formatter that writes entries in a compact binary form
+// using MessagePack. Each entry is encoded as an array of:
+//
+//	[time (unix nanos), severity, prefix, file, line, message, [stack lines], {context}]
+//
+// Context values that are strings, booleans, numbers or nil are encoded as
+// such, all other values are encoded as strings using fmt.Sprint. Use a
+// MsgpackDecoder to read the entries back.
+type MsgpackFormatter struct{}
+
+func (f *MsgpackFormatter) Format(e Entry) []byte {
+	buf := &bytes.Buffer{}
+	writeMsgpackArrayHeader(buf, 8)
+	writeMsgpackInt(buf, e.Time.UnixNano())
+	writeMsgpackInt(buf, int64(e.Severity))
+	writeMsgpackString(buf, e.Prefix)
+	writeMsgpackString(buf, e.File)
+	writeMsgpackInt(buf, int64(e.Line))
+	writeMsgpackString(buf, e.Message)
+	writeMsgpackArrayHeader(buf, len(e.Stack))
+	for _, line := range e.Stack {
+		writeMsgpackString(buf, line)
+	}
+	writeMsgpackMap(buf, e.Context)
+	return buf.Bytes()
+}
+
+// MsgpackDecoder reads entries written by a MsgpackFormatter
+type MsgpackDecoder struct {
+	r *bufio.Reader
+}
+
+// NewMsgpackDecoder creates a MsgpackDecoder that reads from r
+func NewMsgpackDecoder(r io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode decodes the next Entry. It returns io.EOF once there are no more
+// entries. Integer context values are decoded as int64 (or uint64 if too large
+// for an int64) and floating point values as float64.
+func (d *MsgpackDecoder) Decode() (Entry, error) {
+	var e Entry
+	if _, err := d.r.Peek(1); err != nil {
+		return e, err
+	}
+	v, err := d.decodeValue()
+	if err != nil {
+		return e, err
+	}
+	fields, ok := v.([]interface{})
+	if !ok || len(fields) != 8 {
+		return e, fmt.Errorf("expected array of 8 entry fields, got %v", v)
+	}
+	ts, ok1 := fields[0].(int64)
+	severity, ok2 := fields[1].(int64)
+	e.Prefix, _ = fields[2].(string)
+	e.File, _ = fields[3].(string)
+	line, ok3 := fields[4].(int64)
+	e.Message, _ = fields[5].(string)
+	stack, ok4 := fields[6].([]interface{})
+	ctx, ok5 := fields[7].(map[string]interface{})
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 {
+		return e, fmt.Errorf("unexpected entry field types in %v", fields)
+	}
+	e.Time = time.Unix(0, ts)
+	e.Severity = Severity(severity)
+	e.Line = int(line)
+	for _, s := range stack {
+		str, _ := s.(string)
+		e.Stack = append(e.Stack, str)
+	}
+	if len(ctx) > 0 {
+		e.Context = ctx
+	}
+	return e, nil
+}
+
+func (d *MsgpackDecoder) decodeValue() (interface{}, error) {
+	b, err := d.r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return d.decodeMap(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return d.decodeArray(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		return d.decodeString(int(b & 0x1f))
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xca:
+		n, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.readUint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.readUint(1 << (b - 0xcc))
+		if n > math.MaxInt64 {
+			return n, err
+		}
+		return int64(n), err
+	case 0xd0:
+		n, err := d.readUint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := d.readUint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := d.readUint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := d.readUint(8)
+		return int64(n), err
+	case 0xd7:
+		// fixext 8, only used for Fluentd EventTimes (type 0)
+		typ, err := d.readUint(1)
+		if err != nil {
+			return nil, err
+		}
+		n, err := d.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		if typ != 0 {
+			return nil, fmt.Errorf("unsupported msgpack extension type %d", typ)
+		}
+		return time.Unix(int64(n>>32), int64(uint32(n))), nil
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readUint(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeString(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.readUint(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(int(n))
+	case 0xde, 0xdf:
+		n, err := d.readUint(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(int(n))
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%x", b)
+}
+
+func (d *MsgpackDecoder) readUint(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-size:]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func (d *MsgpackDecoder) decodeString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return string(b), nil
+}
+
+func (d *MsgpackDecoder) decodeArray(size int) ([]interface{}, error) {
+	result := make([]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		v, err := d.decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, nil
+}
+
+func (d *MsgpackDecoder) decodeMap(size int) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, size)
+	for i := 0; i < size; i++ {
+		k, err := d.decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		result[fmt.Sprint(k)] = v
+	}
+	return result, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func writeMsgpackArrayHeader(buf *bytes.Buffer, size int) {
+	switch {
+	case size < 16:
+		buf.WriteByte(0x90 | byte(size))
+	case size <= math.MaxUint16:
+		buf.WriteByte(0xdc)
+		binary.Write(buf, binary.BigEndian, uint16(size))
+	default:
+		buf.WriteByte(0xdd)
+		binary.Write(buf, binary.BigEndian, uint32(size))
+	}
+}
+
+func writeMsgpackMapHeader(buf *bytes.Buffer, size int) {
+	switch {
+	case size < 16:
+		buf.WriteByte(0x80 | byte(size))
+	case size <= math.MaxUint16:
+		buf.WriteByte(0xde)
+		binary.Write(buf, binary.BigEndian, uint16(size))
+	default:
+		buf.WriteByte(0xdf)
+		binary.Write(buf, binary.BigEndian, uint32(size))
+	}
+}
+
+// writeMsgpackMap writes the given values as a map with sorted keys
+func writeMsgpackMap(buf *bytes.Buffer, values map[string]interface{}) {
+	writeMsgpackMapHeader(buf, len(values))
+	for _, key := range sortedKeys(values) {
+		writeMsgpackString(buf, key)
+		writeMsgpackValue(buf, values[key])
+	}
+}
+
+func writeMsgpackString(buf *bytes.Buffer, s string) {
+	size := len(s)
+	switch {
+	case size < 32:
+		buf.WriteByte(0xa0 | byte(size))
+	case size <= math.MaxUint8:
+		buf.WriteByte(0xd9)
+		buf.WriteByte(byte(size))
+	case size <= math.MaxUint16:
+		buf.WriteByte(0xda)
+		binary.Write(buf, binary.BigEndian, uint16(size))
+	default:
+		buf.WriteByte(0xdb)
+		binary.Write(buf, binary.BigEndian, uint32(size))
+	}
+	buf.WriteString(s)
+}
+
+func writeMsgpackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+func writeMsgpackValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case string:
+		writeMsgpackString(buf, v)
+	case Field:
+		writeMsgpackValue(buf, v.Value())
+	case int:
+		writeMsgpackInt(buf, int64(v))
+	case int8:
+		writeMsgpackInt(buf, int64(v))
+	case int16:
+		writeMsgpackInt(buf, int64(v))
+	case int32:
+		writeMsgpackInt(buf, int64(v))
+	case int64:
+		writeMsgpackInt(buf, v)
+	case uint8:
+		writeMsgpackInt(buf, int64(v))
+	case uint16:
+		writeMsgpackInt(buf, int64(v))
+	case uint32:
+		writeMsgpackInt(buf, int64(v))
+	case uint:
+		writeMsgpackUint(buf, uint64(v))
+	case uint64:
+		writeMsgpackUint(buf, v)
+	case float32:
+		buf.WriteByte(0xca)
+		binary.Write(buf, binary.BigEndian, math.Float32bits(v))
+	case float64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v))
+	default:
+		writeMsgpackString(buf, fmt.Sprint(v))
+	}
+}
+
+func writeMsgpackUint(buf *bytes.Buffer, u uint64) {
+	if u <= math.MaxInt64 {
+		writeMsgpackInt(buf, int64(u))
+		return
+	}
+	buf.WriteByte(0xcf)
+	binary.Write(buf, binary.BigEndian, u)
+}
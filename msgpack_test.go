@@ -0,0 +1,92 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{
+			Time:     time.Unix(0, 1560171845123456789),
+			Severity: ERROR,
+			Prefix:   "myprefix",
+			File:     "file.go",
+			Line:     12,
+			Message:  strings.Repeat("long message ", 30),
+			Stack:    []string{"  at a", "  at b"},
+			Context: map[string]interface{}{
+				"string":   "a",
+				"bool":     true,
+				"nil":      nil,
+				"small":    5,
+				"negative": -100,
+				"int32":    int32(math.MinInt32),
+				"int64":    int64(math.MaxInt64),
+				"uint64":   uint64(math.MaxUint64),
+				"float":    1.5,
+				"float32":  float32(2.5),
+				"other":    time.Second,
+			},
+		},
+		{
+			Time:     time.Unix(0, 0),
+			Severity: DEBUG,
+			Message:  "Hello world",
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	f := &MsgpackFormatter{}
+	for _, e := range entries {
+		buf.Write(f.Format(e))
+	}
+
+	d := NewMsgpackDecoder(buf)
+	first, err := d.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, entries[0].Time.UnixNano(), first.Time.UnixNano())
+		assert.Equal(t, entries[0].Severity, first.Severity)
+		assert.Equal(t, entries[0].Prefix, first.Prefix)
+		assert.Equal(t, entries[0].File, first.File)
+		assert.Equal(t, entries[0].Line, first.Line)
+		assert.Equal(t, entries[0].Message, first.Message)
+		assert.Equal(t, entries[0].Stack, first.Stack)
+		assert.Equal(t, map[string]interface{}{
+			"string":   "a",
+			"bool":     true,
+			"nil":      nil,
+			"small":    int64(5),
+			"negative": int64(-100),
+			"int32":    int64(math.MinInt32),
+			"int64":    int64(math.MaxInt64),
+			"uint64":   uint64(math.MaxUint64),
+			"float":    1.5,
+			"float32":  2.5,
+			"other":    "1s",
+		}, first.Context)
+	}
+
+	second, err := d.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Hello world", second.Message)
+		assert.Equal(t, Severity(DEBUG), second.Severity)
+		assert.Nil(t, second.Stack)
+		assert.Nil(t, second.Context)
+	}
+
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestMsgpackTruncated(t *testing.T) {
+	b := (&MsgpackFormatter{}).Format(Entry{Message: "Hello world"})
+	_, err := NewMsgpackDecoder(bytes.NewReader(b[:len(b)-3])).Decode()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -0,0 +1,175 @@
+package golog
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sink is one of the destinations of a MultiOutput
+type Sink struct {
+	// Out is where entries are written
+	Out io.Writer
+
+	// Formatter formats entries written to Out, defaults to the Formatter of
+	// the logger that logged each entry. If Formatter is nil and Out is an
+	// EntryWriter, entries are passed to Out unformatted.
+	Formatter Formatter
+
+	// MinSeverity is the minimum severity of entries written to Out, for
+	// example ERROR to only write errors. Defaults to writing all entries.
+	MinSeverity Severity
+}
+
+// Multi is an output that tees every entry to multiple sinks, each with its
+// own Formatter and severity threshold.
+type Multi struct {
+	sinks    []Sink
+	parallel bool
+	sinkMxs  []sync.Mutex
+}
+
+// MultiOutput creates a Multi that writes to the given sinks. Use it for both
+// outputs, for example:
+//
+//	out := golog.MultiOutput(
+//		golog.Sink{Out: os.Stderr, Formatter: &golog.DevFormatter{}},
+//		golog.Sink{Out: file, Formatter: &golog.JSONFormatter{}},
+//		golog.Sink{Out: httpOut, MinSeverity: golog.ERROR},
+//	)
+//	golog.SetOutputs(out, out)
+func MultiOutput(sinks ...Sink) *Multi {
+	return &Multi{sinks: append([]Sink(nil), sinks...)}
+}
+
+// ParallelMultiOutput is like MultiOutput, but writes each entry to the sinks
+// concurrently, so that a slow sink like a remote collector doesn't delay the
+// writes to the others. Logging still waits for all sinks to be written to.
+// Writes to each sink are serialized, as are Flush and Reload, so sinks don't
+// have to be safe for concurrent use. Combine it with AsyncOutput to take slow sinks off the
+// logging goroutines altogether.
+func ParallelMultiOutput(sinks ...Sink) *Multi {
+	m := MultiOutput(sinks...)
+	m.parallel = true
+	m.sinkMxs = make([]sync.Mutex, len(sinks))
+	return m
+}
+
+// SetSinks sets both outputs to a Multi with the given sinks, so that every
+// entry is offered to every sink regardless of the split between error and
+// debug outputs, and each sink only writes entries at or above its own
+// MinSeverity, for example:
+//
+//	golog.SetSinks(
+//		golog.Sink{Out: os.Stderr, MinSeverity: golog.WARN},
+//		golog.Sink{Out: file, MinSeverity: golog.DEBUG},
+//		golog.Sink{Out: httpOut, MinSeverity: golog.ERROR},
+//	)
+//
+// Returns a function that resets outputs to their values prior to calling
+// SetSinks.
+func SetSinks(sinks ...Sink) (reset func()) {
+	m := MultiOutput(sinks...)
+	return SetOutputs(m, m)
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity to all sinks without a severity threshold.
+func (m *Multi) Write(p []byte) (int, error) {
+	return len(p), m.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter. All sinks are written to, even if some
+// fail, and the first error encountered is returned.
+func (m *Multi) WriteEntry(e Entry) error {
+	if m.parallel {
+		return m.writeParallel(e)
+	}
+	var firstErr error
+	for _, sink := range m.sinks {
+		if e.Severity < sink.MinSeverity {
+			continue
+		}
+		if err := sink.write(e); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// writeParallel writes e to the sinks concurrently, writing to the first
+// sink on the calling goroutine
+func (m *Multi) writeParallel(e Entry) error {
+	errs := make([]error, len(m.sinks))
+	var wg sync.WaitGroup
+	for i := len(m.sinks) - 1; i >= 0; i-- {
+		if e.Severity < m.sinks[i].MinSeverity {
+			continue
+		}
+		if i == 0 {
+			errs[0] = m.writeSink(0, e)
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = m.writeSink(i, e)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSink writes e to the i-th sink, serialized with other writes to it
+func (m *Multi) writeSink(i int, e Entry) error {
+	m.sinkMxs[i].Lock()
+	defer m.sinkMxs[i].Unlock()
+	return m.sinks[i].write(e)
+}
+
+// eachSink calls fn with the output of each sink, serialized with the writes
+// to it if the sinks are written to in parallel, and returns the first error.
+// All sinks are visited, even if some fail.
+func (m *Multi) eachSink(fn func(out io.Writer) error) error {
+	var firstErr error
+	for i, sink := range m.sinks {
+		if m.parallel {
+			m.sinkMxs[i].Lock()
+		}
+		err := fn(sink.Out)
+		if m.parallel {
+			m.sinkMxs[i].Unlock()
+		}
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (sink *Sink) write(e Entry) error {
+	f := sink.Formatter
+	if f == nil {
+		if ew, ok := sink.Out.(EntryWriter); ok {
+			return ew.WriteEntry(e)
+		}
+		f = e.formatter
+		if f == nil {
+			f = GetFormatter()
+		}
+	}
+	var b []byte
+	if cf, ok := f.(colorFormatter); ok {
+		b = cf.format(e, useColor(sink.Out))
+	} else {
+		b = f.Format(e)
+	}
+	_, err := sink.Out.Write(b)
+	return err
+}
@@ -0,0 +1,156 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestMultiOutput(t *testing.T) {
+	text := &bytes.Buffer{}
+	json := &bytes.Buffer{}
+	errorsOnly := &bytes.Buffer{}
+	recorder := &entryRecorder{}
+	m := MultiOutput(
+		Sink{Out: text},
+		Sink{Out: &failingWriter{}},
+		Sink{Out: json, Formatter: &customFormatter{}},
+		Sink{Out: errorsOnly, Formatter: &customFormatter{}, MinSeverity: ERROR},
+		Sink{Out: recorder},
+	)
+	SetOutputs(m, m)
+	defer ResetOutputs()
+
+	l := LoggerFor("myprefix")
+	l.Debug("Hello")
+	l.Error("world")
+
+	assert.Regexp(t, `^DEBUG myprefix: multi_test.go:\d+ Hello\nERROR myprefix: multi_test.go:\d+ world\n$`, text.String())
+	assert.Regexp(t, `^DEBUG\|myprefix\|multi_test.go\|\d+\|Hello\|0\nERROR\|myprefix\|multi_test.go\|\d+\|world\|0\n$`, json.String())
+	assert.Regexp(t, `^ERROR\|myprefix\|multi_test.go\|\d+\|world\|0\n$`, errorsOnly.String())
+	assert.Len(t, recorder.entries, 2)
+
+	_, err := m.Write([]byte("plain\n"))
+	assert.EqualError(t, err, "failed")
+	assert.True(t, strings.HasSuffix(json.String(), "\nUNKNOWN|||0|plain|0\n"), "other sinks should still be written to")
+}
+
+func TestSinkLoggerFormatter(t *testing.T) {
+	multiOut := &bytes.Buffer{}
+	asyncOut := &bytes.Buffer{}
+	async := AsyncOutput(asyncOut, nil)
+	m := MultiOutput(Sink{Out: multiOut}, Sink{Out: async})
+	SetOutputs(m, m)
+	defer ResetOutputs()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&customFormatter{})
+	l.Debug("Hello")
+	LoggerFor("otherprefix").Debug("world")
+	async.Flush()
+
+	expected := `^DEBUG\|myprefix\|multi_test.go\|\d+\|Hello\|0\nDEBUG otherprefix: multi_test.go:\d+ world\n$`
+	assert.Regexp(t, expected, multiOut.String(), "sinks without a Formatter should use the logger's")
+	assert.Regexp(t, expected, asyncOut.String(), "outputs wrapped in a Sink should use the logger's Formatter")
+	assert.NoError(t, async.Close())
+}
+
+func TestSetSinks(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	file := &bytes.Buffer{}
+	remote := &bytes.Buffer{}
+	reset := SetSinks(
+		Sink{Out: stderr, Formatter: &customFormatter{}, MinSeverity: WARN},
+		Sink{Out: file, Formatter: &customFormatter{}, MinSeverity: DEBUG},
+		Sink{Out: remote, Formatter: &customFormatter{}, MinSeverity: ERROR},
+	)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.Trace("trace")
+	l.Debug("debug")
+	l.Info("info")
+	l.Warn("warn")
+	l.Error("error")
+
+	messages := func(buf *bytes.Buffer) []string {
+		var result []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			result = append(result, strings.Split(line, "|")[4])
+		}
+		return result
+	}
+	assert.Equal(t, []string{"warn", "error"}, messages(stderr))
+	assert.Equal(t, []string{"debug", "info", "warn", "error"}, messages(file))
+	assert.Equal(t, []string{"error"}, messages(remote))
+}
+
+type slowWriter struct {
+	bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.Buffer.Write(p)
+}
+
+func TestParallelMultiOutput(t *testing.T) {
+	slow1 := &slowWriter{delay: 100 * time.Millisecond}
+	slow2 := &slowWriter{delay: 100 * time.Millisecond}
+	errorsOnly := &slowWriter{delay: time.Second}
+	local := &bytes.Buffer{}
+	m := ParallelMultiOutput(
+		Sink{Out: local, Formatter: &customFormatter{}},
+		Sink{Out: &failingWriter{}},
+		Sink{Out: slow1, Formatter: &customFormatter{}},
+		Sink{Out: slow2, Formatter: &customFormatter{}},
+		Sink{Out: errorsOnly, MinSeverity: ERROR},
+	)
+
+	start := time.Now()
+	assert.EqualError(t, m.WriteEntry(Entry{Severity: DEBUG, Message: "Hello"}), "failed")
+	assert.True(t, time.Since(start) < 190*time.Millisecond, "slow sinks should be written to concurrently")
+	for _, out := range []*bytes.Buffer{local, &slow1.Buffer, &slow2.Buffer} {
+		assert.Equal(t, "DEBUG|||0|Hello|0\n", out.String())
+	}
+	assert.Empty(t, errorsOnly.String())
+}
+
+func TestParallelMultiOutputFlush(t *testing.T) {
+	// Run with -race: bufio.Writer isn't safe for concurrent use, so flushing
+	// must be serialized with the writes to each sink
+	first := bufio.NewWriter(ioutil.Discard)
+	second := bufio.NewWriter(ioutil.Discard)
+	m := ParallelMultiOutput(Sink{Out: first}, Sink{Out: second})
+	reset := SetOutputs(m, m)
+	defer reset()
+
+	l := LoggerFor("parallelflush")
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			l.Debug("Hello")
+		}
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, Flush())
+	}
+	<-done
+	assert.NoError(t, Flush())
+	assert.Zero(t, first.Buffered())
+	assert.Zero(t, second.Buffered())
+}
@@ -0,0 +1,282 @@
+package golog
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSOptions configures a NATSOutput
+type NATSOptions struct {
+	// Subject is the subject of entries without a prefix and the prefix of the
+	// subject of all other entries, which are published to Subject.prefix.
+	// Defaults to "logs".
+	Subject string
+
+	// JetStream waits for a JetStream acknowledgement for each published entry,
+	// which requires a stream to be configured for the subjects.
+	JetStream bool
+
+	// Formatter formats the payload of each message, defaults to a
+	// JSONFormatter.
+	Formatter Formatter
+
+	// User and Password, or Token, are used for authentication if set
+	User     string
+	Password string
+	Token    string
+
+	// Timeout is the timeout for connecting and waiting for acknowledgements,
+	// defaults to 10 seconds.
+	Timeout time.Duration
+
+	// QueueSize is the maximum number of entries waiting to be published,
+	// defaults to 10000. Entries logged while the queue is full are dropped.
+	// Dropped returns how many were.
+	QueueSize int
+
+	// FlushInterval is the maximum time entries wait before being published,
+	// defaults to 1 second.
+	FlushInterval time.Duration
+}
+
+// NATSOutput is an output that publishes entries to NATS using its text
+// protocol. Each entry is published to a subject made from the logger prefix,
+// e.g. logs.flashlight.proxy, so that subscribers can filter by component
+// using wildcards like logs.flashlight.>. Entries are published on a
+// background goroutine, reconnecting as necessary.
+type NATSOutput struct {
+	*batcher
+	addr string
+	opts NATSOptions
+
+	conn    net.Conn
+	writeMx sync.Mutex
+	w       *bufio.Writer
+	acks    chan natsAck
+	inbox   string
+	nextAck int
+}
+
+// natsAck is a JetStream acknowledgement, or rejection if err is set
+type natsAck struct {
+	subject string
+	err     error
+}
+
+// NewNATSOutput creates a NATSOutput that publishes to the NATS server at the
+// given address, e.g. "localhost:4222". The connection is established lazily.
+// opts may be nil.
+func NewNATSOutput(addr string, opts *NATSOptions) *NATSOutput {
+	o := &NATSOutput{addr: addr}
+	if opts != nil {
+		o.opts = *opts
+	}
+	if o.opts.Subject == "" {
+		o.opts.Subject = "logs"
+	}
+	if o.opts.Formatter == nil {
+		o.opts.Formatter = &JSONFormatter{}
+	}
+	if o.opts.Timeout <= 0 {
+		o.opts.Timeout = 10 * time.Second
+	}
+	if o.opts.QueueSize <= 0 {
+		o.opts.QueueSize = 10000
+	}
+	if o.opts.FlushInterval <= 0 {
+		o.opts.FlushInterval = time.Second
+	}
+	o.batcher = newBatcher(o.opts.QueueSize, 512, o.opts.FlushInterval, o.publish)
+	return o
+}
+
+// Write implements io.Writer, publishing each write as the message of an
+// entry without severity.
+func (o *NATSOutput) Write(p []byte) (int, error) {
+	return len(p), o.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (o *NATSOutput) WriteEntry(e Entry) error {
+	return o.add(e)
+}
+
+// Close publishes all queued entries and closes the connection
+func (o *NATSOutput) Close() error {
+	o.batcher.Close()
+	o.disconnect()
+	return nil
+}
+
+func (o *NATSOutput) publish(batch []Entry) {
+	for _, e := range batch {
+		if err := o.publishEntry(e); err != nil {
+			o.disconnect()
+			errorOnLogging(err)
+		}
+	}
+}
+
+func (o *NATSOutput) publishEntry(e Entry) error {
+	if o.conn == nil {
+		if err := o.connect(); err != nil {
+			return err
+		}
+	}
+	payload := o.opts.Formatter.Format(e)
+	reply := ""
+	if o.opts.JetStream {
+		o.nextAck++
+		reply = o.inbox + "." + strconv.Itoa(o.nextAck)
+	}
+
+	o.writeMx.Lock()
+	if reply == "" {
+		fmt.Fprintf(o.w, "PUB %s %d\r\n", o.subject(e.Prefix), len(payload))
+	} else {
+		fmt.Fprintf(o.w, "PUB %s %s %d\r\n", o.subject(e.Prefix), reply, len(payload))
+	}
+	o.w.Write(payload)
+	o.w.WriteString("\r\n")
+	err := o.w.Flush()
+	o.writeMx.Unlock()
+	if err != nil || reply == "" {
+		return err
+	}
+
+	timeout := time.NewTimer(o.opts.Timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case ack, ok := <-o.acks:
+			if !ok {
+				return fmt.Errorf("connection to %v closed while waiting for ack", o.addr)
+			}
+			if ack.subject == reply {
+				return ack.err
+			}
+		case <-timeout.C:
+			return fmt.Errorf("timed out waiting for JetStream ack from %v", o.addr)
+		}
+	}
+}
+
+// subject returns the subject for the given prefix, replacing characters that
+// aren't allowed in subjects.
+func (o *NATSOutput) subject(prefix string) string {
+	if prefix == "" {
+		return o.opts.Subject
+	}
+	return o.opts.Subject + "." + strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n', '*', '>':
+			return '_'
+		default:
+			return r
+		}
+	}, prefix)
+}
+
+func (o *NATSOutput) connect() error {
+	conn, err := net.DialTimeout("tcp", o.addr, o.opts.Timeout)
+	if err != nil {
+		return err
+	}
+	conn.SetReadDeadline(time.Now().Add(o.opts.Timeout))
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("unexpected greeting from NATS server %v: %v", o.addr, strings.TrimSpace(line))
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	connect := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "golog",
+		"lang":     "go",
+	}
+	if o.opts.User != "" {
+		connect["user"] = o.opts.User
+		connect["pass"] = o.opts.Password
+	}
+	if o.opts.Token != "" {
+		connect["auth_token"] = o.opts.Token
+	}
+	connectJSON, _ := json.Marshal(connect)
+
+	o.conn = conn
+	o.w = bufio.NewWriter(conn)
+	o.acks = make(chan natsAck, 1)
+	fmt.Fprintf(o.w, "CONNECT %s\r\n", connectJSON)
+	if o.opts.JetStream {
+		o.inbox = "_INBOX." + newEventID()
+		fmt.Fprintf(o.w, "SUB %s.* 1\r\n", o.inbox)
+	}
+	if err := o.w.Flush(); err != nil {
+		o.disconnect()
+		return err
+	}
+	go o.read(r, o.w, o.acks)
+	return nil
+}
+
+// read reads messages from the server, answering PINGs and forwarding the
+// reply subjects of acknowledgements.
+func (o *NATSOutput) read(r *bufio.Reader, w *bufio.Writer, acks chan natsAck) {
+	defer close(acks)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PING":
+			o.writeMx.Lock()
+			w.WriteString("PONG\r\n")
+			w.Flush()
+			o.writeMx.Unlock()
+		case strings.HasPrefix(line, "MSG "):
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			fields := strings.Fields(line)
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			ack := natsAck{subject: fields[1]}
+			if strings.Contains(string(payload), `"error"`) {
+				ack.err = fmt.Errorf("JetStream rejected entry: %s", payload[:size])
+			}
+			select {
+			case acks <- ack:
+			default:
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			errorOnLogging(fmt.Errorf("error from NATS server %v: %v", o.addr, line))
+		}
+	}
+}
+
+func (o *NATSOutput) disconnect() {
+	if o.conn != nil {
+		o.conn.Close()
+		o.conn = nil
+	}
+}
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type natsMessage struct {
+	subject string
+	payload string
+}
+
+// fakeNATS accepts a single connection, records published messages and acks
+// them if they have a reply subject.
+func fakeNATS(l net.Listener, published chan<- natsMessage, commands chan<- string) {
+	conn, err := l.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\nPING\r\n"))
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if !strings.HasPrefix(line, "PUB ") {
+			commands <- line
+			continue
+		}
+		fields := strings.Fields(line)
+		size, _ := strconv.Atoi(fields[len(fields)-1])
+		payload := make([]byte, size+2)
+		io.ReadFull(r, payload)
+		published <- natsMessage{fields[1], string(payload[:size])}
+		if len(fields) == 4 {
+			ack := `{"stream":"LOGS","seq":1}`
+			fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+		}
+	}
+}
+
+func TestNATSOutput(t *testing.T) {
+	for _, jetStream := range []bool{false, true} {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if !assert.NoError(t, err) {
+			return
+		}
+		published := make(chan natsMessage, 10)
+		commands := make(chan string, 10)
+		go fakeNATS(l, published, commands)
+
+		o := NewNATSOutput(l.Addr().String(), &NATSOptions{
+			Formatter: &customFormatter{},
+			JetStream: jetStream,
+			Token:     "secret",
+		})
+		o.WriteEntry(Entry{Severity: ERROR, Prefix: "flashlight.proxy", Message: "Hello world"})
+		o.Write([]byte("plain\n"))
+		assert.NoError(t, o.Close())
+		l.Close()
+
+		connect := <-commands
+		assert.True(t, strings.HasPrefix(connect, "CONNECT {"), connect)
+		assert.Contains(t, connect, `"auth_token":"secret"`)
+		if jetStream {
+			assert.Regexp(t, `^SUB _INBOX\.\w+\.\* 1$`, <-commands)
+			// The server's PING precedes the acks, so it must have been answered
+			assert.Equal(t, "PONG", <-commands)
+		}
+		assert.Equal(t, natsMessage{"logs.flashlight.proxy", "ERROR|flashlight.proxy||0|Hello world|0\n"}, <-published)
+		assert.Equal(t, natsMessage{"logs", "UNKNOWN|||0|plain|0\n"}, <-published)
+	}
+}
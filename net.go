@@ -0,0 +1,170 @@
+package golog
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	netBufferSize     = 10000
+	netInitialBackoff = 100 * time.Millisecond
+	netDialTimeout    = 10 * time.Second
+)
+
+// NetWriter is an output that streams formatted entries to a remote collector
+// over the network. Writes are queued and sent on a background goroutine, so
+// logging never blocks on the network. If the connection fails, NetWriter
+// reconnects with exponential backoff, buffering up to 10000 writes in the
+// meantime. Writes made while the buffer is full are dropped and counted, see
+// Dropped.
+type NetWriter struct {
+	dropCounter
+	network       string
+	addr          string
+	queue         chan []byte
+	flushRequests chan chan struct{}
+	closeOnce     sync.Once
+	closed        chan struct{}
+	done          chan struct{}
+	conn          net.Conn
+}
+
+// NetOutput creates a NetWriter that connects to addr on the given network,
+// for example NetOutput("tcp", "logs.example.com:5000"). The connection is
+// established in the background.
+func NetOutput(network string, addr string) *NetWriter {
+	w := &NetWriter{
+		network:       network,
+		addr:          addr,
+		queue:         make(chan []byte, netBufferSize),
+		flushRequests: make(chan chan struct{}),
+		closed:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// UnixSocketOutput creates a NetWriter that connects to the unix domain socket
+// at path, for example to hand logs to a node-local agent. If datagram is
+// true, each write is sent as a single datagram ("unixgram"), otherwise writes
+// are streamed ("unix").
+func UnixSocketOutput(path string, datagram bool) *NetWriter {
+	if datagram {
+		return NetOutput("unixgram", path)
+	}
+	return NetOutput("unix", path)
+}
+
+// Write implements io.Writer, queueing p to be sent
+func (w *NetWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.closed:
+		return 0, errClosed
+	default:
+	}
+	b := make([]byte, len(p))
+	copy(b, p)
+	select {
+	case w.queue <- b:
+	default:
+		w.drop()
+	}
+	return len(p), nil
+}
+
+// Flush waits for the writes queued before the call to be sent. While
+// disconnected, nothing can be sent and it returns right away.
+func (w *NetWriter) Flush() {
+	ack := make(chan struct{})
+	select {
+	case w.flushRequests <- ack:
+		<-ack
+	case <-w.done:
+	}
+}
+
+// Close sends whatever is queued, if connected, and closes the connection
+func (w *NetWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.closed)
+	})
+	<-w.done
+	return nil
+}
+
+func (w *NetWriter) run() {
+	defer close(w.done)
+	defer func() {
+		if w.conn != nil {
+			w.conn.Close()
+		}
+	}()
+
+	backoff := netInitialBackoff
+	for {
+		var b []byte
+		select {
+		case b = <-w.queue:
+		case ack := <-w.flushRequests:
+			b = w.drain()
+			close(ack)
+			if b == nil {
+				continue
+			}
+		case <-w.closed:
+			w.drain()
+			return
+		}
+
+		for !w.send(b) {
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+				if backoff > maxRetryBackoff {
+					backoff = maxRetryBackoff
+				}
+			case ack := <-w.flushRequests:
+				close(ack)
+			case <-w.closed:
+				w.drain()
+				return
+			}
+		}
+		backoff = netInitialBackoff
+	}
+}
+
+// send sends b, connecting first if necessary, and indicates whether or not
+// it succeeded.
+func (w *NetWriter) send(b []byte) bool {
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.addr, netDialTimeout)
+		if err != nil {
+			return false
+		}
+		w.conn = conn
+	}
+	if _, err := w.conn.Write(b); err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return false
+	}
+	return true
+}
+
+// drain makes a best effort to send everything still queued, giving up at the
+// first failure and returning what it failed to send.
+func (w *NetWriter) drain() []byte {
+	for {
+		select {
+		case b := <-w.queue:
+			if !w.send(b) {
+				return b
+			}
+		default:
+			return nil
+		}
+	}
+}
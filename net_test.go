@@ -0,0 +1,131 @@
+package golog
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetOutputReconnect(t *testing.T) {
+	// Find a free port and leave it unused for now
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	w := NetOutput("tcp", addr)
+	SetOutputs(w, w)
+	defer ResetOutputs()
+	LoggerFor("myprefix").Debug("while down")
+
+	l, err = net.Listen("tcp", addr)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+	conn, err := l.Accept()
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	lines := bufio.NewScanner(conn)
+	if assert.True(t, lines.Scan()) {
+		assert.Regexp(t, `^DEBUG myprefix: net_test.go:\d+ while down$`, lines.Text())
+	}
+
+	w.Write([]byte("while up\n"))
+	w.Flush()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if assert.True(t, lines.Scan(), "write should have been sent by Flush") {
+		assert.Equal(t, "while up", lines.Text())
+	}
+
+	assert.NoError(t, w.Close())
+	_, err = w.Write([]byte("after close\n"))
+	assert.Equal(t, errClosed, err)
+}
+
+func TestNetOutputDropped(t *testing.T) {
+	// Find a free port and leave it unused, so that writes queue up
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	w := NetOutput("tcp", addr)
+	defer w.Close()
+	for i := 0; i < netBufferSize+2; i++ {
+		n, err := w.Write([]byte("entry\n"))
+		assert.NoError(t, err, "dropped writes shouldn't fail")
+		assert.Equal(t, 6, n)
+	}
+	assert.True(t, w.Dropped() >= 1 && w.Dropped() <= 2, "expected 1 or 2 drops, got %d", w.Dropped())
+
+	done := make(chan bool)
+	go func() {
+		w.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Flush shouldn't wait while disconnected")
+	}
+}
+
+func TestUnixSocketOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	// Datagram
+	path := filepath.Join(dir, "dgram.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer pc.Close()
+	w := UnixSocketOutput(path, true)
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+	b := make([]byte, 100)
+	for _, expected := range []string{"first\n", "second\n"} {
+		n, _, err := pc.ReadFrom(b)
+		if assert.NoError(t, err) {
+			assert.Equal(t, expected, string(b[:n]))
+		}
+	}
+	assert.NoError(t, w.Close())
+
+	// Stream, reconnecting after the agent restarts
+	path = filepath.Join(dir, "stream.sock")
+	w = UnixSocketOutput(path, false)
+	defer w.Close()
+	w.Write([]byte("before agent started\n"))
+	l, err := net.Listen("unix", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+	conn, err := l.Accept()
+	if !assert.NoError(t, err) {
+		return
+	}
+	lines := bufio.NewScanner(conn)
+	if assert.True(t, lines.Scan()) {
+		assert.Equal(t, "before agent started", lines.Text())
+	}
+	conn.Close()
+}
@@ -0,0 +1,143 @@
+//go:build otel
+// +build otel
+
+// Package oteladapter implements the OpenTelemetry Logs Bridge API
+// (go.opentelemetry.io/otel/log) on top of golog, so that instrumentation
+// libraries emitting logs through it are funneled into golog's outputs and
+// ErrorReporters with their attributes preserved as context values. For
+// example:
+//
+//	global.SetLoggerProvider(oteladapter.NewLoggerProvider("otel"))
+//
+// Each instrumentation scope gets a Logger whose prefix is the given prefix
+// followed by a dot and the name of the scope. Severities are mapped to golog
+// severities by range, with FATAL records being logged at ERROR so that they
+// don't exit the process. Map attributes are flattened with their keys joined
+// by dots.
+//
+// SpanLookup ties golog's SpanExtractor to OpenTelemetry tracing, so that
+// entries logged with a context carry the IDs of its active span.
+//
+// The OpenTelemetry log API isn't a dependency of golog, so this package is
+// only built with the "otel" build tag, e.g. go build -tags otel, and requires
+// go.opentelemetry.io/otel/log and go.opentelemetry.io/otel/trace in the
+// go.mod of the main module. The log API is still unstable and changes between
+// versions, so this package is written against and tested with v0.11.0 only,
+// together with the trace API it was released with:
+//
+//	go get go.opentelemetry.io/otel/log@v0.11.0 go.opentelemetry.io/otel/trace@v1.35.0
+package oteladapter
+
+import (
+	"context"
+
+	"github.com/getlantern/golog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+)
+
+type loggerProvider struct {
+	embedded.LoggerProvider
+	prefix string
+}
+
+// NewLoggerProvider creates a log.LoggerProvider whose Loggers log with the
+// given prefix followed by the name of their instrumentation scope
+func NewLoggerProvider(prefix string) log.LoggerProvider {
+	return &loggerProvider{prefix: prefix}
+}
+
+func (p *loggerProvider) Logger(name string, options ...log.LoggerOption) log.Logger {
+	prefix := p.prefix
+	switch {
+	case prefix == "":
+		prefix = name
+	case name != "":
+		prefix += "." + name
+	}
+	return &logger{l: golog.LoggerFor(prefix).WithoutCaller()}
+}
+
+type logger struct {
+	embedded.Logger
+	l golog.Logger
+}
+
+// Severity returns the golog Severity at which records of the given severity
+// are logged
+func Severity(severity log.Severity) golog.Severity {
+	switch {
+	case severity >= log.SeverityError1:
+		return golog.ERROR
+	case severity >= log.SeverityWarn1:
+		return golog.WARN
+	case severity >= log.SeverityInfo1, severity == log.SeverityUndefined:
+		return golog.INFO
+	case severity >= log.SeverityDebug1:
+		return golog.DEBUG
+	default:
+		return golog.TRACE
+	}
+}
+
+func (l *logger) Enabled(ctx context.Context, param log.EnabledParameters) bool {
+	severity := Severity(param.Severity)
+	// errors are reported even when they aren't written
+	return severity >= golog.ERROR || l.l.IsEnabled(severity)
+}
+
+func (l *logger) Emit(ctx context.Context, record log.Record) {
+	gl := l.l
+	if record.AttributesLen() > 0 {
+		fields := make(map[string]interface{}, record.AttributesLen())
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			addValue(fields, kv.Key, kv.Value)
+			return true
+		})
+		gl = gl.WithFields(fields)
+	}
+	body := record.Body().String()
+	switch Severity(record.Severity()) {
+	case golog.TRACE:
+		gl.TraceCtx(ctx, body)
+	case golog.DEBUG:
+		gl.DebugCtx(ctx, body)
+	case golog.INFO:
+		gl.InfoCtx(ctx, body)
+	case golog.WARN:
+		gl.WarnCtx(ctx, body)
+	default:
+		gl.ErrorCtx(ctx, body)
+	}
+}
+
+// addValue adds v to fields under key, flattening maps
+func addValue(fields map[string]interface{}, key string, v log.Value) {
+	switch v.Kind() {
+	case log.KindBool:
+		fields[key] = v.AsBool()
+	case log.KindInt64:
+		fields[key] = v.AsInt64()
+	case log.KindFloat64:
+		fields[key] = v.AsFloat64()
+	case log.KindString:
+		fields[key] = v.AsString()
+	case log.KindBytes:
+		fields[key] = v.AsBytes()
+	case log.KindSlice:
+		values := v.AsSlice()
+		slice := make([]interface{}, 0, len(values))
+		for _, sv := range values {
+			element := make(map[string]interface{}, 1)
+			addValue(element, "", sv)
+			slice = append(slice, element[""])
+		}
+		fields[key] = slice
+	case log.KindMap:
+		for _, kv := range v.AsMap() {
+			addValue(fields, key+"."+kv.Key, kv.Value)
+		}
+	default:
+		fields[key] = v.String()
+	}
+}
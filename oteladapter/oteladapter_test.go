@@ -0,0 +1,55 @@
+//go:build otel
+// +build otel
+
+package oteladapter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLoggerProvider(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := golog.SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	l := NewLoggerProvider("otel").Logger("db")
+	var record log.Record
+	record.SetSeverity(log.SeverityInfo)
+	record.SetBody(log.StringValue("connected"))
+	record.AddAttributes(
+		log.Int("pool.size", 5),
+		log.Map("server", log.String("host", "db1"), log.Int("port", 5432)),
+	)
+	l.Emit(context.Background(), record)
+
+	record = log.Record{}
+	record.SetSeverity(log.SeverityFatal)
+	record.SetBody(log.StringValue("lost connection"))
+	l.Emit(context.Background(), record)
+
+	assert.Equal(t, "INFO otel.db: connected [pool.size=5 server.host=db1 server.port=5432]\n", debugOut.String())
+	assert.Equal(t, "ERROR otel.db: lost connection\n", errorOut.String())
+	assert.True(t, l.Enabled(context.Background(), log.EnabledParameters{Severity: log.SeverityError}))
+}
+
+func TestSpanLookup(t *testing.T) {
+	_, _, ok := SpanLookup(context.Background())
+	assert.False(t, ok)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10},
+		SpanID:  trace.SpanID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+	})
+	traceID, spanID, ok := SpanLookup(trace.ContextWithSpanContext(context.Background(), sc))
+	assert.True(t, ok)
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", traceID)
+	assert.Equal(t, "0102030405060708", spanID)
+}
@@ -0,0 +1,23 @@
+//go:build otel
+// +build otel
+
+package oteladapter
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanLookup is a golog.SpanLookup that looks up the active OpenTelemetry span
+// in ctx. Use it with golog.SpanExtractor to include the trace and span IDs in
+// entries logged with a context:
+//
+//	golog.RegisterContextExtractor(golog.SpanExtractor(oteladapter.SpanLookup))
+func SpanLookup(ctx context.Context) (traceID string, spanID string, ok bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", "", false
+	}
+	return sc.TraceID().String(), sc.SpanID().String(), true
+}
@@ -0,0 +1,236 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLPOptions configures an OTLPExporter
+type OTLPOptions struct {
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+
+	// Headers are added to each export request, e.g. for authentication
+	Headers map[string]string
+
+	// QueueSize is the maximum number of entries waiting to be exported,
+	// defaults to 10000. Entries logged while the queue is full are dropped.
+	// Dropped returns how many were.
+	QueueSize int
+
+	// BatchSize is the maximum number of entries per export request, defaults
+	// to 512.
+	BatchSize int
+
+	// FlushInterval is the maximum time entries wait before being exported,
+	// defaults to 5 seconds.
+	FlushInterval time.Duration
+
+	// Client is the http.Client used for exporting, defaults to a client with
+	// a 30 second timeout.
+	Client *http.Client
+}
+
+// OTLPExporter is an output that exports entries as OpenTelemetry LogRecords
+// to an OTLP/HTTP collector endpoint using the JSON encoding. Entries are
+// exported in batches on a background goroutine.
+//
+// Context values become LogRecord attributes, except for trace_id and span_id
+// which are used as the LogRecord's trace and span IDs. Each logger prefix is
+// reported as a separate instrumentation scope.
+type OTLPExporter struct {
+	*batcher
+	endpoint string
+	opts     OTLPOptions
+}
+
+// NewOTLPExporter creates an OTLPExporter that exports to the given endpoint,
+// for example "http://localhost:4318/v1/logs". opts may be nil.
+func NewOTLPExporter(endpoint string, opts *OTLPOptions) *OTLPExporter {
+	x := &OTLPExporter{endpoint: endpoint}
+	if opts != nil {
+		x.opts = *opts
+	}
+	if x.opts.QueueSize <= 0 {
+		x.opts.QueueSize = 10000
+	}
+	if x.opts.BatchSize <= 0 {
+		x.opts.BatchSize = 512
+	}
+	if x.opts.FlushInterval <= 0 {
+		x.opts.FlushInterval = 5 * time.Second
+	}
+	if x.opts.Client == nil {
+		x.opts.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	x.batcher = newBatcher(x.opts.QueueSize, x.opts.BatchSize, x.opts.FlushInterval, x.export)
+	return x
+}
+
+// Write implements io.Writer, exporting each write as the body of a LogRecord
+// without severity.
+func (x *OTLPExporter) Write(p []byte) (int, error) {
+	return len(p), x.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (x *OTLPExporter) WriteEntry(e Entry) error {
+	return x.add(e)
+}
+
+func (x *OTLPExporter) export(batch []Entry) {
+	body := x.encode(batch)
+	req, err := http.NewRequest(http.MethodPost, x.endpoint, bytes.NewReader(body))
+	if err != nil {
+		errorOnLogging(err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range x.opts.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := x.opts.Client.Do(req)
+	if err != nil {
+		errorOnLogging(err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		errorOnLogging(fmt.Errorf("unexpected response status exporting to %v: %v", x.endpoint, resp.Status))
+	}
+}
+
+// encode encodes the given batch as an ExportLogsServiceRequest
+func (x *OTLPExporter) encode(batch []Entry) []byte {
+	var prefixes []string
+	byPrefix := make(map[string][]Entry)
+	for _, e := range batch {
+		if _, found := byPrefix[e.Prefix]; !found {
+			prefixes = append(prefixes, e.Prefix)
+		}
+		byPrefix[e.Prefix] = append(byPrefix[e.Prefix], e)
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"resourceLogs":[{"resource":{"attributes":[`)
+	if x.opts.ServiceName != "" {
+		writeOTLPAttribute(buf, "service.name", x.opts.ServiceName)
+	}
+	buf.WriteString(`]},"scopeLogs":[`)
+	for i, prefix := range prefixes {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"scope":{"name":`)
+		writeJSONString(buf, prefix)
+		buf.WriteString(`},"logRecords":[`)
+		for j, e := range byPrefix[prefix] {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+			writeOTLPLogRecord(buf, e)
+		}
+		buf.WriteString(`]}`)
+	}
+	buf.WriteString(`]}]}`)
+	return buf.Bytes()
+}
+
+func writeOTLPLogRecord(buf *bytes.Buffer, e Entry) {
+	buf.WriteString(`{"timeUnixNano":"`)
+	buf.WriteString(strconv.FormatInt(e.Time.UnixNano(), 10))
+	buf.WriteString(`","severityNumber":`)
+	buf.WriteString(strconv.Itoa(otlpSeverityNumber(e.Severity)))
+	if e.Severity != 0 {
+		buf.WriteString(`,"severityText":`)
+		writeJSONString(buf, e.Severity.String())
+	}
+	buf.WriteString(`,"body":{"stringValue":`)
+	writeJSONString(buf, e.Message)
+	buf.WriteString(`},"attributes":[`)
+	first := true
+	writeAttribute := func(key string, value interface{}) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeOTLPAttribute(buf, key, value)
+	}
+	if e.File != "" {
+		writeAttribute("code.filepath", e.File)
+		writeAttribute("code.lineno", e.Line)
+	}
+	for _, key := range sortedKeys(e.Context) {
+		switch key {
+		case "trace_id", "span_id":
+			// written as traceId and spanId below
+		default:
+			writeAttribute(key, e.Context[key])
+		}
+	}
+	if len(e.Stack) > 0 {
+		writeAttribute("exception.stacktrace", e.Message+"\n"+strings.Join(e.Stack, "\n"))
+	}
+	buf.WriteByte(']')
+	if traceID, ok := e.Context["trace_id"]; ok {
+		buf.WriteString(`,"traceId":`)
+		writeJSONString(buf, fmt.Sprint(traceID))
+	}
+	if spanID, ok := e.Context["span_id"]; ok {
+		buf.WriteString(`,"spanId":`)
+		writeJSONString(buf, fmt.Sprint(spanID))
+	}
+	buf.WriteByte('}')
+}
+
+// writeOTLPAttribute writes a KeyValue with an AnyValue matching the type of
+// value.
+func writeOTLPAttribute(buf *bytes.Buffer, key string, value interface{}) {
+	buf.WriteString(`{"key":`)
+	writeJSONString(buf, key)
+	buf.WriteString(`,"value":{`)
+	if f, ok := value.(Field); ok {
+		value = f.Value()
+	}
+	switch v := value.(type) {
+	case bool:
+		buf.WriteString(`"boolValue":`)
+		buf.WriteString(strconv.FormatBool(v))
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		// intValue is an int64, which is encoded as a string in JSON
+		buf.WriteString(`"intValue":"`)
+		fmt.Fprint(buf, v)
+		buf.WriteByte('"')
+	case float32, float64:
+		buf.WriteString(`"doubleValue":`)
+		writeJSONValue(buf, v)
+	default:
+		buf.WriteString(`"stringValue":`)
+		writeJSONString(buf, fmt.Sprint(v))
+	}
+	buf.WriteString(`}}`)
+}
+
+// otlpSeverityNumber maps the given Severity to an OTLP SeverityNumber
+func otlpSeverityNumber(s Severity) int {
+	switch {
+	case s >= FATAL:
+		return 21
+	case s >= ERROR:
+		return 17
+	case s >= WARN:
+		return 13
+	case s >= INFO:
+		return 9
+	case s >= DEBUG:
+		return 5
+	case s >= TRACE:
+		return 1
+	default:
+		return 0
+	}
+}
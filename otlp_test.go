@@ -0,0 +1,71 @@
+package golog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", req.Header.Get("X-Auth"))
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		requests <- body
+	}))
+	defer server.Close()
+
+	x := NewOTLPExporter(server.URL, &OTLPOptions{ServiceName: "myservice", Headers: map[string]string{"X-Auth": "secret"}})
+	defer x.Close()
+	SetOutputs(x, x)
+	defer SetOutputs(ioutil.Discard, ioutil.Discard)
+
+	l := LoggerFor("myprefix")
+	op := ops.Begin("name").Set("cvarA", "a").Set("trace_id", "0af7651916cd43dd8448eb211c80319c")
+	l.Debug("Hello world")
+	l.Error(errors.New("world"))
+	op.End()
+	x.Flush()
+
+	var body map[string]interface{}
+	select {
+	case body = <-requests:
+	default:
+		t.Fatal("nothing exported")
+	}
+	resourceLogs := body["resourceLogs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{map[string]interface{}{"key": "service.name", "value": map[string]interface{}{"stringValue": "myservice"}}}, resourceLogs["resource"].(map[string]interface{})["attributes"])
+	scopeLogs := resourceLogs["scopeLogs"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "myprefix", scopeLogs["scope"].(map[string]interface{})["name"])
+	records := scopeLogs["logRecords"].([]interface{})
+	if !assert.Len(t, records, 2) {
+		return
+	}
+
+	debug := records[0].(map[string]interface{})
+	assert.Equal(t, float64(5), debug["severityNumber"])
+	assert.Equal(t, "DEBUG", debug["severityText"])
+	assert.Equal(t, map[string]interface{}{"stringValue": "Hello world"}, debug["body"])
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", debug["traceId"])
+	assert.Contains(t, debug["attributes"], map[string]interface{}{"key": "cvarA", "value": map[string]interface{}{"stringValue": "a"}})
+	assert.Contains(t, debug["attributes"], map[string]interface{}{"key": "code.filepath", "value": map[string]interface{}{"stringValue": "otlp_test.go"}})
+
+	errorRecord := records[1].(map[string]interface{})
+	assert.Equal(t, float64(17), errorRecord["severityNumber"])
+	assert.Equal(t, map[string]interface{}{"stringValue": "world"}, errorRecord["body"])
+	found := false
+	for _, attr := range errorRecord["attributes"].([]interface{}) {
+		if attr.(map[string]interface{})["key"] == "exception.stacktrace" {
+			found = true
+		}
+	}
+	assert.True(t, found, "should have included stack trace")
+}
@@ -0,0 +1,74 @@
+package golog
+
+import (
+	"fmt"
+)
+
+// HandlePanics logs a panic of the calling goroutine at FATAL, along with the
+// stack of the panic, reports it to the ErrorReporters, flushes the outputs
+// (see Flush) and then calls the OnFatal function, which exits the process by
+// default. It must be deferred, typically at the top of main and of long
+// running goroutines, as panics on other goroutines can't be recovered:
+//
+//	func main() {
+//		defer golog.HandlePanics()
+//		...
+//	}
+//
+// See Go for launching goroutines that handle their panics.
+func HandlePanics() {
+	if p := recover(); p != nil {
+		err := panicError(p)
+		l := panicLogger()
+		l.errorSkipFrames(err, 1, FATAL)
+		if flushErr := Flush(); flushErr != nil {
+			errorOnLogging(flushErr)
+		}
+		l.fatal(err)
+	}
+}
+
+// RecoverAndLog recovers a panic of the calling goroutine, logs it at ERROR
+// along with the stack of the panic, reports it to the ErrorReporters and
+// stores it in *err, so that the panicking function returns an error instead
+// of crashing the process. It must be deferred by a function with a named
+// error result:
+//
+//	func process(job *Job) (err error) {
+//		defer golog.RecoverAndLog(&err)
+//		...
+//	}
+func RecoverAndLog(err *error) {
+	if p := recover(); p != nil {
+		perr := panicError(p)
+		panicLogger().errorSkipFrames(perr, 1, ERROR)
+		if err != nil {
+			*err = perr
+		}
+	}
+}
+
+// Go runs fn on a new goroutine that handles its panics with HandlePanics, so
+// that they're logged and reported before the process exits.
+func Go(fn func()) {
+	go func() {
+		defer HandlePanics()
+		fn()
+	}()
+}
+
+// panicLogger returns the Logger with which panics are logged from a deferred
+// function, which includes the stack in entries and skips the frame of
+// runtime.gopanic, so that entries report the location of the panic.
+func panicLogger() *logger {
+	return LoggerFor("panic").WithStack().WithCallerSkip(1).(*logger)
+}
+
+// panicError returns an error for the value of a panic, wrapping it if it's
+// an error
+func panicError(p interface{}) error {
+	if err, ok := p.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", p)
+}
@@ -0,0 +1,46 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errPanic = errors.New("boom")
+
+func panicking() (err error) {
+	defer RecoverAndLog(&err)
+	panic(errPanic)
+}
+
+func TestRecoverAndLog(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+
+	err := panicking()
+	assert.EqualError(t, err, "panic: boom")
+	assert.True(t, errors.Is(err, errPanic), "panics with errors should be wrapped")
+	assert.Regexp(t, `^ERROR panic: panic_test.go:15 panic: boom\n`, out.String())
+	assert.Regexp(t, `\nERROR panic: panic_test.go:15   at github.com/getlantern/golog.panicking \(panic_test.go:15\)\n`, out.String())
+}
+
+func TestHandlePanics(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+	fatalErrs := make(chan error, 1)
+	OnFatal(func(err error) {
+		fatalErrs <- err
+	})
+	defer DefaultOnFatal()
+
+	Go(func() {
+		panic("crash")
+	})
+	assert.EqualError(t, <-fatalErrs, "panic: crash")
+	assert.Regexp(t, `^FATAL panic: panic_test.go:\d+ panic: crash`, out.String())
+	assert.Regexp(t, `\nFATAL panic: panic_test.go:\d+   at github.com/getlantern/golog.TestHandlePanics.func2 \(panic_test.go:\d+\)\n`, out.String())
+}
@@ -0,0 +1,41 @@
+package golog
+
+import "os"
+
+// ProcessInfo identifies the running process in log entries
+type ProcessInfo struct {
+	// AppName is included as the "app" field if not empty
+	AppName string
+
+	// Version is included as the "version" field if not empty
+	Version string
+
+	// Commit is included as the "commit" field if not empty
+	Commit string
+}
+
+// SetProcessInfo includes the hostname and pid, along with the given
+// ProcessInfo, in the global fields, so that they're included in the context
+// of every entry logged by any logger and in the context passed to
+// ErrorReporters. This allows telling apart the output of multiple instances
+// after aggregation. Pass nil to stop including process information.
+func SetProcessInfo(info *ProcessInfo) {
+	fields := make(map[string]interface{})
+	if info != nil {
+		fields["hostname"] = hostname
+		fields["pid"] = os.Getpid()
+		if info.AppName != "" {
+			fields["app"] = info.AppName
+		}
+		if info.Version != "" {
+			fields["version"] = info.Version
+		}
+		if info.Commit != "" {
+			fields["commit"] = info.Commit
+		}
+	}
+	globalFieldsMx.Lock()
+	defer globalFieldsMx.Unlock()
+	processFields = fields
+	storeGlobalFields()
+}
@@ -0,0 +1,74 @@
+package golog
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessInfo(t *testing.T) {
+	SetProcessInfo(&ProcessInfo{AppName: "myapp", Version: "1.0"})
+	defer SetProcessInfo(nil)
+
+	r := &entryRecorder{}
+	SetOutputs(ioutil.Discard, r)
+	defer SetOutputs(ioutil.Discard, ioutil.Discard)
+	l := LoggerFor("myprefix")
+	l.Debugf("Hello %v", "world")
+	l.Debug("Hello world")
+	if assert.Len(t, r.entries, 2) {
+		for _, e := range r.entries {
+			assert.Equal(t, hostname, e.Context["hostname"])
+			assert.Equal(t, os.Getpid(), e.Context["pid"])
+			assert.Equal(t, "myapp", e.Context["app"])
+			assert.Equal(t, "1.0", e.Context["version"])
+			assert.NotContains(t, e.Context, "commit")
+		}
+	}
+
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	SetProcessInfo(&ProcessInfo{})
+	l.Debug("Hello world")
+	assert.Equal(t, normalized(fmt.Sprintf("DEBUG myprefix: process_test.go:999 Hello world [hostname=%v pid=999]\n", hostname)), out.String())
+}
+
+func TestProcessInfoReported(t *testing.T) {
+	var reported map[string]interface{}
+	reportersMutex.Lock()
+	oldReporters := reporters
+	reporters = []ErrorReporter{func(err error, severity Severity, ctx map[string]interface{}) {
+		reported = ctx
+	}}
+	reportersMutex.Unlock()
+	defer func() {
+		reportersMutex.Lock()
+		reporters = oldReporters
+		reportersMutex.Unlock()
+	}()
+
+	reset := SetOutputs(ioutil.Discard, ioutil.Discard)
+	defer reset()
+	SetProcessInfo(&ProcessInfo{AppName: "myapp", Version: "1.0"})
+	defer SetProcessInfo(nil)
+	SetGlobalFields(map[string]interface{}{"version": "override", "env": "prod"})
+	defer SetGlobalFields(nil)
+
+	LoggerFor("myprefix").Error("failed")
+	if assert.NotNil(t, reported) {
+		assert.Equal(t, hostname, reported["hostname"])
+		assert.Equal(t, os.Getpid(), reported["pid"])
+		assert.Equal(t, "myapp", reported["app"])
+		assert.Equal(t, "override", reported["version"], "global fields should take precedence over process info")
+		assert.Equal(t, "prod", reported["env"])
+	}
+
+	SetGlobalFields(nil)
+	SetProcessInfo(nil)
+	LoggerFor("myprefix").Error("failed")
+	assert.NotContains(t, reported, "app")
+	assert.NotContains(t, reported, "env")
+}
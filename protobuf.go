@@ -0,0 +1,185 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	pbVarint = 0
+	pbBytes  = 2
+)
+
+// ProtobufFormatter is a Formatter that writes entries as Entry messages of
+// the protocol buffers schema in proto/logstream.proto, each prefixed with its
+// varint encoded length. Context values are written as strings using
+// fmt.Sprint. Use a ProtobufDecoder to read the entries back.
+type ProtobufFormatter struct{}
+
+func (f *ProtobufFormatter) Format(e Entry) []byte {
+	msg := &bytes.Buffer{}
+	if !e.Time.IsZero() {
+		writeProtobufVarint(msg, 2, uint64(e.Time.UnixNano()))
+	}
+	writeProtobufVarint(msg, 3, uint64(e.Severity))
+	writeProtobufString(msg, 4, e.Prefix)
+	writeProtobufString(msg, 5, e.File)
+	writeProtobufVarint(msg, 6, uint64(e.Line))
+	writeProtobufString(msg, 7, e.Message)
+	for _, line := range e.Stack {
+		writeProtobufBytes(msg, 8, []byte(line))
+	}
+	for _, key := range sortedKeys(e.Context) {
+		kv := &bytes.Buffer{}
+		writeProtobufBytes(kv, 1, []byte(key))
+		writeProtobufBytes(kv, 2, []byte(fmt.Sprint(e.Context[key])))
+		writeProtobufBytes(msg, 9, kv.Bytes())
+	}
+
+	buf := &bytes.Buffer{}
+	writeUvarint(buf, uint64(msg.Len()))
+	buf.Write(msg.Bytes())
+	return buf.Bytes()
+}
+
+// ProtobufDecoder reads entries written by a ProtobufFormatter
+type ProtobufDecoder struct {
+	r *bufio.Reader
+}
+
+// NewProtobufDecoder creates a ProtobufDecoder that reads from r
+func NewProtobufDecoder(r io.Reader) *ProtobufDecoder {
+	return &ProtobufDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode decodes the next Entry, returning io.EOF once there are no more
+// entries. Unknown fields, including sequence, are ignored.
+func (d *ProtobufDecoder) Decode() (Entry, error) {
+	var e Entry
+	size, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return e, err
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(d.r, msg); err != nil {
+		return e, unexpectedEOF(err)
+	}
+	err = readProtobufFields(msg, func(field int, varint uint64, b []byte) error {
+		switch field {
+		case 2:
+			e.Time = time.Unix(0, int64(varint))
+		case 3:
+			e.Severity = Severity(int32(varint))
+		case 4:
+			e.Prefix = string(b)
+		case 5:
+			e.File = string(b)
+		case 6:
+			e.Line = int(int32(varint))
+		case 7:
+			e.Message = string(b)
+		case 8:
+			e.Stack = append(e.Stack, string(b))
+		case 9:
+			var key, value string
+			err := readProtobufFields(b, func(field int, _ uint64, b []byte) error {
+				switch field {
+				case 1:
+					key = string(b)
+				case 2:
+					value = string(b)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if e.Context == nil {
+				e.Context = make(map[string]interface{})
+			}
+			e.Context[key] = value
+		}
+		return nil
+	})
+	return e, err
+}
+
+// readProtobufFields calls onField with the value of each varint and length
+// delimited field in msg.
+func readProtobufFields(msg []byte, onField func(field int, varint uint64, b []byte) error) error {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return io.ErrUnexpectedEOF
+		}
+		msg = msg[n:]
+		field := int(key >> 3)
+		switch key & 0x7 {
+		case pbVarint:
+			v, n := binary.Uvarint(msg)
+			if n <= 0 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[n:]
+			if err := onField(field, v, nil); err != nil {
+				return err
+			}
+		case pbBytes:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return io.ErrUnexpectedEOF
+			}
+			b := msg[n : n+int(size)]
+			msg = msg[n+int(size):]
+			if err := onField(field, 0, b); err != nil {
+				return err
+			}
+		case 1:
+			if len(msg) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[8:]
+		case 5:
+			if len(msg) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&0x7)
+		}
+	}
+	return nil
+}
+
+// writeProtobufVarint writes a varint field, omitting it if zero as proto3
+// does. Negative int32 and int64 values must be sign extended to 64 bits.
+func writeProtobufVarint(buf *bytes.Buffer, field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	writeUvarint(buf, uint64(field<<3|pbVarint))
+	writeUvarint(buf, v)
+}
+
+func writeProtobufString(buf *bytes.Buffer, field int, s string) {
+	if s == "" {
+		return
+	}
+	writeProtobufBytes(buf, field, []byte(s))
+}
+
+func writeProtobufBytes(buf *bytes.Buffer, field int, b []byte) {
+	writeUvarint(buf, uint64(field<<3|pbBytes))
+	writeUvarint(buf, uint64(len(b)))
+	buf.Write(b)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	buf.Write(b[:n])
+}
@@ -0,0 +1,65 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtobufRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{
+			Time:     time.Unix(0, 1560171845123456789),
+			Severity: ERROR,
+			Prefix:   "myprefix",
+			File:     "file.go",
+			Line:     12,
+			Message:  "Hello world",
+			Stack:    []string{"  at a", "  at b"},
+			Context:  map[string]interface{}{"cvarA": "a", "count": 5},
+		},
+		{
+			Severity: -1,
+			Message:  "negative",
+		},
+	}
+
+	buf := &bytes.Buffer{}
+	f := &ProtobufFormatter{}
+	for _, e := range entries {
+		buf.Write(f.Format(e))
+	}
+
+	d := NewProtobufDecoder(buf)
+	first, err := d.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, entries[0].Time.UnixNano(), first.Time.UnixNano())
+		assert.Equal(t, entries[0].Severity, first.Severity)
+		assert.Equal(t, entries[0].Prefix, first.Prefix)
+		assert.Equal(t, entries[0].File, first.File)
+		assert.Equal(t, entries[0].Line, first.Line)
+		assert.Equal(t, entries[0].Message, first.Message)
+		assert.Equal(t, entries[0].Stack, first.Stack)
+		assert.Equal(t, map[string]interface{}{"cvarA": "a", "count": "5"}, first.Context)
+	}
+
+	second, err := d.Decode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, Severity(-1), second.Severity)
+		assert.Equal(t, "negative", second.Message)
+		assert.True(t, second.Time.IsZero())
+		assert.Nil(t, second.Context)
+	}
+
+	_, err = d.Decode()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestProtobufWireFormat(t *testing.T) {
+	// Severity 500 is field 3 (varint), message is field 7 (length delimited)
+	b := (&ProtobufFormatter{}).Format(Entry{Severity: ERROR, Message: "hi"})
+	assert.Equal(t, []byte{0x07, 0x18, 0xf4, 0x03, 0x3a, 0x02, 'h', 'i'}, b)
+}
@@ -0,0 +1,95 @@
+package golog
+
+import (
+	"sync/atomic"
+
+	"github.com/getlantern/context"
+	"github.com/getlantern/ops"
+)
+
+var (
+	contextProvider atomic.Value
+)
+
+func init() {
+	SetContextProvider(OpsContextProvider)
+}
+
+// ContextProvider provides the context values of the current goroutine that
+// are included in every entry, like those set with github.com/getlantern/ops,
+// which is the default.
+type ContextProvider interface {
+	// Fields returns the context values, which must not be modified afterwards
+	Fields() map[string]interface{}
+}
+
+// GlobalContextProvider is a ContextProvider that also has process-wide
+// context values, which are included when reporting errors.
+type GlobalContextProvider interface {
+	ContextProvider
+
+	// GlobalFields returns the context values together with the process-wide
+	// ones, which must not be modified afterwards
+	GlobalFields() map[string]interface{}
+}
+
+// ContextProviderFunc adapts a function to a ContextProvider
+type ContextProviderFunc func() map[string]interface{}
+
+// Fields implements ContextProvider
+func (f ContextProviderFunc) Fields() map[string]interface{} {
+	return f()
+}
+
+type opsContextProvider struct{}
+
+func (opsContextProvider) Fields() map[string]interface{} {
+	return ops.AsMap(nil, false)
+}
+
+func (opsContextProvider) GlobalFields() map[string]interface{} {
+	return ops.AsMap(nil, true)
+}
+
+// OpsContextProvider is the default ContextProvider, which provides the
+// context values set with github.com/getlantern/ops, including ops' global
+// values when reporting errors.
+var OpsContextProvider GlobalContextProvider = opsContextProvider{}
+
+type providerHolder struct {
+	ContextProvider
+}
+
+// SetContextProvider sets the ContextProvider used by all loggers, or disables
+// goroutine context values if p is nil. Context values of logged errors, like
+// those from github.com/getlantern/errors, are always included.
+func SetContextProvider(p ContextProvider) {
+	contextProvider.Store(&providerHolder{p})
+}
+
+// contextValues returns the context values of arg, if it has any, followed by
+// those of the ContextProvider, including its process-wide values if
+// includeGlobals is true and it's a GlobalContextProvider.
+func contextValues(arg interface{}, includeGlobals bool) map[string]interface{} {
+	p := contextProvider.Load().(*providerHolder).ContextProvider
+	if _, isOps := p.(opsContextProvider); isOps {
+		return ops.AsMap(arg, includeGlobals)
+	}
+	values := make(context.Map)
+	if contextual, ok := arg.(context.Contextual); ok {
+		contextual.Fill(values)
+	}
+	if p == nil {
+		return values
+	}
+	fields := p.Fields
+	if global, ok := p.(GlobalContextProvider); ok && includeGlobals {
+		fields = global.GlobalFields
+	}
+	for key, value := range fields() {
+		if _, exists := values[key]; !exists {
+			values[key] = value
+		}
+	}
+	return values
+}
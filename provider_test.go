@@ -0,0 +1,40 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextProvider(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(out, out)
+	defer reset()
+	defer SetContextProvider(OpsContextProvider)
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	op := ops.Begin("name")
+	defer op.End()
+
+	SetContextProvider(ContextProviderFunc(func() map[string]interface{} {
+		return map[string]interface{}{"request": "abc", "error": "provided"}
+	}))
+	l.Debug("custom")
+	l.Error(errors.New("failed"))
+	assert.Regexp(t, `^DEBUG myprefix: provider_test.go:\d+ custom \[error=provided request=abc\]\n`, out.String())
+	assert.Regexp(t, `\nERROR myprefix: provider_test.go:\d+ failed \[error=failed .*request=abc.*\]\n`, out.String(), "error context should take precedence")
+
+	out.Reset()
+	SetContextProvider(nil)
+	l.Debug("disabled")
+	assert.Regexp(t, `^DEBUG myprefix: provider_test.go:\d+ disabled\n$`, out.String())
+
+	out.Reset()
+	SetContextProvider(OpsContextProvider)
+	l.Debug("ops")
+	assert.Regexp(t, `^DEBUG myprefix: provider_test.go:\d+ ops \[op=name root_op=name\]\n$`, out.String())
+}
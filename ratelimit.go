@@ -0,0 +1,120 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is an output that limits the rate of entries written per logger
+// prefix using token buckets, so that one misbehaving component can't starve
+// the underlying output or fill the disk. Entries beyond the limit are
+// dropped and counted. The first entry written after some were dropped is
+// preceded by a WARN entry stating how many were dropped. FATAL entries are
+// never dropped.
+type RateLimiter struct {
+	out   Sink
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mx      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	pending uint64
+	dropped uint64
+}
+
+// RateLimitOutput creates a RateLimiter that writes up to perSecond entries
+// per second for each prefix, allowing bursts of up to burst entries. If out
+// isn't an EntryWriter, entries are formatted with the Formatter of the
+// logger that logged them. For example:
+//
+//	out := golog.RateLimitOutput(os.Stderr, 100, 500)
+func RateLimitOutput(out io.Writer, perSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		out:     Sink{Out: out},
+		rate:    perSecond,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity.
+func (r *RateLimiter) Write(p []byte) (int, error) {
+	return len(p), r.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (r *RateLimiter) WriteEntry(e Entry) error {
+	allowed, dropped := r.take(e)
+	if !allowed {
+		return nil
+	}
+	if dropped > 0 {
+		err := r.out.write(Entry{
+			Time:      e.Time,
+			Severity:  WARN,
+			Prefix:    e.Prefix,
+			Message:   fmt.Sprintf("rate limited, dropped %d entries", dropped),
+			formatter: e.formatter,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return r.out.write(e)
+}
+
+// take takes a token from the bucket of e's prefix, indicating whether or not
+// e is allowed and how many entries were dropped since the last allowed one.
+func (r *RateLimiter) take(e Entry) (bool, uint64) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	now := r.now()
+	b := r.buckets[e.Prefix]
+	if b == nil {
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[e.Prefix] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * r.rate
+	if b.tokens > r.burst {
+		b.tokens = r.burst
+	}
+	b.last = now
+	if b.tokens < 1 && e.Severity < FATAL {
+		b.pending++
+		b.dropped++
+		return false, 0
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+	}
+	dropped := b.pending
+	b.pending = 0
+	return true, dropped
+}
+
+// Dropped returns the total number of entries dropped for each prefix
+func (r *RateLimiter) Dropped() map[string]uint64 {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	result := make(map[string]uint64)
+	for prefix, b := range r.buckets {
+		if b.dropped > 0 {
+			result[prefix] = b.dropped
+		}
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package golog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	ring := NewRingBuffer(100)
+	r := RateLimitOutput(ring, 2, 3)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		r.WriteEntry(Entry{Severity: DEBUG, Prefix: "chatty", Message: "chatty"})
+	}
+	r.WriteEntry(Entry{Severity: DEBUG, Prefix: "other", Message: "other"})
+	r.WriteEntry(Entry{Severity: FATAL, Prefix: "chatty", Message: "fatal"})
+	assert.Equal(t, []string{"chatty", "chatty", "chatty", "other", "rate limited, dropped 2 entries", "fatal"}, ringMessages(ring))
+	assert.Equal(t, Severity(WARN), ring.Entries()[4].Severity)
+	assert.Equal(t, "chatty", ring.Entries()[4].Prefix)
+	assert.Equal(t, map[string]uint64{"chatty": 2}, r.Dropped())
+
+	// one token per 500ms
+	now = now.Add(time.Second)
+	for i := 0; i < 3; i++ {
+		r.WriteEntry(Entry{Severity: DEBUG, Prefix: "chatty", Message: "later"})
+	}
+	r.WriteEntry(Entry{Severity: DEBUG, Prefix: "chatty", Message: "last"})
+	assert.Equal(t, []string{"chatty", "chatty", "chatty", "other", "rate limited, dropped 2 entries", "fatal", "later", "later"}, ringMessages(ring))
+	assert.Equal(t, map[string]uint64{"chatty": 4}, r.Dropped())
+}
+
+func ringMessages(ring *RingBuffer) []string {
+	var messages []string
+	for _, e := range ring.Entries() {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
@@ -0,0 +1,177 @@
+package golog
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Redacted replaces the values of redacted keys
+const Redacted = "[REDACTED]"
+
+var (
+	// EmailPattern matches email addresses
+	EmailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+	// CreditCardPattern matches credit card numbers of 13 to 19 digits,
+	// optionally grouped with spaces or dashes
+	CreditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+	// IPv4Pattern matches IPv4 addresses
+	IPv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+)
+
+var (
+	redactedKeys atomic.Value
+	scrubbersMx  sync.Mutex
+	scrubbers    atomic.Value
+)
+
+type scrubber struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+func init() {
+	SetRedactedKeys()
+}
+
+// SetRedactedKeys sets the context keys whose values are replaced with
+// "[REDACTED]" in every entry, after filters have run and before any output
+// sees the entry, as well as in the context passed to ErrorReporters. Keys are
+// matched case-insensitively. For example:
+//
+//	golog.SetRedactedKeys("password", "token", "authorization", "cookie")
+//
+// Call it without keys to stop redacting.
+func SetRedactedKeys(keys ...string) {
+	redacted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = true
+	}
+	redactedKeys.Store(redacted)
+}
+
+// AddScrubber replaces all matches of pattern with replacement, which may
+// refer to submatches like regexp.ReplaceAllString, in the message, stack and
+// context values of every entry and in the context passed to ErrorReporters.
+// Like redaction, scrubbing happens after filters have run and before any
+// output sees the entry. Context values that aren't strings, including
+// numbers, are scrubbed in their fmt.Sprint form and replaced with a string if
+// anything matched, so that a card number held in an integer is caught too.
+// For example:
+//
+//	golog.AddScrubber(golog.EmailPattern, "[EMAIL]")
+func AddScrubber(pattern *regexp.Regexp, replacement string) {
+	scrubbersMx.Lock()
+	defer scrubbersMx.Unlock()
+	current, _ := scrubbers.Load().([]scrubber)
+	scrubbers.Store(append(append([]scrubber(nil), current...), scrubber{pattern, replacement}))
+}
+
+// ResetScrubbers removes all scrubbers added with AddScrubber
+func ResetScrubbers() {
+	scrubbersMx.Lock()
+	defer scrubbersMx.Unlock()
+	scrubbers.Store([]scrubber(nil))
+}
+
+// sanitize redacts and scrubs the given entry
+func sanitize(e Entry) Entry {
+	e.Context = redact(e.Context)
+	ss, _ := scrubbers.Load().([]scrubber)
+	if len(ss) == 0 {
+		return e
+	}
+	e.Message = scrub(ss, e.Message)
+	if len(e.Stack) > 0 {
+		stack := make([]string, len(e.Stack))
+		for i, line := range e.Stack {
+			stack[i] = scrub(ss, line)
+		}
+		e.Stack = stack
+	}
+	e.Context = scrubValues(ss, e.Context)
+	return e
+}
+
+// sanitizeValues redacts and scrubs the given context values
+func sanitizeValues(values map[string]interface{}) map[string]interface{} {
+	ss, _ := scrubbers.Load().([]scrubber)
+	return scrubValues(ss, redact(values))
+}
+
+func scrub(ss []scrubber, s string) string {
+	for _, sc := range ss {
+		s = sc.pattern.ReplaceAllString(s, sc.replacement)
+	}
+	return s
+}
+
+// scrubValues returns the given context values with scrubbed values, copying
+// them if necessary.
+func scrubValues(ss []scrubber, values map[string]interface{}) map[string]interface{} {
+	if len(ss) == 0 {
+		return values
+	}
+	copied := false
+	for key, value := range values {
+		var original string
+		switch v := value.(type) {
+		case nil, bool:
+			continue
+		case string:
+			original = v
+		case int:
+			original = strconv.Itoa(v)
+		case int64:
+			original = strconv.FormatInt(v, 10)
+		case uint64:
+			original = strconv.FormatUint(v, 10)
+		default:
+			original = fmt.Sprint(v)
+		}
+		scrubbed := scrub(ss, original)
+		if scrubbed == original {
+			continue
+		}
+		if !copied {
+			values = copyValues(values)
+			copied = true
+		}
+		values[key] = scrubbed
+	}
+	return values
+}
+
+// redact returns the given context values with the values of redacted keys
+// replaced, copying them if necessary.
+func redact(values map[string]interface{}) map[string]interface{} {
+	keys := redactedKeys.Load().(map[string]bool)
+	if len(keys) == 0 {
+		return values
+	}
+	copied := false
+	for key, value := range values {
+		if value == Redacted || !keys[strings.ToLower(key)] {
+			continue
+		}
+		if !copied {
+			values = copyValues(values)
+			copied = true
+		}
+		values[key] = Redacted
+	}
+	return values
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
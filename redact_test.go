@@ -0,0 +1,80 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactedKeys(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := &entryRecorder{}
+	reset := SetOutputs(r, out)
+	defer reset()
+	SetRedactedKeys("password", "Authorization")
+	defer SetRedactedKeys()
+
+	var reported map[string]interface{}
+	RegisterReporter(func(err error, severity Severity, ctx map[string]interface{}) {
+		if ctx["prefix"] == "redacting" {
+			reported = ctx
+		}
+	})
+
+	l := LoggerFor("redacting")
+	l.SetFormatter(&TextFormatter{})
+	fields := map[string]interface{}{"PASSWORD": "secret", "authorization": String("authorization", "Bearer x"), "user": "bob"}
+	l.WithFields(fields).Debug("Hello")
+	l.WithFields(fields).Error(errors.New("failed"))
+	assert.Regexp(t, `^DEBUG redacting: redact_test.go:\d+ Hello \[PASSWORD=\[REDACTED\] authorization=\[REDACTED\] user=bob\]\n$`, out.String())
+	if assert.Len(t, r.entries, 1) {
+		assert.Equal(t, Redacted, r.entries[0].Context["PASSWORD"])
+		assert.Equal(t, "bob", r.entries[0].Context["user"])
+	}
+	assert.Equal(t, Redacted, reported["PASSWORD"])
+	assert.Equal(t, "secret", fields["PASSWORD"], "fields passed in shouldn't be modified")
+
+	SetRedactedKeys()
+	out.Reset()
+	SetOutputs(ioutil.Discard, out)
+	l.WithField("password", "secret").Debug("Hello")
+	assert.Regexp(t, `\[password=secret\]`, out.String())
+}
+
+func TestScrubbers(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+	AddScrubber(EmailPattern, "[EMAIL]")
+	AddScrubber(CreditCardPattern, "[CARD]")
+	AddScrubber(IPv4Pattern, "[IP]")
+	defer ResetScrubbers()
+
+	var reported map[string]interface{}
+	RegisterReporter(func(err error, severity Severity, ctx map[string]interface{}) {
+		if ctx["prefix"] == "scrubbing" {
+			reported = ctx
+		}
+	})
+
+	l := LoggerFor("scrubbing")
+	l.SetFormatter(&TextFormatter{})
+	fields := map[string]interface{}{"user": "bob@example.com", "addrs": []string{"10.0.0.1"}, "card": int64(4111111111111111), "count": 3}
+	l.WithFields(fields).Debugf("paid with 4111 1111 1111 1111 from %v", "192.168.1.10")
+	assert.Regexp(t, `^DEBUG scrubbing: redact_test.go:\d+ paid with \[CARD\] from \[IP\] \[addrs=\[\[IP\]\] card=\[CARD\] count=3 user=\[EMAIL\]\]\n$`, out.String())
+	assert.Equal(t, "bob@example.com", fields["user"], "fields passed in shouldn't be modified")
+
+	l.WithFields(fields).Error("failed")
+	assert.Equal(t, "[EMAIL]", reported["user"])
+	assert.Equal(t, "[[IP]]", reported["addrs"])
+	assert.Equal(t, "[CARD]", reported["card"], "numbers should be scrubbed in their decimal form")
+	assert.Equal(t, 3, reported["count"])
+
+	ResetScrubbers()
+	out.Reset()
+	l.Debug("mail bob@example.com")
+	assert.Regexp(t, `mail bob@example.com\n$`, out.String())
+}
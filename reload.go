@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	reloadHooksMx sync.RWMutex
+	reloadHooks   []func() error
+)
+
+// Reopener is implemented by outputs that write to files which can be reopened
+// after being moved by an external tool like logrotate, such as RotatingFile
+// and TimeRotatingFile, and by AsyncWriter and BufferedWriter, which reopen
+// the output they wrap.
+type Reopener interface {
+	// Reopen closes and reopens the underlying file
+	Reopen() error
+}
+
+// OnReload registers a function that is called by Reload after the outputs
+// have been reopened, for example to re-read the application's logging config
+// file and call SetOutputs or SetPrefixLevel accordingly.
+func OnReload(fn func() error) {
+	reloadHooksMx.Lock()
+	reloadHooks = append(reloadHooks, fn)
+	reloadHooksMx.Unlock()
+}
+
+// Reload reopens all outputs that are Reopeners (including the sinks of a
+// Multi) and calls the functions registered with OnReload. All steps are
+// attempted and the first error is returned.
+//
+// Reload doesn't touch the level or Formatter, so settings made at runtime
+// with SetLevel, SetFormatter or the AdminHandler are kept. The environment
+// variables read at startup aren't read again, as a running process doesn't
+// see changes made to them from outside.
+//
+// Use ReloadOnSIGHUP to reload when the process receives SIGHUP, or call
+// Reload directly on platforms without signals.
+func Reload() error {
+	var firstErr error
+	record := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	outs := GetOutputs()
+	record(reopen(outs.ErrorOut))
+	record(reopen(outs.DebugOut))
+
+	reloadHooksMx.RLock()
+	hooks := append([]func() error(nil), reloadHooks...)
+	reloadHooksMx.RUnlock()
+	for _, hook := range hooks {
+		record(hook())
+	}
+	return firstErr
+}
+
+// reopen reopens out if it's a Reopener, or its sinks if it's a Multi
+func reopen(out io.Writer) error {
+	switch o := out.(type) {
+	case Reopener:
+		return o.Reopen()
+	case *Multi:
+		return o.eachSink(reopen)
+	default:
+		return nil
+	}
+}
+
+// ReloadOnSIGHUP calls Reload whenever the process receives SIGHUP, which is
+// what logrotate sends after moving files, until the returned function is
+// called. Errors are written to stderr.
+func ReloadOnSIGHUP() (stop func()) {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := Reload(); err != nil {
+					errorOnLogging(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package golog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	r, err := RotatingFileOutput(path, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	out := MultiOutput(Sink{Out: r, Formatter: &customFormatter{}})
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	reloaded := 0
+	OnReload(func() error {
+		reloaded++
+		return nil
+	})
+	defer os.Unsetenv("GOLOG_LEVEL")
+	defer SetLevel(0)
+	os.Setenv("GOLOG_LEVEL", "info")
+	SetLevel(WARN)
+
+	r.Write([]byte("before\n"))
+	assert.NoError(t, os.Rename(path, path+".moved"))
+	assert.NoError(t, Reload())
+	r.Write([]byte("after\n"))
+	assertContents(t, "before\n", path+".moved")
+	assertContents(t, "after\n", path)
+	assert.EqualValues(t, WARN, GetLevel(), "level set at runtime should be kept")
+	assert.Equal(t, 1, reloaded)
+}
+
+func TestReloadWrapped(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	asyncPath := filepath.Join(dir, "async.log")
+	asyncFile, err := RotatingFileOutput(asyncPath, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer asyncFile.Close()
+	async := AsyncOutput(MultiOutput(Sink{Out: asyncFile, Formatter: &customFormatter{}}), nil)
+	defer async.Close()
+
+	bufferedPath := filepath.Join(dir, "buffered.log")
+	bufferedFile, err := RotatingFileOutput(bufferedPath, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer bufferedFile.Close()
+	buffered := BufferedOutput(bufferedFile, 1024, time.Hour)
+	defer buffered.Close()
+
+	reset := SetOutputs(async, buffered)
+	defer reset()
+
+	async.Write([]byte("before\n"))
+	buffered.Write([]byte("before\n"))
+	assert.NoError(t, os.Rename(asyncPath, asyncPath+".moved"))
+	assert.NoError(t, os.Rename(bufferedPath, bufferedPath+".moved"))
+	assert.NoError(t, Reload())
+	async.Write([]byte("after\n"))
+	buffered.Write([]byte("after\n"))
+	async.Flush()
+	assert.NoError(t, buffered.Flush())
+
+	assertContents(t, "UNKNOWN|||0|before|0\n", asyncPath+".moved")
+	assertContents(t, "UNKNOWN|||0|after|0\n", asyncPath)
+	assertContents(t, "before\n", bufferedPath+".moved")
+	assertContents(t, "after\n", bufferedPath)
+}
+
+func TestReloadOnSIGHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no SIGHUP on windows")
+	}
+	reloaded := make(chan bool, 10)
+	OnReload(func() error {
+		reloaded <- true
+		return nil
+	})
+	stop := ReloadOnSIGHUP()
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, p.Signal(syscall.SIGHUP))
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reloaded on SIGHUP")
+	}
+}
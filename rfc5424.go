@@ -0,0 +1,134 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+	defaultSyslogFacility = 1 // user-level messages
+	defaultSDID           = "golog@32473"
+)
+
+// RFC5424Formatter is a Formatter that writes entries as RFC 5424 syslog
+// messages:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID - [SD-ID caller="file.go:12" key="value"] MSG
+//
+// The APP-NAME is the logger's prefix and context values are written as
+// SD-PARAMs of a single SD-ELEMENT. Stack traces are appended to the MSG on
+// separate lines, which is only safe for datagram transports or when using
+// OctetCounting.
+type RFC5424Formatter struct {
+	// Facility is the syslog facility code, defaults to 1 (user-level messages)
+	// if zero.
+	Facility int
+
+	// Hostname is the reported HOSTNAME, defaults to the hostname of this
+	// machine.
+	Hostname string
+
+	// SDID is the SD-ID of the structured data element holding context values,
+	// defaults to "golog@32473".
+	SDID string
+
+	// OctetCounting prefixes each message with its length as described in
+	// RFC 6587 instead of terminating it with a newline.
+	OctetCounting bool
+}
+
+func (f *RFC5424Formatter) Format(e Entry) []byte {
+	facility := f.Facility
+	if facility == 0 {
+		facility = defaultSyslogFacility
+	}
+	host := f.Hostname
+	if host == "" {
+		host = hostname
+	}
+	sdID := f.SDID
+	if sdID == "" {
+		sdID = defaultSDID
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "<%d>1 %s %s %s %d - [%s",
+		facility*8+syslogSeverity(e.Severity),
+		e.Time.Format(rfc5424TimeFormat),
+		syslogHeaderField(host, 255),
+		syslogHeaderField(e.Prefix, 48),
+		os.Getpid(),
+		sdID)
+	if e.File != "" {
+		buf.WriteString(` caller="`)
+		writeSDParamValue(buf, e.File+":"+strconv.Itoa(e.Line))
+		buf.WriteByte('"')
+	}
+	for _, key := range sortedKeys(e.Context) {
+		buf.WriteByte(' ')
+		buf.WriteString(sdParamName(key))
+		buf.WriteString(`="`)
+		writeSDParamValue(buf, fmt.Sprint(e.Context[key]))
+		buf.WriteByte('"')
+	}
+	buf.WriteString("] ")
+	buf.WriteString(e.Message)
+	for _, line := range e.Stack {
+		buf.WriteByte('\n')
+		buf.WriteString(line)
+	}
+
+	if f.OctetCounting {
+		return append([]byte(strconv.Itoa(buf.Len())+" "), buf.Bytes()...)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// syslogHeaderField makes the given value safe for use as a header field,
+// which must be printable US-ASCII without spaces and may not be empty.
+func syslogHeaderField(value string, maxLen int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	if len(value) > maxLen {
+		return value[:maxLen]
+	}
+	return value
+}
+
+// sdParamName makes the given key safe for use as a PARAM-NAME
+func sdParamName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+	if len(name) > 32 {
+		return name[:32]
+	}
+	return name
+}
+
+// writeSDParamValue writes the given value escaping '"', '\' and ']'
+func writeSDParamValue(buf *bytes.Buffer, value string) {
+	for _, r := range value {
+		switch r {
+		case '"', '\\', ']':
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(r)
+	}
+}
@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRFC5424Formatter(t *testing.T) {
+	ts := time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.UTC)
+	e := Entry{
+		Time:     ts,
+		Severity: ERROR,
+		Prefix:   "my prefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"  at somewhere"},
+		Context:  map[string]interface{}{"cvarA": `a "quoted" [value]`, "cvar=B": 5},
+	}
+
+	f := &RFC5424Formatter{Facility: 16, Hostname: "myhost"}
+	expected := fmt.Sprintf(`<131>1 2019-06-10T15:04:05.123456Z myhost my_prefix %d - [golog@32473 caller="file.go:12" cvar_B="5" cvarA="a \"quoted\" [value\]"] Hello world`+"\n  at somewhere\n", os.Getpid())
+	assert.Equal(t, expected, string(f.Format(e)))
+
+	f = &RFC5424Formatter{Hostname: "myhost", SDID: "ctx@1", OctetCounting: true}
+	e.Severity = DEBUG
+	e.Prefix = ""
+	e.Context = nil
+	e.Stack = nil
+	msg := fmt.Sprintf(`<15>1 2019-06-10T15:04:05.123456Z myhost - %d - [ctx@1 caller="file.go:12"] Hello world`, os.Getpid())
+	assert.Equal(t, fmt.Sprintf("%d %v", len(msg), msg), string(f.Format(e)))
+}
@@ -0,0 +1,78 @@
+package golog
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingBuffer is an output that keeps the most recent entries in memory, for
+// example so that a crash handler or debug endpoint can emit recent history on
+// demand. It is safe for concurrent use.
+type RingBuffer struct {
+	mx      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// NewRingBuffer creates a RingBuffer that keeps the last size entries
+func NewRingBuffer(size int) *RingBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &RingBuffer{entries: make([]Entry, size)}
+}
+
+// Write implements io.Writer, keeping each write as the message of an entry
+// without severity.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	return len(p), r.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (r *RingBuffer) WriteEntry(e Entry) error {
+	r.mx.Lock()
+	r.entries[r.next] = e
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	r.mx.Unlock()
+	return nil
+}
+
+// Entries returns the buffered entries, oldest first
+func (r *RingBuffer) Entries() []Entry {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if !r.full {
+		return append([]Entry(nil), r.entries[:r.next]...)
+	}
+	result := make([]Entry, 0, len(r.entries))
+	result = append(result, r.entries[r.next:]...)
+	return append(result, r.entries[:r.next]...)
+}
+
+// Dump writes the buffered entries to w, oldest first, using the global
+// Formatter.
+func (r *RingBuffer) Dump(w io.Writer) error {
+	f := GetFormatter()
+	for _, e := range r.Entries() {
+		if _, err := w.Write(f.Format(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset discards all buffered entries
+func (r *RingBuffer) Reset() {
+	r.mx.Lock()
+	r.entries = make([]Entry, len(r.entries))
+	r.next = 0
+	r.full = false
+	r.mx.Unlock()
+}
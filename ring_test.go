@@ -0,0 +1,39 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	r := NewRingBuffer(3)
+	SetOutputs(r, r)
+	defer ResetOutputs()
+	SetFormatter(&customFormatter{})
+	defer SetFormatter(nil)
+
+	l := LoggerFor("myprefix")
+	l.Debug("1")
+	l.Debug("2")
+	assert.Len(t, r.Entries(), 2)
+	l.Error("3")
+	l.Debug("4")
+	r.Write([]byte("5\n"))
+
+	entries := r.Entries()
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, "3", entries[0].Message)
+		assert.Equal(t, Severity(ERROR), entries[0].Severity)
+		assert.Equal(t, "4", entries[1].Message)
+		assert.Equal(t, "5", entries[2].Message)
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, r.Dump(buf))
+	assert.Regexp(t, `^ERROR\|myprefix\|ring_test.go\|\d+\|3\|0\nDEBUG\|myprefix\|ring_test.go\|\d+\|4\|0\nUNKNOWN\|\|\|0\|5\|0\n$`, buf.String())
+
+	r.Reset()
+	assert.Empty(t, r.Entries())
+}
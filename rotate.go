@@ -0,0 +1,235 @@
+package golog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// rotateRetryInterval is how long a RotatingFile keeps appending to the
+	// current file after a failed rotation before trying again
+	rotateRetryInterval = time.Minute
+)
+
+var (
+	// renameFile renames the file being rotated, replaceable for testing
+	renameFile = os.Rename
+)
+
+// RotatingFile is an output that writes to a file and rotates it once it
+// reaches a maximum size. Rotated files are renamed to path.1, path.2 etc.
+// (path.1 being the most recent) and optionally compressed with gzip. It is
+// safe for concurrent use and can be used with SetOutputs.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mx       sync.Mutex
+	compress bool
+	file     *os.File
+	size     int64
+	retryAt  time.Time
+	wg       sync.WaitGroup
+}
+
+// RotatingFileOutput opens (or creates) the file at path for appending and
+// returns a RotatingFile that rotates it once it exceeds maxSizeMB megabytes,
+// keeping at most maxBackups rotated files.
+func RotatingFileOutput(path string, maxSizeMB int, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetCompress sets whether or not rotated files are compressed with gzip, in
+// which case they're named path.1.gz, path.2.gz etc.
+func (r *RotatingFile) SetCompress(compress bool) {
+	r.mx.Lock()
+	r.compress = compress
+	r.mx.Unlock()
+}
+
+// Write implements io.Writer, rotating the file first if writing p would
+// exceed the maximum size. If rotating fails, p is appended to the current
+// file anyway and rotation is retried a minute later.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize && !time.Now().Before(r.retryAt) {
+		if err := r.rotate(); err != nil {
+			if r.file == nil {
+				return 0, err
+			}
+			errorOnLogging(err)
+			r.retryAt = time.Now().Add(rotateRetryInterval)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file immediately. If it fails, writes keep being
+// appended to the current file.
+func (r *RotatingFile) Rotate() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.rotate()
+}
+
+// Reopen closes and reopens the file, for use after the file has been moved
+// by an external tool like logrotate.
+func (r *RotatingFile) Reopen() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.file != nil {
+		r.file.Close()
+	}
+	return r.open()
+}
+
+// Close closes the file and waits for any pending compression to finish
+func (r *RotatingFile) Close() error {
+	r.mx.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mx.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	file, fi, err := openAppend(r.path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.size = fi.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if r.file != nil {
+		err := r.file.Close()
+		r.file = nil
+		if err != nil {
+			return r.keepAppending(err)
+		}
+	}
+
+	// Wait for any previous compression to finish before shifting backups
+	r.wg.Wait()
+	if r.maxBackups > 0 {
+		removeBackup(r.backupName(r.maxBackups))
+		for i := r.maxBackups - 1; i > 0; i-- {
+			renameBackup(r.backupName(i), r.backupName(i+1))
+		}
+		first := r.backupName(1)
+		if err := renameFile(r.path, first); err != nil && !os.IsNotExist(err) {
+			return r.keepAppending(err)
+		}
+		if r.compress {
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				if err := compressFile(first); err != nil {
+					errorOnLogging(err)
+				}
+			}()
+		}
+	} else if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return r.keepAppending(err)
+	}
+	return r.open()
+}
+
+// keepAppending reopens the file after a failed rotation, so that writes keep
+// being appended to it, and returns err
+func (r *RotatingFile) keepAppending(err error) error {
+	if openErr := r.open(); openErr != nil {
+		errorOnLogging(openErr)
+	}
+	return err
+}
+
+func (r *RotatingFile) backupName(i int) string {
+	return fmt.Sprintf("%v.%d", r.path, i)
+}
+
+// openAppend opens (or creates) the file at path for appending
+func openAppend(path string) (*os.File, os.FileInfo, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, fi, nil
+}
+
+// removeBackup removes the backup with the given name, whether compressed or
+// not.
+func removeBackup(name string) {
+	os.Remove(name)
+	os.Remove(name + ".gz")
+}
+
+// renameBackup renames the backup with the given name, whether compressed or
+// not.
+func renameBackup(from string, to string) {
+	os.Rename(from, to)
+	os.Rename(from+".gz", to+".gz")
+}
+
+// compressFile gzips the file at path to path.gz and removes the original
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := path + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gzw := gzip.NewWriter(out)
+	_, err = io.Copy(gzw, in)
+	if err == nil {
+		err = gzw.Close()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(path)
+}
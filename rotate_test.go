@@ -0,0 +1,128 @@
+package golog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	r, err := RotatingFileOutput(path, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r.maxSize = 10
+
+	write := func(s string) {
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	write("first\n")
+	write("second\n")
+	write("third\n")
+	write("fourth\n")
+	assert.NoError(t, r.Close())
+
+	assertContents(t, "fourth\n", path)
+	assertContents(t, "third\n", path+".1")
+	assertContents(t, "second\n", path+".2")
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "should only keep 2 backups")
+
+	_, err = r.Write([]byte("closed\n"))
+	assert.Error(t, err, "writing after close should fail")
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	r, err := RotatingFileOutput(path, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	r.maxSize = 10
+
+	defer func() {
+		renameFile = os.Rename
+	}()
+	renameFile = func(from string, to string) error {
+		return errors.New("rename failed")
+	}
+	write := func(s string) {
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	write("first\n")
+	assert.EqualError(t, r.Rotate(), "rename failed")
+	write("second\n")
+	write("third\n")
+	assertContents(t, "first\nsecond\nthird\n", path)
+	assert.False(t, r.retryAt.IsZero(), "rotation should be retried later")
+
+	renameFile = os.Rename
+	r.retryAt = time.Time{}
+	write("fourth\n")
+	assertContents(t, "fourth\n", path)
+	assertContents(t, "first\nsecond\nthird\n", path+".1")
+}
+
+func TestRotatingFileCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("existing\n"), 0644))
+	r, err := RotatingFileOutput(path, 1, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r.SetCompress(true)
+	r.Write([]byte("appended\n"))
+	assert.NoError(t, r.Rotate())
+	r.Write([]byte("rotated\n"))
+	assert.NoError(t, r.Close())
+
+	assertContents(t, "rotated\n", path)
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err), "uncompressed backup should have been removed")
+	f, err := os.Open(path + ".1.gz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+	gzr, err := gzip.NewReader(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, _ := ioutil.ReadAll(gzr)
+	assert.Equal(t, "existing\nappended\n", string(b))
+}
+
+func assertContents(t *testing.T, expected string, path string) {
+	b, err := ioutil.ReadFile(path)
+	if assert.NoError(t, err, path) {
+		assert.Equal(t, expected, string(b), path)
+	}
+}
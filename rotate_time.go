@@ -0,0 +1,258 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotationInterval is how often a TimeRotatingFile rotates
+type RotationInterval int
+
+const (
+	// RotateDaily rotates at local midnight
+	RotateDaily RotationInterval = iota
+
+	// RotateHourly rotates at the top of every hour
+	RotateHourly
+)
+
+// layout returns the default timestamp layout for rotated files
+func (i RotationInterval) layout() string {
+	if i == RotateHourly {
+		return "2006-01-02T15"
+	}
+	return "2006-01-02"
+}
+
+// start returns the start of the interval containing t
+func (i RotationInterval) start(t time.Time) time.Time {
+	hour := 0
+	if i == RotateHourly {
+		hour = t.Hour()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+}
+
+// next returns the start of the interval following the one containing t
+func (i RotationInterval) next(t time.Time) time.Time {
+	start := i.start(t)
+	if i == RotateHourly {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// TimeRotatingFile is an output that writes to a file and rotates it at the
+// end of every day or hour. Rotated files are named by appending the start of
+// their interval, formatted using a timestamp layout, to the path (e.g.
+// app.log.2019-06-10) and optionally compressed with gzip. Rotated files older
+// than a maximum age are removed. It is safe for concurrent use and can be used
+// with SetOutputs.
+type TimeRotatingFile struct {
+	path     string
+	layout   string
+	interval RotationInterval
+	maxAge   time.Duration
+	now      func() time.Time
+
+	mx       sync.Mutex
+	compress bool
+	file     *os.File
+	started  time.Time
+	next     time.Time
+	wg       sync.WaitGroup
+}
+
+// TimeRotatingFileOutput opens (or creates) the file at path for appending and
+// returns a TimeRotatingFile that rotates it every interval. Rotated files are
+// suffixed with the interval's start time formatted using layout, which
+// defaults to 2006-01-02 for daily and 2006-01-02T15 for hourly rotation if
+// empty. If maxAge is greater than 0, rotated files older than maxAge are
+// removed.
+func TimeRotatingFileOutput(path string, layout string, interval RotationInterval, maxAge time.Duration) (*TimeRotatingFile, error) {
+	if layout == "" {
+		layout = interval.layout()
+	}
+	r := &TimeRotatingFile{
+		path:     path,
+		layout:   layout,
+		interval: interval,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetCompress sets whether or not rotated files are compressed with gzip, in
+// which case they're suffixed with .gz.
+func (r *TimeRotatingFile) SetCompress(compress bool) {
+	r.mx.Lock()
+	r.compress = compress
+	r.mx.Unlock()
+}
+
+// Write implements io.Writer, rotating the file first if the current interval
+// has ended. If rotating fails, p is appended to the current file anyway and
+// rotation is retried a minute later.
+func (r *TimeRotatingFile) Write(p []byte) (int, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if !r.now().Before(r.next) {
+		if err := r.rotate(); err != nil {
+			if r.file == nil {
+				return 0, err
+			}
+			errorOnLogging(err)
+		}
+	}
+	return r.file.Write(p)
+}
+
+// Rotate rotates the file immediately. If it fails, writes keep being
+// appended to the current file.
+func (r *TimeRotatingFile) Rotate() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.rotate()
+}
+
+// Reopen closes and reopens the file, for use after the file has been moved
+// by an external tool like logrotate.
+func (r *TimeRotatingFile) Reopen() error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if r.file != nil {
+		r.file.Close()
+	}
+	return r.open()
+}
+
+// Close closes the file and waits for any pending compression to finish
+func (r *TimeRotatingFile) Close() error {
+	r.mx.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mx.Unlock()
+	r.wg.Wait()
+	return err
+}
+
+func (r *TimeRotatingFile) open() error {
+	file, fi, err := openAppend(r.path)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	now := r.now()
+	r.started = r.interval.start(now)
+	r.next = r.interval.next(now)
+	if fi.Size() > 0 && fi.ModTime().Before(r.started) {
+		// The existing file belongs to an earlier interval, rotate it out
+		r.started = r.interval.start(fi.ModTime())
+		r.next = now
+	}
+	return nil
+}
+
+func (r *TimeRotatingFile) rotate() error {
+	if r.file != nil {
+		err := r.file.Close()
+		r.file = nil
+		if err != nil {
+			return r.keepAppending(err)
+		}
+	}
+
+	name := r.backupName()
+	if err := renameFile(r.path, name); err != nil && !os.IsNotExist(err) {
+		return r.keepAppending(err)
+	}
+	// Wait for any previous compression to finish before pruning
+	r.wg.Wait()
+	r.prune()
+	if r.compress {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			if err := compressFile(name); err != nil {
+				errorOnLogging(err)
+			}
+		}()
+	}
+	return r.open()
+}
+
+// keepAppending reopens the file after a failed rotation, so that writes keep
+// being appended to it until rotation is retried, and returns err
+func (r *TimeRotatingFile) keepAppending(err error) error {
+	started, next := r.started, r.next
+	if openErr := r.open(); openErr != nil {
+		errorOnLogging(openErr)
+		return err
+	}
+	// The file still holds the entries of the interval it was opened in
+	r.started, r.next = started, next
+	if now := r.now(); !now.Before(r.next) {
+		r.next = now.Add(rotateRetryInterval)
+	}
+	return err
+}
+
+// backupName returns an unused name for the file rotated out of the current
+// interval.
+func (r *TimeRotatingFile) backupName() string {
+	base := r.path + "." + r.started.Format(r.layout)
+	name := base
+	for i := 1; fileExists(name) || fileExists(name+".gz"); i++ {
+		name = base + "." + strconv.Itoa(i)
+	}
+	return name
+}
+
+// prune removes rotated files older than maxAge
+func (r *TimeRotatingFile) prune() {
+	if r.maxAge <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return
+	}
+	cutoff := r.now().Add(-r.maxAge)
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(match, r.path+"."), ".gz")
+		if idx := strings.LastIndex(suffix, "."); idx > 0 {
+			// Strip counter added to avoid collisions
+			if _, err := time.ParseInLocation(r.layout, suffix, r.started.Location()); err != nil {
+				suffix = suffix[:idx]
+			}
+		}
+		ts, err := time.ParseInLocation(r.layout, suffix, r.started.Location())
+		if err != nil {
+			// Not one of ours
+			continue
+		}
+		if r.interval.next(ts).Before(cutoff) {
+			os.Remove(match)
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,121 @@
+package golog
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	stale := path + ".2019-05-01"
+	assert.NoError(t, ioutil.WriteFile(stale, []byte("stale\n"), 0644))
+	unrelated := path + ".bak"
+	assert.NoError(t, ioutil.WriteFile(unrelated, []byte("unrelated\n"), 0644))
+
+	r, err := TimeRotatingFileOutput(path, "", RotateDaily, 7*24*time.Hour)
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Date(2019, 6, 10, 23, 59, 0, 0, time.Local)
+	r.now = func() time.Time { return now }
+	assert.NoError(t, r.Reopen())
+
+	write := func(s string) {
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	write("first\n")
+	write("second\n")
+	now = now.Add(time.Minute)
+	write("third\n")
+	now = now.Add(time.Hour)
+	write("fourth\n")
+	assert.NoError(t, r.Close())
+
+	assertContents(t, "third\nfourth\n", path)
+	assertContents(t, "first\nsecond\n", path+".2019-06-10")
+	assertContents(t, "unrelated\n", unrelated)
+	_, err = os.Stat(stale)
+	assert.True(t, os.IsNotExist(err), "stale file should have been pruned")
+}
+
+func TestTimeRotatingFileRenameFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	r, err := TimeRotatingFileOutput(path, "", RotateDaily, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	now := time.Date(2019, 6, 10, 23, 59, 0, 0, time.Local)
+	r.now = func() time.Time { return now }
+	assert.NoError(t, r.Reopen())
+
+	defer func() {
+		renameFile = os.Rename
+	}()
+	renameFile = func(from string, to string) error {
+		return errors.New("rename failed")
+	}
+	write := func(s string) {
+		_, err := r.Write([]byte(s))
+		assert.NoError(t, err)
+	}
+	write("first\n")
+	now = now.Add(time.Minute)
+	write("second\n")
+	assert.EqualError(t, r.Rotate(), "rename failed")
+	write("third\n")
+	assertContents(t, "first\nsecond\nthird\n", path)
+
+	renameFile = os.Rename
+	now = now.Add(rotateRetryInterval)
+	write("fourth\n")
+	assertContents(t, "fourth\n", path)
+	assertContents(t, "first\nsecond\nthird\n", path+".2019-06-10")
+}
+
+func TestTimeRotatingFileHourly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.log")
+	r, err := TimeRotatingFileOutput(path, "", RotateHourly, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+	now := time.Date(2019, 6, 10, 13, 30, 0, 0, time.Local)
+	r.now = func() time.Time { return now }
+	assert.NoError(t, r.Reopen())
+
+	r.Write([]byte("first\n"))
+	assert.NoError(t, r.Rotate())
+	r.Write([]byte("second\n"))
+	now = now.Add(30 * time.Minute)
+	r.Write([]byte("third\n"))
+	assert.NoError(t, r.Close())
+
+	assertContents(t, "third\n", path)
+	assertContents(t, "first\n", path+".2019-06-10T13")
+	assertContents(t, "second\n", path+".2019-06-10T13.1")
+}
@@ -0,0 +1,155 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingRule selects entries to sample
+type SamplingRule struct {
+	// Prefix selects entries with exactly this prefix, or all entries if empty
+	Prefix string
+
+	// MaxSeverity selects entries up to and including this severity, or all
+	// entries if 0. For example, DEBUG samples DEBUG and TRACE entries.
+	MaxSeverity Severity
+
+	// N is the sampling rate, only every Nth entry is written
+	N int
+}
+
+func (r *SamplingRule) matches(e Entry) bool {
+	return (r.Prefix == "" || r.Prefix == e.Prefix) && (r.MaxSeverity == 0 || e.Severity <= r.MaxSeverity)
+}
+
+// Sampler is an output that only writes every Nth of similar entries selected
+// by its rules, to keep hot loops from flooding the underlying output. Entries
+// are similar if they have the same prefix and severity and were logged from
+// the same file and line. The first of similar entries is always written, and
+// after an interval in which entries were skipped, a summary like
+// "sampled 99 similar messages" is written with the prefix, severity and
+// caller of the skipped entries. Entries not selected by any rule are written
+// as is.
+type Sampler struct {
+	out      Sink
+	rules    []SamplingRule
+	interval time.Duration
+
+	mx     sync.Mutex
+	counts map[samplingKey]*samplingCount
+	timer  *time.Timer
+}
+
+type samplingKey struct {
+	prefix   string
+	severity Severity
+	file     string
+	line     int
+}
+
+type samplingCount struct {
+	seen      int
+	skipped   int
+	formatter Formatter
+}
+
+// SamplingOutput creates a Sampler that writes to out using the first rule
+// that matches each entry, writing summaries of skipped entries once per
+// interval. If out isn't an EntryWriter, entries are formatted with the
+// Formatter of the logger that logged them. For example, to only write every
+// 100th DEBUG entry:
+//
+//	out := golog.SamplingOutput(os.Stdout, time.Minute, golog.SamplingRule{MaxSeverity: golog.DEBUG, N: 100})
+func SamplingOutput(out io.Writer, interval time.Duration, rules ...SamplingRule) *Sampler {
+	return &Sampler{
+		out:      Sink{Out: out},
+		rules:    append([]SamplingRule(nil), rules...),
+		interval: interval,
+		counts:   make(map[samplingKey]*samplingCount),
+	}
+}
+
+// Write implements io.Writer, writing p as the message of an entry without
+// severity.
+func (s *Sampler) Write(p []byte) (int, error) {
+	return len(p), s.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (s *Sampler) WriteEntry(e Entry) error {
+	if !s.sample(e) {
+		return nil
+	}
+	return s.out.write(e)
+}
+
+// sample indicates whether or not e should be written
+func (s *Sampler) sample(e Entry) bool {
+	for _, rule := range s.rules {
+		if !rule.matches(e) {
+			continue
+		}
+		if rule.N <= 1 {
+			return true
+		}
+		key := samplingKey{e.Prefix, e.Severity, e.File, e.Line}
+		s.mx.Lock()
+		defer s.mx.Unlock()
+		count := s.counts[key]
+		if count == nil {
+			count = &samplingCount{}
+			s.counts[key] = count
+		}
+		count.seen++
+		if (count.seen-1)%rule.N == 0 {
+			return true
+		}
+		count.skipped++
+		count.formatter = e.formatter
+		if s.timer == nil {
+			s.timer = time.AfterFunc(s.interval, s.summarize)
+		}
+		return false
+	}
+	return true
+}
+
+// Close writes summaries of entries skipped since the last summary
+func (s *Sampler) Close() error {
+	s.summarize()
+	return nil
+}
+
+// summarize writes summaries of skipped entries and resets the counts
+func (s *Sampler) summarize() {
+	s.mx.Lock()
+	counts := s.counts
+	s.counts = make(map[samplingKey]*samplingCount)
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mx.Unlock()
+
+	now := time.Now()
+	for key, count := range counts {
+		if count.skipped == 0 {
+			continue
+		}
+		err := s.out.write(Entry{
+			Time:      now,
+			Severity:  key.severity,
+			Prefix:    key.prefix,
+			File:      key.file,
+			Line:      key.line,
+			Message:   fmt.Sprintf("sampled %d similar messages", count.skipped),
+			formatter: count.formatter,
+		})
+		if err != nil {
+			errorOnLogging(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	ring := NewRingBuffer(100)
+	s := SamplingOutput(ring, time.Hour,
+		SamplingRule{Prefix: "quiet", N: 1},
+		SamplingRule{MaxSeverity: DEBUG, N: 3})
+
+	for i := 0; i < 7; i++ {
+		s.WriteEntry(Entry{Severity: DEBUG, Prefix: "hot", File: "file.go", Line: 1, Message: "loop"})
+		s.WriteEntry(Entry{Severity: DEBUG, Prefix: "quiet", File: "file.go", Line: 2, Message: "quiet"})
+		s.WriteEntry(Entry{Severity: ERROR, Prefix: "hot", File: "file.go", Line: 3, Message: "error"})
+	}
+	var messages []string
+	for _, e := range ring.Entries() {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, 3, countOf(messages, "loop"), "should write the 1st, 4th and 7th")
+	assert.Equal(t, 7, countOf(messages, "quiet"))
+	assert.Equal(t, 7, countOf(messages, "error"))
+
+	assert.NoError(t, s.Close())
+	entries := ring.Entries()
+	last := entries[len(entries)-1]
+	assert.Equal(t, "sampled 4 similar messages", last.Message)
+	assert.Equal(t, Severity(DEBUG), last.Severity)
+	assert.Equal(t, "hot", last.Prefix)
+	assert.Equal(t, 1, last.Line)
+
+	s.Close()
+	assert.Len(t, ring.Entries(), len(entries), "nothing should have been skipped since the last summary")
+}
+
+func TestSamplerInterval(t *testing.T) {
+	ring := NewRingBuffer(100)
+	s := SamplingOutput(ring, 50*time.Millisecond, SamplingRule{N: 10})
+	for i := 0; i < 5; i++ {
+		s.WriteEntry(Entry{Message: "loop"})
+	}
+	time.Sleep(200 * time.Millisecond)
+	entries := ring.Entries()
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "sampled 4 similar messages", entries[1].Message)
+	}
+}
+
+func countOf(values []string, value string) int {
+	n := 0
+	for _, v := range values {
+		if v == value {
+			n++
+		}
+	}
+	return n
+}
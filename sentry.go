@@ -0,0 +1,242 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getlantern/hidden"
+)
+
+var (
+	stackFrameRegex = regexp.MustCompile(`^\s*at (.+) \((.+):(\d+)\)$`)
+)
+
+// SentryOptions configures a SentryReporter
+type SentryOptions struct {
+	// Release is the release (version) of the application
+	Release string
+
+	// Environment is the environment the application runs in, e.g. production
+	Environment string
+
+	// ServerName is the reported server name, defaults to the hostname of this
+	// machine.
+	ServerName string
+
+	// QueueSize is the maximum number of events waiting to be sent, defaults to
+	// 100. Errors reported while the queue is full are dropped.
+	QueueSize int
+
+	// Client is the http.Client used for sending, defaults to a client with a
+	// 30 second timeout.
+	Client *http.Client
+}
+
+// SentryReporter reports errors to Sentry. Register it with
+// RegisterReporter(reporter.Report).
+//
+// Each error becomes an event with an exception whose stack trace is taken
+// from the error's MultiLine output (as produced by getlantern/errors) and
+// whose context is sent as extra data. Events are fingerprinted with the
+// error_fingerprint context value, or the error's Fingerprint if there is
+// none, so that Sentry groups them like golog's other reporters do. Events are
+// sent on a background goroutine. Warnings aren't reported, only errors and
+// fatal errors.
+type SentryReporter struct {
+	endpoint string
+	opts     SentryOptions
+	http     HTTPOptions
+	events   chan []byte
+	wg       sync.WaitGroup
+	mx       sync.RWMutex
+	closed   bool
+}
+
+// NewSentryReporter creates a SentryReporter for the given DSN, for example
+// "https://public@sentry.example.com/1". opts may be nil.
+func NewSentryReporter(dsn string, opts *SentryOptions) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("sentry DSN %v is missing public key", dsn)
+	}
+	idx := strings.LastIndex(u.Path, "/")
+	projectID := u.Path[idx+1:]
+	if projectID == "" {
+		return nil, fmt.Errorf("sentry DSN %v is missing project ID", dsn)
+	}
+
+	s := &SentryReporter{
+		endpoint: fmt.Sprintf("%v://%v%v/api/%v/store/", u.Scheme, u.Host, u.Path[:idx], projectID),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.ServerName == "" {
+		s.opts.ServerName = hostname
+	}
+	if s.opts.QueueSize <= 0 {
+		s.opts.QueueSize = 100
+	}
+	auth := "Sentry sentry_version=7, sentry_client=golog/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	s.http = HTTPOptions{
+		Headers: map[string]string{"X-Sentry-Auth": auth},
+		Client:  s.opts.Client,
+	}
+	s.http.applyDefaults()
+	s.events = make(chan []byte, s.opts.QueueSize)
+	s.wg.Add(1)
+	go s.send()
+	return s, nil
+}
+
+// Report implements ErrorReporter
+func (s *SentryReporter) Report(err error, severity Severity, ctx map[string]interface{}) {
+	if severity < ERROR {
+		return
+	}
+	event := s.encode(err, severity, ctx)
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		// queue full, drop event
+	}
+}
+
+// Close sends all queued events and stops the reporter
+func (s *SentryReporter) Close() error {
+	s.mx.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mx.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func (s *SentryReporter) send() {
+	defer s.wg.Done()
+	for event := range s.events {
+		if err := postWithRetry(s.endpoint, "application/json", event, &s.http); err != nil {
+			errorOnLogging(err)
+		}
+	}
+}
+
+// encode encodes the given error as a Sentry event
+func (s *SentryReporter) encode(err error, severity Severity, ctx map[string]interface{}) []byte {
+	message := hidden.Clean(err.Error())
+	var stack []string
+	if ml, ok := err.(MultiLine); ok {
+		var buf bytes.Buffer
+		mlp := ml.MultiLinePrinter()
+		for first := true; ; first = false {
+			more := mlp(&buf)
+			if first {
+				message = hidden.Clean(buf.String())
+			} else {
+				stack = append(stack, hidden.Clean(buf.String()))
+			}
+			buf.Reset()
+			if !more {
+				break
+			}
+		}
+	}
+
+	errorType := fmt.Sprintf("%T", err)
+	if t, ok := ctx["error_type"].(string); ok && t != "" {
+		errorType = t
+	}
+	fingerprint, _ := ctx["error_fingerprint"].(string)
+	if fingerprint == "" {
+		fingerprint = Fingerprint(err)
+	}
+
+	level := "error"
+	if severity >= FATAL {
+		level = "fatal"
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"event_id":"`)
+	buf.WriteString(newEventID())
+	buf.WriteString(`","timestamp":`)
+	writeJSONString(buf, time.Now().UTC().Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":"`)
+	buf.WriteString(level)
+	buf.WriteString(`","logger":"golog","platform":"go","server_name":`)
+	writeJSONString(buf, s.opts.ServerName)
+	if s.opts.Release != "" {
+		buf.WriteString(`,"release":`)
+		writeJSONString(buf, s.opts.Release)
+	}
+	if s.opts.Environment != "" {
+		buf.WriteString(`,"environment":`)
+		writeJSONString(buf, s.opts.Environment)
+	}
+	buf.WriteString(`,"fingerprint":[`)
+	writeJSONString(buf, fingerprint)
+	buf.WriteString(`],"exception":{"values":[{"type":`)
+	writeJSONString(buf, errorType)
+	buf.WriteString(`,"value":`)
+	writeJSONString(buf, message)
+	if frames := sentryFrames(stack); len(frames) > 0 {
+		buf.WriteString(`,"stacktrace":{"frames":[`)
+		buf.WriteString(strings.Join(frames, ","))
+		buf.WriteString(`]}`)
+	}
+	buf.WriteString(`}]},"extra":`)
+	writeJSONObject(buf, ctx)
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// sentryFrames parses stack lines of the form "at function (file:line)" into
+// JSON encoded Sentry frames, which are ordered oldest call first.
+func sentryFrames(stack []string) []string {
+	var frames []string
+	for i := len(stack) - 1; i >= 0; i-- {
+		match := stackFrameRegex.FindStringSubmatch(stack[i])
+		if match == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(match[3])
+		buf := &bytes.Buffer{}
+		buf.WriteString(`{"function":`)
+		writeJSONString(buf, match[1])
+		buf.WriteString(`,"filename":`)
+		writeJSONString(buf, match[2])
+		buf.WriteString(`,"lineno":`)
+		buf.WriteString(strconv.Itoa(line))
+		buf.WriteByte('}')
+		frames = append(frames, buf.String())
+	}
+	return frames
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
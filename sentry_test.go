@@ -0,0 +1,70 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getlantern/errors"
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentryReporter(t *testing.T) {
+	events := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/sentry/api/42/store/", req.URL.Path)
+		assert.Equal(t, "Sentry sentry_version=7, sentry_client=golog/1.0, sentry_key=public", req.Header.Get("X-Sentry-Auth"))
+		var event map[string]interface{}
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&event))
+		events <- event
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "http://", "http://public@", 1) + "/sentry/42"
+	s, err := NewSentryReporter(dsn, &SentryOptions{Release: "1.0.0", Environment: "test", ServerName: "myhost"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	op := ops.Begin("name").Set("cvarA", "a")
+	err = errors.New("unable to dial %v", "www.google.com")
+	op.End()
+	ctx := ops.AsMap(err, true)
+	ctx["error_fingerprint"] = "myfingerprint"
+	s.Report(err, WARN, ctx)
+	s.Report(err, FATAL, ctx)
+	s.Report(err, ERROR, ops.AsMap(err, true))
+	assert.NoError(t, s.Close())
+
+	assert.Len(t, events, 2, "warnings should not be reported")
+	event := <-events
+	assert.Len(t, event["event_id"], 32)
+	assert.Equal(t, "fatal", event["level"])
+	assert.Equal(t, "1.0.0", event["release"])
+	assert.Equal(t, "test", event["environment"])
+	assert.Equal(t, "myhost", event["server_name"])
+	assert.Equal(t, []interface{}{"myfingerprint"}, event["fingerprint"])
+	exception := event["exception"].(map[string]interface{})["values"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "unable to dial www.google.com", exception["value"])
+	frames := exception["stacktrace"].(map[string]interface{})["frames"].([]interface{})
+	last := frames[len(frames)-1].(map[string]interface{})
+	assert.Equal(t, "sentry_test.go", last["filename"])
+	assert.Equal(t, "github.com/getlantern/golog.TestSentryReporter", last["function"])
+	assert.Equal(t, "a", event["extra"].(map[string]interface{})["cvarA"])
+
+	event = <-events
+	assert.Equal(t, []interface{}{Fingerprint(err)}, event["fingerprint"], "should fall back to the error's Fingerprint")
+
+	s.Report(err, ERROR, nil)
+	assert.Empty(t, events, "events reported after closing should be dropped")
+}
+
+func TestSentryReporterInvalidDSN(t *testing.T) {
+	_, err := NewSentryReporter("https://sentry.example.com/1", nil)
+	assert.Error(t, err)
+	_, err = NewSentryReporter("https://public@sentry.example.com/", nil)
+	assert.Error(t, err)
+}
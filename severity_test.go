@@ -0,0 +1,42 @@
+package golog
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityLabels(t *testing.T) {
+	labels := map[Severity]string{ERROR: "WARNING", DEBUG: "dbg"}
+	SetSeverityLabels(labels)
+	defer SetSeverityLabels(nil)
+	labels[ERROR] = "changed after setting"
+
+	assert.Equal(t, "WARNING", Severity(ERROR).String())
+	assert.Equal(t, "dbg", Severity(DEBUG).String())
+	assert.Equal(t, "FATAL", Severity(FATAL).String())
+
+	out := newBuffer()
+	SetOutputs(out, ioutil.Discard)
+	LoggerFor("myprefix").Error("Hello world")
+	assert.Equal(t, "WARNING myprefix: severity_test.go:999 Hello world\n", out.String())
+
+	assert.Contains(t, string((&JSONFormatter{}).Format(Entry{Severity: DEBUG})), `"severity":"dbg"`)
+	assert.Contains(t, string((&DevFormatter{}).Format(Entry{Severity: ERROR})), " WARNING ")
+
+	SetSeverityLabels(nil)
+	assert.Equal(t, "ERROR", Severity(ERROR).String())
+	assert.Equal(t, "UNKNOWN", Severity(0).String())
+}
+
+func TestParseSeverity(t *testing.T) {
+	for label, expected := range map[string]Severity{"trace": TRACE, "DEBUG": DEBUG, "info": INFO, "Warn": WARN, "Error": ERROR, "fatal": FATAL, "250": 250} {
+		s, err := ParseSeverity(label)
+		if assert.NoError(t, err, label) {
+			assert.Equal(t, expected, s, label)
+		}
+	}
+	_, err := ParseSeverity("bogus")
+	assert.Error(t, err)
+}
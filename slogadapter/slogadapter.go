@@ -0,0 +1,142 @@
+//go:build go1.21
+// +build go1.21
+
+// Package slogadapter provides a slog.Handler that logs through golog, so
+// that code using log/slog as its front-end gets golog's formatting, outputs,
+// severity levels, ops context and ErrorReporters. For example:
+//
+//	slog.SetDefault(slog.New(slogadapter.NewHandler(golog.LoggerFor("myapp"))))
+//
+// Levels are mapped to the nearest golog Severity at or below them, so
+// slog.LevelDebug is DEBUG, slog.LevelInfo is INFO, slog.LevelWarn is WARN
+// and slog.LevelError and above are ERROR, with levels below slog.LevelDebug
+// being TRACE. Records are never logged at FATAL, so they don't exit the
+// process.
+//
+// Attributes become context values, with the keys of groups joined with dots,
+// e.g. "request.method". Error values under the key "err" or "error" are
+// attached with Logger.WithError, so their causes are logged too.
+package slogadapter
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+
+	"github.com/getlantern/golog"
+)
+
+// Handler is a slog.Handler that logs records to a golog.Logger
+type Handler struct {
+	l     golog.Logger
+	group string
+}
+
+// NewHandler creates a Handler that logs to l
+func NewHandler(l golog.Logger) *Handler {
+	return &Handler{l: l}
+}
+
+// Severity returns the golog Severity at which records of the given level
+// are logged
+func Severity(level slog.Level) golog.Severity {
+	switch {
+	case level >= slog.LevelError:
+		return golog.ERROR
+	case level >= slog.LevelWarn:
+		return golog.WARN
+	case level >= slog.LevelInfo:
+		return golog.INFO
+	case level >= slog.LevelDebug:
+		return golog.DEBUG
+	default:
+		return golog.TRACE
+	}
+}
+
+// Enabled implements slog.Handler
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	severity := Severity(level)
+	// errors are reported even when they aren't written
+	return severity >= golog.ERROR || h.l.IsEnabled(severity)
+}
+
+// Handle implements slog.Handler
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	l := h.l
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		l = l.WithCaller(filepath.Base(frame.File), frame.Line)
+	} else {
+		l = l.WithoutCaller()
+	}
+	if r.NumAttrs() > 0 {
+		fields := make(map[string]interface{}, r.NumAttrs())
+		var err error
+		r.Attrs(func(a slog.Attr) bool {
+			if e, ok := a.Value.Any().(error); ok && h.group == "" && (a.Key == "err" || a.Key == "error") {
+				err = e
+				return true
+			}
+			addAttr(fields, h.group, a)
+			return true
+		})
+		if err != nil {
+			l = l.WithError(err)
+		}
+		l = l.WithFields(fields)
+	}
+	switch Severity(r.Level) {
+	case golog.TRACE:
+		l.TraceCtx(ctx, r.Message)
+	case golog.DEBUG:
+		l.DebugCtx(ctx, r.Message)
+	case golog.INFO:
+		l.InfoCtx(ctx, r.Message)
+	case golog.WARN:
+		l.WarnCtx(ctx, r.Message)
+	default:
+		l.ErrorCtx(ctx, r.Message)
+	}
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	fields := make(map[string]interface{}, len(attrs))
+	for _, a := range attrs {
+		addAttr(fields, h.group, a)
+	}
+	return &Handler{l: h.l.WithFields(fields), group: h.group}
+}
+
+// WithGroup implements slog.Handler
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{l: h.l, group: h.group + name + "."}
+}
+
+// addAttr adds a to fields under its key prefixed with group, flattening
+// groups and dropping empty attributes as slog.Handler requires
+func addAttr(fields map[string]interface{}, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(fields, group, ga)
+		}
+		return
+	}
+	fields[group+a.Key] = a.Value.Any()
+}
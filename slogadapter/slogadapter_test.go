@@ -0,0 +1,50 @@
+//go:build go1.21
+// +build go1.21
+
+package slogadapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+	reset := golog.SetOutputs(buf, buf)
+	defer reset()
+	var reported []error
+	golog.RegisterReporter(func(err error, severity golog.Severity, ctx map[string]interface{}) {
+		if ctx["prefix"] == "slogadapter" && severity == golog.ERROR {
+			reported = append(reported, err)
+		}
+	})
+
+	log := slog.New(NewHandler(golog.LoggerFor("slogadapter")))
+	log.Debug("debug", "a", 1)
+	log.With("b", 2).WithGroup("request").Info("info", slog.String("method", "GET"), slog.Group("client", "ip", "10.0.0.1"))
+	log.Warn("warn", slog.Attr{}, slog.Group("empty"))
+	log.Error("failed", "err", errors.New("boom"))
+	log.Log(context.Background(), slog.LevelDebug-4, "trace")
+
+	assert.Regexp(t, `^DEBUG slogadapter: slogadapter_test.go:\d+ debug \[a=1\]
+INFO slogadapter: slogadapter_test.go:\d+ info \[b=2 request.client.ip=10.0.0.1 request.method=GET\]
+WARN slogadapter: slogadapter_test.go:\d+ warn
+ERROR slogadapter: slogadapter_test.go:\d+ failed \[error=boom error_type=errors.errorString\]
+ERROR slogadapter: slogadapter_test.go:\d+ Caused by: boom
+$`, buf.String())
+	assert.Len(t, reported, 1)
+}
+
+func TestSeverity(t *testing.T) {
+	assert.EqualValues(t, golog.TRACE, Severity(slog.LevelDebug-1))
+	assert.EqualValues(t, golog.DEBUG, Severity(slog.LevelDebug))
+	assert.EqualValues(t, golog.INFO, Severity(slog.LevelInfo+1))
+	assert.EqualValues(t, golog.WARN, Severity(slog.LevelWarn))
+	assert.EqualValues(t, golog.ERROR, Severity(slog.LevelError+4))
+}
@@ -0,0 +1,60 @@
+package golog
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	spanRecorderMx sync.RWMutex
+	spanRecorder   SpanRecorder
+)
+
+// SpanLookup looks up the active span in a context.Context, returning its
+// hex-encoded trace and span IDs, or false if there is no valid span. golog
+// doesn't depend on a tracing library, so this is what ties it to one. For
+// OpenTelemetry, use oteladapter.SpanLookup.
+type SpanLookup func(ctx context.Context) (traceID string, spanID string, ok bool)
+
+// SpanRecorder records an ERROR or FATAL logged with ErrorCtx or FatalCtx on
+// the active span in ctx, if there is one. With OpenTelemetry for example:
+//
+//	func(ctx context.Context, err error, severity golog.Severity) {
+//		trace.SpanFromContext(ctx).RecordError(err, trace.WithAttributes(attribute.String("severity", severity.String())))
+//	}
+type SpanRecorder func(ctx context.Context, err error, severity Severity)
+
+// SpanExtractor returns a ContextExtractor that includes the IDs of the active
+// span as the trace_id and span_id fields, which outputs like the
+// CloudLoggingFormatter and OTLPExporter use to correlate entries with traces.
+// Register it with RegisterContextExtractor.
+func SpanExtractor(lookup SpanLookup) ContextExtractor {
+	return func(ctx context.Context) map[string]interface{} {
+		traceID, spanID, ok := lookup(ctx)
+		if !ok {
+			return nil
+		}
+		return map[string]interface{}{"trace_id": traceID, "span_id": spanID}
+	}
+}
+
+// RecordSpanErrors sets the SpanRecorder that ERRORs and FATALs logged with
+// ErrorCtx and FatalCtx are recorded with, or disables recording if recorder
+// is nil.
+func RecordSpanErrors(recorder SpanRecorder) {
+	spanRecorderMx.Lock()
+	spanRecorder = recorder
+	spanRecorderMx.Unlock()
+}
+
+func recordSpanError(ctx context.Context, err error, severity Severity) {
+	if ctx == nil || err == nil {
+		return
+	}
+	spanRecorderMx.RLock()
+	recorder := spanRecorder
+	spanRecorderMx.RUnlock()
+	if recorder != nil {
+		recorder(ctx, err, severity)
+	}
+}
@@ -0,0 +1,54 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpan struct {
+	traceID string
+	spanID  string
+	errors  []string
+}
+
+type testSpanKey struct{}
+
+func TestSpans(t *testing.T) {
+	debugOut := &bytes.Buffer{}
+	errorOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	RegisterContextExtractor(SpanExtractor(func(ctx context.Context) (string, string, bool) {
+		span, ok := ctx.Value(testSpanKey{}).(*testSpan)
+		if !ok {
+			return "", "", false
+		}
+		return span.traceID, span.spanID, true
+	}))
+	RecordSpanErrors(func(ctx context.Context, err error, severity Severity) {
+		if span, ok := ctx.Value(testSpanKey{}).(*testSpan); ok {
+			span.errors = append(span.errors, severity.String()+" "+err.Error())
+		}
+	})
+	defer RecordSpanErrors(nil)
+
+	span := &testSpan{traceID: "4bf92f3577b34da6a3ce929d0e0e4736", spanID: "00f067aa0ba902b7"}
+	ctx := context.WithValue(context.Background(), testSpanKey{}, span)
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	l.DebugCtx(ctx, "Hello")
+	l.DebugCtx(context.Background(), "world")
+	assert.Regexp(t, `^DEBUG myprefix: span_test.go:\d+ Hello \[span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736\]
+DEBUG myprefix: span_test.go:\d+ world
+$`, debugOut.String())
+
+	l.WarnCtx(ctx, "careful")
+	l.Error("no span")
+	l.ErrorCtx(ctx, "failed")
+	assert.Equal(t, []string{"ERROR failed"}, span.errors)
+	assert.Regexp(t, `ERROR myprefix: span_test.go:\d+ failed \[span_id=00f067aa0ba902b7 trace_id=4bf92f3577b34da6a3ce929d0e0e4736\]\n$`, errorOut.String())
+}
@@ -0,0 +1,194 @@
+package golog
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	sqliteSchema = `CREATE TABLE IF NOT EXISTS %[1]s (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	time INTEGER NOT NULL,
+	severity INTEGER NOT NULL,
+	prefix TEXT NOT NULL,
+	file TEXT NOT NULL,
+	line INTEGER NOT NULL,
+	message TEXT NOT NULL,
+	stack TEXT NOT NULL,
+	context TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS %[1]s_time ON %[1]s (time);
+CREATE INDEX IF NOT EXISTS %[1]s_severity ON %[1]s (severity, time);
+CREATE INDEX IF NOT EXISTS %[1]s_prefix ON %[1]s (prefix, time);`
+
+	sqliteColumns = "time, severity, prefix, file, line, message, stack, context"
+)
+
+// SQLiteStore is an output that stores entries in a SQLite database, with
+// indexes on time, severity and prefix, so that applications can query their
+// local log history. It works with any SQLite driver for database/sql, which
+// the application needs to import itself.
+//
+// Entries are inserted in batches on a background goroutine.
+type SQLiteStore struct {
+	*batcher
+	db    *sql.DB
+	table string
+}
+
+// StoreQuery selects stored entries. Zero values don't restrict the results.
+type StoreQuery struct {
+	// Since and Until restrict the results to entries logged in [Since, Until)
+	Since time.Time
+	Until time.Time
+
+	// MinSeverity is the minimum severity of entries
+	MinSeverity Severity
+
+	// Prefix restricts the results to entries with exactly this prefix
+	Prefix string
+
+	// Contains restricts the results to entries whose message contains this
+	Contains string
+
+	// Limit is the maximum number of results, which are the most recent
+	// matching entries.
+	Limit int
+}
+
+// NewSQLiteStore creates a SQLiteStore that stores entries in the given table,
+// creating the table and its indexes if necessary.
+func NewSQLiteStore(db *sql.DB, table string) (*SQLiteStore, error) {
+	if _, err := db.Exec(fmt.Sprintf(sqliteSchema, table)); err != nil {
+		return nil, err
+	}
+	s := &SQLiteStore{db: db, table: table}
+	s.batcher = newBatcher(10000, 500, time.Second, s.insert)
+	return s, nil
+}
+
+// Write implements io.Writer, storing each write as the message of an entry
+// without severity.
+func (s *SQLiteStore) Write(p []byte) (int, error) {
+	return len(p), s.WriteEntry(Entry{Time: time.Now(), Message: strings.TrimSuffix(string(p), "\n")})
+}
+
+// WriteEntry implements EntryWriter
+func (s *SQLiteStore) WriteEntry(e Entry) error {
+	return s.add(e)
+}
+
+// Query returns the entries matching q, oldest first. Queued entries are
+// stored before querying. Context values are returned as decoded from JSON.
+func (s *SQLiteStore) Query(q StoreQuery) ([]Entry, error) {
+	s.Flush()
+	query, args := s.buildQuery(q)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		var ts int64
+		var severity int
+		var stack, ctx string
+		if err := rows.Scan(&ts, &severity, &e.Prefix, &e.File, &e.Line, &e.Message, &stack, &ctx); err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(0, ts)
+		e.Severity = Severity(severity)
+		if stack != "" {
+			e.Stack = strings.Split(stack, "\n")
+		}
+		if ctx != "" {
+			if err := json.Unmarshal([]byte(ctx), &e.Context); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// Results are selected most recent first in order to apply the limit
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+func (s *SQLiteStore) buildQuery(q StoreQuery) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		conditions = append(conditions, "time < ?")
+		args = append(args, q.Until.UnixNano())
+	}
+	if q.MinSeverity != 0 {
+		conditions = append(conditions, "severity >= ?")
+		args = append(args, int(q.MinSeverity))
+	}
+	if q.Prefix != "" {
+		conditions = append(conditions, "prefix = ?")
+		args = append(args, q.Prefix)
+	}
+	if q.Contains != "" {
+		conditions = append(conditions, "instr(message, ?) > 0")
+		args = append(args, q.Contains)
+	}
+
+	query := "SELECT " + sqliteColumns + " FROM " + s.table
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY time DESC, id DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return query, args
+}
+
+func (s *SQLiteStore) insert(batch []Entry) {
+	if err := s.doInsert(batch); err != nil {
+		errorOnLogging(err)
+	}
+}
+
+func (s *SQLiteStore) doInsert(batch []Entry) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO " + s.table + " (" + sqliteColumns + ") VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range batch {
+		ctx := ""
+		if len(e.Context) > 0 {
+			buf := &bytes.Buffer{}
+			writeJSONObject(buf, e.Context)
+			ctx = buf.String()
+		}
+		_, err := stmt.Exec(e.Time.UnixNano(), int(e.Severity), e.Prefix, e.File, e.Line, e.Message, strings.Join(e.Stack, "\n"), ctx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package golog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("fakesqlite", &fakeDriver{})
+}
+
+// fakeDriver is a database/sql driver that records executed statements and
+// returns the inserted rows, most recent first, from any query.
+type fakeDriver struct {
+	mx         sync.Mutex
+	statements []string
+	rows       [][]driver.Value
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{d}, nil
+}
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.d, query}, nil
+}
+
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { return nil }
+func (c *fakeConn) Rollback() error           { return nil }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mx.Lock()
+	defer s.d.mx.Unlock()
+	s.d.statements = append(s.d.statements, s.query)
+	if strings.HasPrefix(s.query, "INSERT") {
+		s.d.rows = append([][]driver.Value{args}, s.d.rows...)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mx.Lock()
+	defer s.d.mx.Unlock()
+	return &fakeRows{rows: append([][]driver.Value(nil), s.d.rows...)}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return strings.Split(sqliteColumns, ", ")
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLiteStore(t *testing.T) {
+	db, err := sql.Open("fakesqlite", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	d := db.Driver().(*fakeDriver)
+	s, err := NewSQLiteStore(db, "logs")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+	assert.Contains(t, d.statements[0], "CREATE TABLE IF NOT EXISTS logs (")
+	assert.Contains(t, d.statements[0], "CREATE INDEX IF NOT EXISTS logs_prefix ON logs (prefix, time);")
+
+	first := Entry{
+		Time:     time.Unix(0, 1000),
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Stack:    []string{"at a", "at b"},
+		Context:  map[string]interface{}{"cvarA": "a", "count": 5},
+	}
+	s.WriteEntry(first)
+	s.Write([]byte("plain\n"))
+
+	entries, err := s.Query(StoreQuery{})
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		assert.Equal(t, first.Time.UnixNano(), entries[0].Time.UnixNano())
+		assert.Equal(t, first.Severity, entries[0].Severity)
+		assert.Equal(t, first.Prefix, entries[0].Prefix)
+		assert.Equal(t, first.File, entries[0].File)
+		assert.Equal(t, first.Line, entries[0].Line)
+		assert.Equal(t, first.Message, entries[0].Message)
+		assert.Equal(t, first.Stack, entries[0].Stack)
+		assert.Equal(t, map[string]interface{}{"cvarA": "a", "count": float64(5)}, entries[0].Context)
+		assert.Equal(t, "plain", entries[1].Message)
+		assert.Nil(t, entries[1].Stack)
+		assert.Nil(t, entries[1].Context)
+	}
+}
+
+func TestSQLiteStoreBuildQuery(t *testing.T) {
+	s := &SQLiteStore{table: "logs"}
+	query, args := s.buildQuery(StoreQuery{})
+	assert.Equal(t, "SELECT "+sqliteColumns+" FROM logs ORDER BY time DESC, id DESC", query)
+	assert.Empty(t, args)
+
+	query, args = s.buildQuery(StoreQuery{
+		Since:       time.Unix(0, 100),
+		Until:       time.Unix(0, 200),
+		MinSeverity: ERROR,
+		Prefix:      "myprefix",
+		Contains:    "world",
+		Limit:       10,
+	})
+	assert.Equal(t, "SELECT "+sqliteColumns+" FROM logs WHERE time >= ? AND time < ? AND severity >= ? AND prefix = ? AND instr(message, ?) > 0 ORDER BY time DESC, id DESC LIMIT ?", query)
+	assert.Equal(t, []interface{}{int64(100), int64(200), ERROR, "myprefix", "world", 10}, args)
+}
@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+)
+
+const (
+	sseKeepAliveInterval = 30 * time.Second
+)
+
+// SSEHandler returns an http.Handler that streams the entries of this LiveTail
+// as Server-Sent Events (text/event-stream), a lighter-weight alternative to
+// the WebSocket for quick debugging with curl or a browser's EventSource, e.g.:
+//
+//	curl -N 'http://localhost:8080/debug/tail?severity=warn&prefix=flashlight'
+//
+// It supports the same query parameters as the WebSocket. Each entry is sent as
+// one event, with a data line for each of its lines.
+func (lt *LiveTail) SSEHandler() http.Handler {
+	return http.HandlerFunc(lt.serveSSE)
+}
+
+func (lt *LiveTail) serveSSE(resp http.ResponseWriter, req *http.Request) {
+	flusher, ok := resp.(http.Flusher)
+	if !ok {
+		http.Error(resp, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	sub, err := newLiveTailSubscriber(req)
+	if err != nil {
+		http.Error(resp, err.Error(), http.StatusBadRequest)
+		return
+	}
+	lt.subscribe(sub)
+	defer lt.unsubscribe(sub)
+
+	resp.Header().Set("Content-Type", "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	var buf bytes.Buffer
+	for {
+		select {
+		case b := <-sub.entries:
+			buf.Reset()
+			writeSSEEvent(&buf, b)
+			if _, err := resp.Write(buf.Bytes()); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			// comment lines keep proxies from closing idle connections
+			if _, err := resp.Write([]byte(":\n\n")); err != nil {
+				return
+			}
+		case <-req.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeSSEEvent writes the formatted entry b as an event with a data line for
+// each of its lines
+func writeSSEEvent(buf *bytes.Buffer, b []byte) {
+	b = bytes.TrimRight(b, "\n")
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(bytes.TrimSuffix(line, []byte("\r")))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,55 @@
+package golog
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveTailSSE(t *testing.T) {
+	lt := NewLiveTailWithHistory(10)
+	lt.WriteEntry(Entry{Severity: ERROR, Prefix: "myprefix", Message: "before connecting"})
+	lt.WriteEntry(Entry{Severity: DEBUG, Prefix: "myprefix", Message: "too low"})
+	server := httptest.NewServer(lt.SSEHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?severity=error&prefix=my")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lt.WriteEntry(Entry{Severity: ERROR, Prefix: "other", Message: "wrong prefix"})
+	lt.WriteEntry(Entry{Severity: ERROR, Prefix: "myprefix", Message: "Hello", Stack: []string{"  at main"}})
+
+	br := bufio.NewReader(resp.Body)
+	var events []string
+	var event strings.Builder
+	for len(events) < 2 {
+		line, err := br.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		if line == "\n" {
+			events = append(events, event.String())
+			event.Reset()
+			continue
+		}
+		event.WriteString(line)
+	}
+	assert.Equal(t, []string{
+		"data: ERROR myprefix: before connecting\n",
+		"data: ERROR myprefix: Hello\ndata: ERROR myprefix:   at main\n",
+	}, events)
+
+	resp, err = http.Get(server.URL + "?severity=bogus")
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,220 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	framePrefix      = "  at "
+	causePrefix      = "Caused by: "
+	moreFramesPrefix = "  ... "
+)
+
+var (
+	stackOptions atomic.Value
+)
+
+func init() {
+	SetStackOptions(StackOptions{})
+}
+
+// StackOptions controls how the stack traces and causes of errors, as well as
+// the stacks appended by WithStack, are rendered in the Stack of entries. The
+// zero value renders them in full.
+type StackOptions struct {
+	// Disabled omits all stack frames, leaving only the causes of errors
+	Disabled bool
+
+	// MaxDepth is the maximum number of frames rendered for each stack trace.
+	// The remaining frames are replaced by a single "  ... N more frames"
+	// line. 0 means unlimited.
+	MaxDepth int
+
+	// SkipFrames omits frames whose function name, like
+	// "testing.tRunner", matches any of the given patterns, e.g.
+	// regexp.MustCompile(`^(runtime|testing)\.`). Skipped frames don't count
+	// towards MaxDepth.
+	SkipFrames []*regexp.Regexp
+
+	// OmitCauses omits the "Caused by:" chains of errors along with their
+	// stack traces
+	OmitCauses bool
+}
+
+// SetStackOptions sets the StackOptions used by all loggers that don't have
+// their own StackOptions (see Logger.WithStackOptions).
+func SetStackOptions(opts StackOptions) {
+	stackOptions.Store(&opts)
+}
+
+func (l *logger) WithStack() Logger {
+	c := l.child(l.prefix, l.fields)
+	c.withStack = true
+	return c
+}
+
+func (l *logger) WithStackOptions(opts StackOptions) Logger {
+	c := l.child(l.prefix, l.fields)
+	c.stackOpts = &opts
+	return c
+}
+
+// getStackOptions returns the StackOptions to use for this logger
+func (l *logger) getStackOptions() *StackOptions {
+	if l.stackOpts != nil {
+		return l.stackOpts
+	}
+	return stackOptions.Load().(*StackOptions)
+}
+
+// apply returns the given stack lines with the options applied, which are
+// the lines themselves if there's nothing to do.
+func (o *StackOptions) apply(lines []string) []string {
+	if len(lines) == 0 || (!o.Disabled && o.MaxDepth <= 0 && len(o.SkipFrames) == 0 && !o.OmitCauses) {
+		return lines
+	}
+	result := make([]string, 0, len(lines))
+	depth, omitted := 0, 0
+	endStack := func() {
+		if omitted > 0 {
+			result = append(result, fmt.Sprintf(moreFramesPrefix+"%d more frames", omitted))
+		}
+		depth, omitted = 0, 0
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, framePrefix) {
+			endStack()
+			if o.OmitCauses && strings.HasPrefix(line, causePrefix) {
+				// everything following the first cause belongs to the causes
+				return result
+			}
+			result = append(result, line)
+			continue
+		}
+		if o.Disabled || o.skips(line) {
+			continue
+		}
+		if o.MaxDepth > 0 && depth >= o.MaxDepth {
+			omitted++
+			continue
+		}
+		depth++
+		result = append(result, line)
+	}
+	endStack()
+	return result
+}
+
+// skips reports whether the given frame line matches any of the SkipFrames
+func (o *StackOptions) skips(frame string) bool {
+	if len(o.SkipFrames) == 0 {
+		return false
+	}
+	function, _, _ := parseFrame(frame)
+	for _, pattern := range o.SkipFrames {
+		if pattern.MatchString(function) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFrame reports whether the given stack line is a frame
+func isFrame(line string) bool {
+	return strings.HasPrefix(line, framePrefix)
+}
+
+// parseFrame splits a frame line like "  at main.run (main.go:12)" into the
+// function, file and line. Parts that can't be parsed are left empty.
+func parseFrame(frame string) (function string, file string, line int) {
+	function = strings.TrimPrefix(frame, framePrefix)
+	idx := strings.LastIndex(function, " (")
+	if idx < 0 || !strings.HasSuffix(function, ")") {
+		return function, "", 0
+	}
+	location := function[idx+2 : len(function)-1]
+	function = function[:idx]
+	file = location
+	if colon := strings.LastIndexByte(location, ':'); colon >= 0 {
+		if n, err := strconv.Atoi(location[colon+1:]); err == nil {
+			file, line = location[:colon], n
+		}
+	}
+	return function, file, line
+}
+
+// singleLineStack joins each run of frame lines in the given stack into a
+// single line like "  at main.a (main.go:1) <- main.b (main.go:2)". Other
+// lines, like causes, are kept as is.
+func singleLineStack(lines []string) []string {
+	result := make([]string, 0, len(lines))
+	inStack := false
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, framePrefix) && inStack:
+			result[len(result)-1] += " <- " + strings.TrimPrefix(line, framePrefix)
+		case strings.HasPrefix(line, moreFramesPrefix) && inStack:
+			result[len(result)-1] += " <- " + strings.TrimPrefix(line, moreFramesPrefix)
+		default:
+			inStack = strings.HasPrefix(line, framePrefix)
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// writeJSONStack writes the given stack as a JSON array with an object for
+// each line. Frames are written as {"function":…,"file":…,"line":…}, causes
+// as {"caused_by":…} and other lines as {"text":…}.
+func writeJSONStack(buf *bytes.Buffer, lines []string) {
+	buf.WriteByte('[')
+	for i, line := range lines {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		switch {
+		case strings.HasPrefix(line, framePrefix):
+			function, file, lineNumber := parseFrame(line)
+			buf.WriteString(`{"function":`)
+			writeJSONString(buf, function)
+			buf.WriteString(`,"file":`)
+			writeJSONString(buf, file)
+			buf.WriteString(`,"line":`)
+			writeJSONInt(buf, int64(lineNumber))
+			buf.WriteByte('}')
+		case strings.HasPrefix(line, causePrefix):
+			buf.WriteString(`{"caused_by":`)
+			writeJSONString(buf, strings.TrimPrefix(line, causePrefix))
+			buf.WriteByte('}')
+		default:
+			buf.WriteString(`{"text":`)
+			writeJSONString(buf, strings.TrimSpace(line))
+			buf.WriteByte('}')
+		}
+	}
+	buf.WriteByte(']')
+}
+
+// stackLines renders the stack of the current goroutine, skipping the given
+// number of frames, in the same format as the stacks of
+// github.com/getlantern/errors.
+func stackLines(skipFrames int) []string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skipFrames, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var lines []string
+	for {
+		frame, more := frames.Next()
+		lines = append(lines, fmt.Sprintf(framePrefix+"%v (%v:%d)", frame.Function, filepath.Base(frame.File), frame.Line))
+		if !more {
+			return lines
+		}
+	}
+}
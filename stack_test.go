@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithStack(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	l.WithStack().Infof("reached %v", "here")
+	assert.Regexp(t, `^INFO myprefix: stack_test.go:(\d+) reached here
+INFO myprefix: stack_test.go:\d+   at github.com/getlantern/golog.TestWithStack \(stack_test.go:\d+\)
+INFO myprefix: stack_test.go:\d+   at testing.tRunner \(testing.go:\d+\)
+`, out.String())
+
+	out.Reset()
+	l.Info("no stack")
+	assert.Regexp(t, `^INFO myprefix: stack_test.go:\d+ no stack\n$`, out.String())
+}
+
+func TestStackOptions(t *testing.T) {
+	lines := []string{
+		"  at main.a (main.go:1)",
+		"  at main.b (main.go:2)",
+		"  at testing.tRunner (testing.go:3)",
+		"  at runtime.goexit (asm_amd64.s:4)",
+		"Caused by: cause",
+		"  at main.c (main.go:5)",
+		"  at main.d (main.go:6)",
+	}
+	assert.Equal(t, lines, (&StackOptions{}).apply(lines))
+	assert.Equal(t, []string{"Caused by: cause"}, (&StackOptions{Disabled: true}).apply(lines))
+	assert.Equal(t, []string{
+		"  at main.a (main.go:1)",
+		"  ... 1 more frames",
+		"Caused by: cause",
+		"  at main.c (main.go:5)",
+		"  ... 1 more frames",
+	}, (&StackOptions{MaxDepth: 1, SkipFrames: []*regexp.Regexp{regexp.MustCompile(`^runtime\.`), regexp.MustCompile(`\.b$`)}}).apply(lines))
+	assert.Equal(t, lines[:4], (&StackOptions{OmitCauses: true}).apply(lines))
+}
+
+func TestWithStackOptions(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+	SetStackOptions(StackOptions{MaxDepth: 1})
+	defer SetStackOptions(StackOptions{})
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	l.WithStack().Info("limited")
+	assert.Regexp(t, `^INFO myprefix: stack_test.go:\d+ limited
+INFO myprefix: stack_test.go:\d+   at github.com/getlantern/golog.TestWithStackOptions \(stack_test.go:\d+\)
+INFO myprefix: stack_test.go:\d+   ... \d+ more frames
+$`, out.String())
+
+	out.Reset()
+	l.WithStack().WithStackOptions(StackOptions{Disabled: true, OmitCauses: true}).WithError(fmt.Errorf("wrapped: %w", errors.New("cause"))).Info("disabled")
+	assert.Regexp(t, `^INFO myprefix: stack_test.go:\d+ disabled \[error=wrapped: cause error_type=errors.errorString\]\n$`, out.String())
+}
@@ -0,0 +1,209 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// maxLineLength is the length after which lines written to a WriterAt are
+// logged even if they haven't been completed yet
+const maxLineLength = 64 * 1024
+
+// sniffedSeverities maps the leading tokens recognized by SniffSeverity to
+// severities.
+var sniffedSeverities = map[string]Severity{
+	"TRACE":    TRACE,
+	"DEBUG":    DEBUG,
+	"INFO":     INFO,
+	"NOTICE":   INFO,
+	"WARN":     WARN,
+	"WARNING":  WARN,
+	"ERROR":    ERROR,
+	"ERR":      ERROR,
+	"CRITICAL": ERROR,
+	"FATAL":    FATAL,
+}
+
+// klogSeverities maps the severity letters of klog (and glog) headers to
+// severities.
+var klogSeverities = map[byte]Severity{
+	'I': INFO,
+	'W': WARN,
+	'E': ERROR,
+	'F': FATAL,
+}
+
+// SniffSeverity looks for a severity token at the start of line, like
+// "WARN", "[WARN]", "warning:" or "ERROR:", in any case. If found, it returns
+// the corresponding severity and the rest of the line. Lines starting with
+// "panic:" are considered to be ERRORs and are returned unchanged. Lines with
+// a klog header, like "W0405 12:34:56.789012   123 file.go:12] message", are
+// recognized by the letter before the date, and the rest of the line is the
+// part following the header.
+func SniffSeverity(line string) (Severity, string, bool) {
+	if strings.HasPrefix(line, "panic:") {
+		return ERROR, line, true
+	}
+	if severity, rest, found := sniffKlog(line); found {
+		return severity, rest, true
+	}
+	token := line
+	rest := ""
+	if idx := strings.IndexAny(line, " \t"); idx >= 0 {
+		token, rest = line[:idx], strings.TrimLeft(line[idx:], " \t")
+	}
+	token = strings.TrimSuffix(token, ":")
+	if strings.HasPrefix(token, "[") && strings.HasSuffix(token, "]") {
+		token = token[1 : len(token)-1]
+	}
+	if severity, found := sniffedSeverities[strings.ToUpper(token)]; found {
+		return severity, rest, true
+	}
+	return 0, line, false
+}
+
+// sniffKlog looks for a klog header at the start of line, which starts with
+// the severity letter immediately followed by the month and day as "mmdd".
+func sniffKlog(line string) (Severity, string, bool) {
+	if len(line) < 5 {
+		return 0, line, false
+	}
+	severity, found := klogSeverities[line[0]]
+	if !found {
+		return 0, line, false
+	}
+	for i := 1; i < 5; i++ {
+		if line[i] < '0' || line[i] > '9' {
+			return 0, line, false
+		}
+	}
+	if len(line) > 5 && line[5] != ' ' {
+		return 0, line, false
+	}
+	rest := strings.TrimLeft(line[5:], " ")
+	if idx := strings.Index(rest, "] "); idx >= 0 {
+		rest = rest[idx+2:]
+	}
+	return severity, rest, true
+}
+
+// stdWriter is the io.Writer of standard loggers returned by logger
+type stdWriter struct {
+	l        *logger
+	severity Severity
+	sniff    bool
+}
+
+// Write implements method of io.Writer, due to different call depth,
+// it will not log correct file and line prefix
+func (w *stdWriter) Write(p []byte) (n int, err error) {
+	s := strings.TrimSuffix(string(p), "\n")
+	severity := w.severity
+	if w.sniff {
+		if sniffed, rest, found := SniffSeverity(s); found {
+			severity, s = sniffed, rest
+		}
+	}
+	w.l.print(w.l.outputFor(severity), 6, severity, s)
+	return len(p), nil
+}
+
+func (l *logger) AsStdLoggerAt(severity Severity) *log.Logger {
+	return log.New(&stdWriter{l: l, severity: severity}, "", 0)
+}
+
+func (l *logger) AsSniffingStdLogger(defaultSeverity Severity) *log.Logger {
+	return log.New(&stdWriter{l: l, severity: defaultSeverity, sniff: true}, "", 0)
+}
+
+// CaptureStdLog points the output of the standard library's global logger, as
+// used by log.Printf and friends, at golog, so that dependencies using it
+// don't bypass golog's outputs. Lines are logged at the given severity by a
+// Logger with the prefix "stdlog", with the file and line of the log.Printf
+// call. The flags and prefix of the global logger are cleared, as golog adds
+// its own header. Returns a function that restores the previous output, flags
+// and prefix.
+func CaptureStdLog(severity Severity) (reset func()) {
+	oldOut, oldFlags, oldPrefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(&stdWriter{l: LoggerFor("stdlog").(*logger), severity: severity})
+	log.SetFlags(0)
+	log.SetPrefix("")
+	return func() {
+		log.SetOutput(oldOut)
+		log.SetFlags(oldFlags)
+		log.SetPrefix(oldPrefix)
+	}
+}
+
+// outputFor returns the output for entries of the given severity, which is the
+// error output for warnings and above and the debug output for the rest.
+func (l *logger) outputFor(severity Severity) io.Writer {
+	if severity >= WARN {
+		return l.getOutputs().ErrorOut
+	}
+	return l.getOutputs().DebugOut
+}
+
+// lineWriter is the io.Writer returned by WriterAt
+type lineWriter struct {
+	l        *logger
+	severity Severity
+	sniff    bool
+	mx       sync.Mutex
+	buf      []byte
+}
+
+func (l *logger) WriterAt(severity Severity) io.Writer {
+	return &lineWriter{l: l, severity: severity}
+}
+
+func (l *logger) SniffingWriter(defaultSeverity Severity) io.Writer {
+	return &lineWriter{l: l, severity: defaultSeverity, sniff: true}
+}
+
+// Write implements io.Writer, logging each completed line
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			if len(w.buf) < maxLineLength {
+				break
+			}
+			i = maxLineLength
+		}
+		w.log(w.buf[:i])
+		if i < len(w.buf) && w.buf[i] == '\n' {
+			i++
+		}
+		w.buf = w.buf[:copy(w.buf, w.buf[i:])]
+	}
+	return len(p), nil
+}
+
+// Close logs the incomplete last line, if any
+func (w *lineWriter) Close() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *lineWriter) log(line []byte) {
+	s := string(bytes.TrimSuffix(line, []byte("\r")))
+	severity := w.severity
+	if w.sniff {
+		if sniffed, rest, found := SniffSeverity(s); found {
+			severity, s = sniffed, rest
+		}
+	}
+	w.l.print(w.l.outputFor(severity), 5, severity, s)
+}
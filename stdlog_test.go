@@ -0,0 +1,125 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffSeverity(t *testing.T) {
+	for line, expected := range map[string]struct {
+		severity Severity
+		rest     string
+	}{
+		"WARN something":           {WARN, "something"},
+		"[warn] something":         {WARN, "something"},
+		"Warning: something":       {WARN, "something"},
+		"ERROR: something":         {ERROR, "something"},
+		"[DEBUG]  something":       {DEBUG, "something"},
+		"info":                     {INFO, ""},
+		"panic: runtime error":     {ERROR, "panic: runtime error"},
+		"FATAL:\tsomething broken": {FATAL, "something broken"},
+		"W0405 12:34:56.789012   1234 main.go:42] something": {WARN, "something"},
+		"E1231 00:00:00.000000 1 x.go:1] ] bracket":          {ERROR, "] bracket"},
+		"I0101":               {INFO, ""},
+		"F0405 no header end": {FATAL, "no header end"},
+	} {
+		severity, rest, found := SniffSeverity(line)
+		if assert.True(t, found, line) {
+			assert.Equal(t, expected.severity, severity, line)
+			assert.Equal(t, expected.rest, rest, line)
+		}
+	}
+	for _, line := range []string{"something", "Errors happen", "[WARN something", "", "W04", "E1234abc", "X0405 something"} {
+		_, rest, found := SniffSeverity(line)
+		assert.False(t, found, line)
+		assert.Equal(t, line, rest)
+	}
+}
+
+func TestAsStdLoggerAt(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&customFormatter{})
+
+	l.AsStdLoggerAt(INFO).Print("WARN not sniffed")
+	l.AsStdLoggerAt(WARN).Print("warning")
+	assert.Regexp(t, `^INFO\|myprefix\|stdlog_test.go\|\d+\|WARN not sniffed\|0\n$`, debugOut.String())
+	assert.Regexp(t, `^WARN\|myprefix\|stdlog_test.go\|\d+\|warning\|0\n$`, errorOut.String())
+
+	debugOut.Reset()
+	errorOut.Reset()
+	sniffing := l.AsSniffingStdLogger(INFO)
+	sniffing.Print("[ERROR] failed")
+	sniffing.Print("DEBUG: details")
+	sniffing.Print("just info")
+	assert.Regexp(t, `^DEBUG\|myprefix\|stdlog_test.go\|\d+\|details\|0\nINFO\|myprefix\|stdlog_test.go\|\d+\|just info\|0\n$`, debugOut.String())
+	assert.Regexp(t, `^ERROR\|myprefix\|stdlog_test.go\|\d+\|failed\|0\n$`, errorOut.String())
+}
+
+func TestWriterAt(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&customFormatter{})
+
+	w := l.WriterAt(INFO)
+	w.Write([]byte("first line\nsecond "))
+	assert.Regexp(t, `^INFO\|myprefix\|stdlog_test.go\|\d+\|first line\|0\n$`, debugOut.String())
+	w.Write([]byte("line\r\n\nincomplete"))
+	assert.NoError(t, w.(io.Closer).Close())
+	assert.Regexp(t, `^INFO\|myprefix\|stdlog_test.go\|\d+\|first line\|0
+INFO\|myprefix\|stdlog_test.go\|\d+\|second line\|0
+INFO\|myprefix\|stdlog_test.go\|\d+\|\|0
+INFO\|myprefix\|stdlog_test.go\|\d+\|incomplete\|0
+$`, debugOut.String())
+
+	w = l.WriterAt(ERROR)
+	w.Write([]byte(strings.Repeat("x", maxLineLength+10)))
+	assert.Equal(t, 1, strings.Count(errorOut.String(), "\n"), "overlong lines should be logged in chunks")
+	assert.Contains(t, errorOut.String(), "ERROR|myprefix|stdlog_test.go")
+}
+
+func TestSniffingWriter(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&customFormatter{})
+
+	w := l.SniffingWriter(DEBUG)
+	w.Write([]byte("I0405 12:34:56.789012   1234 main.go:42] started\n[WARN] slow\nE0405 12:34:57.000000   1234 main.go:50] failed\nplain"))
+	assert.NoError(t, w.(io.Closer).Close())
+	assert.Regexp(t, `^INFO\|myprefix\|stdlog_test.go\|\d+\|started\|0
+DEBUG\|myprefix\|stdlog_test.go\|\d+\|plain\|0
+$`, debugOut.String())
+	assert.Regexp(t, `^WARN\|myprefix\|stdlog_test.go\|\d+\|slow\|0
+ERROR\|myprefix\|stdlog_test.go\|\d+\|failed\|0
+$`, errorOut.String())
+}
+
+func TestCaptureStdLog(t *testing.T) {
+	errorOut := &bytes.Buffer{}
+	debugOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	resetStdLog := CaptureStdLog(INFO)
+	log.Printf("Hello %v", "world")
+	log.Println("again")
+	resetStdLog()
+	assert.Regexp(t, `^INFO stdlog: stdlog_test.go:\d+ Hello world
+INFO stdlog: stdlog_test.go:\d+ again
+$`, debugOut.String())
+	assert.NotEqual(t, 0, log.Flags(), "flags should be restored")
+}
@@ -0,0 +1,168 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+	errNoSyslog = errors.New("unable to find local syslog socket")
+)
+
+// SyslogOptions configures a SyslogOutput
+type SyslogOptions struct {
+	// Facility is the syslog facility code, defaults to 1 (user-level messages)
+	// if zero.
+	Facility int
+
+	// Tag identifies the process in each message, defaults to the name of the
+	// executable.
+	Tag string
+
+	// Hostname is the reported hostname when logging to a remote syslog,
+	// defaults to the hostname of this machine.
+	Hostname string
+
+	// Formatter formats the message part of each entry, defaults to a
+	// TextFormatter.
+	Formatter Formatter
+}
+
+// SyslogOutput is an output that writes entries to a local or remote syslog
+// daemon, mapping severities to syslog severities. The message part of each
+// entry is rendered using the configured Formatter so that it looks the same as
+// it would anywhere else.
+//
+// If the connection fails, SyslogOutput reconnects and retries once.
+type SyslogOutput struct {
+	network string
+	addr    string
+	opts    SyslogOptions
+	local   bool
+
+	mx     sync.Mutex
+	conn   net.Conn
+	stream bool
+}
+
+// NewSyslogOutput connects to the syslog daemon at the given address. network
+// is one of "udp", "tcp", "unix" or "unixgram". If network and addr are both
+// empty, it connects to the local syslog daemon using its unix socket. opts may
+// be nil.
+func NewSyslogOutput(network string, addr string, opts *SyslogOptions) (*SyslogOutput, error) {
+	s := &SyslogOutput{network: network, addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Facility == 0 {
+		s.opts.Facility = defaultSyslogFacility
+	}
+	if s.opts.Tag == "" {
+		s.opts.Tag = filepath.Base(os.Args[0])
+	}
+	if s.opts.Hostname == "" {
+		s.opts.Hostname = hostname
+	}
+	if s.opts.Formatter == nil {
+		s.opts.Formatter = &TextFormatter{}
+	}
+	s.local = network == "" || strings.HasPrefix(network, "unix")
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write implements io.Writer, writing p as a message with debug severity
+func (s *SyslogOutput) Write(p []byte) (int, error) {
+	return len(p), s.write(syslogSeverity(DEBUG), p)
+}
+
+// WriteEntry implements EntryWriter
+func (s *SyslogOutput) WriteEntry(e Entry) error {
+	return s.write(syslogSeverity(e.Severity), s.opts.Formatter.Format(e))
+}
+
+// Close closes the connection to syslog
+func (s *SyslogOutput) Close() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (s *SyslogOutput) write(severity int, msg []byte) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	b := s.encode(severity, msg)
+	if s.conn != nil {
+		if _, err := s.conn.Write(b); err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+	if err := s.connect(); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(b)
+	return err
+}
+
+// encode encodes msg using the traditional BSD syslog format understood by
+// both local and remote syslog daemons. Must be called with mx held.
+func (s *SyslogOutput) encode(severity int, msg []byte) []byte {
+	msg = bytes.TrimRight(msg, "\n")
+	pri := s.opts.Facility*8 + severity
+	now := time.Now()
+	buf := &bytes.Buffer{}
+	if s.local {
+		fmt.Fprintf(buf, "<%d>%s %s[%d]: ", pri, now.Format(time.Stamp), s.opts.Tag, os.Getpid())
+	} else {
+		fmt.Fprintf(buf, "<%d>%s %s %s[%d]: ", pri, now.Format(time.RFC3339), s.opts.Hostname, s.opts.Tag, os.Getpid())
+	}
+	buf.Write(msg)
+	if s.stream {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func (s *SyslogOutput) connect() error {
+	if s.network != "" || s.addr != "" {
+		conn, err := net.Dial(s.network, s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		s.stream = strings.HasPrefix(s.network, "tcp") || s.network == "unix"
+		return nil
+	}
+	for _, path := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, path)
+			if err == nil {
+				s.conn = conn
+				s.stream = network == "unix"
+				return nil
+			}
+		}
+	}
+	return errNoSyslog
+}
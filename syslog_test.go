@@ -0,0 +1,75 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyslogOutputUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer pc.Close()
+
+	s, err := NewSyslogOutput("udp", pc.LocalAddr().String(), &SyslogOptions{
+		Facility:  16,
+		Tag:       "myapp",
+		Hostname:  "myhost",
+		Formatter: &customFormatter{},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	assert.NoError(t, s.WriteEntry(Entry{Severity: ERROR, Message: "Hello world"}))
+	b := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(b)
+	if assert.NoError(t, err) {
+		msg := string(b[:n])
+		assert.Regexp(t, fmt.Sprintf(`^<131>\S+ myhost myapp\[%d\]: ERROR\|\|\|0\|Hello world\|0$`, os.Getpid()), msg)
+	}
+}
+
+func TestSyslogOutputTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer l.Close()
+	lines := make(chan string, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	s, err := NewSyslogOutput("tcp", l.Addr().String(), &SyslogOptions{Tag: "myapp"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	buf := &bytes.Buffer{}
+	SetOutputs(s, buf)
+	defer ResetOutputs()
+	LoggerFor("myprefix").Error("Hello world")
+	_, err = s.Write([]byte("plain\n"))
+	assert.NoError(t, err)
+
+	assert.Regexp(t, `^<11>\S+ \S+ myapp\[\d+\]: ERROR myprefix: syslog_test.go:\d+ Hello world$`, <-lines)
+	assert.Regexp(t, `^<15>\S+ \S+ myapp\[\d+\]: plain$`, <-lines)
+}
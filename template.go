@@ -0,0 +1,67 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// TemplateFormatter is a Formatter that renders entries using a text/template,
+// for example:
+//
+//	{{.Time.Format "15:04:05"}} {{.Severity}} {{.Prefix}}: {{.Msg}}{{range .Stack}}
+//	{{.}}{{end}}
+//
+// In addition to the fields of Entry, templates can use .Msg (same as
+// .Message), .Caller ("file.go:12", or empty if callers aren't captured) and
+// .ContextString (the context values in the same "[key=value ...]" format used
+// by the default text output, with a leading space if non-empty). A trailing
+// newline is added if the template doesn't end with one.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+type templateData struct {
+	Entry
+	Msg           string
+	Caller        string
+	ContextString string
+}
+
+// NewTemplateFormatter parses the given layout into a TemplateFormatter
+func NewTemplateFormatter(layout string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("golog").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(layout)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (f *TemplateFormatter) Format(e Entry) []byte {
+	data := &templateData{
+		Entry: e,
+		Msg:   e.Message,
+	}
+	if e.File != "" {
+		data.Caller = e.File + ":" + strconv.Itoa(e.Line)
+	}
+	if len(e.Context) > 0 {
+		var buf bytes.Buffer
+		writeContext(&buf, e.Context, false, nil, false)
+		data.ContextString = buf.String()
+	}
+
+	buf := &bytes.Buffer{}
+	if err := f.tmpl.Execute(buf, data); err != nil {
+		buf.Reset()
+		fmt.Fprintf(buf, "%v %v: unable to execute log template: %v: %v", e.Severity, e.Prefix, err, e.Message)
+	}
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
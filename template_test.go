@@ -0,0 +1,48 @@
+package golog
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getlantern/ops"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFormatter(t *testing.T) {
+	_, err := NewTemplateFormatter("{{.Msg")
+	assert.Error(t, err, "invalid template should fail to parse")
+
+	f, err := NewTemplateFormatter(`{{.Time.Format "15:04"}} {{.Severity}} {{.Prefix}} {{.Caller}}: {{.Msg}}{{.ContextString}}`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	e := Entry{
+		Time:     time.Date(2019, 6, 10, 15, 4, 5, 0, time.UTC),
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Context:  map[string]interface{}{"cvarB": "b", "cvarA": "a"},
+	}
+	assert.Equal(t, "15:04 ERROR myprefix file.go:12: Hello world [cvarA=a cvarB=b]\n", string(f.Format(e)))
+
+	f, _ = NewTemplateFormatter("{{.Msg}}{{.Nonexistent}}")
+	result := string(f.Format(e))
+	assert.Contains(t, result, "ERROR myprefix: unable to execute log template: ")
+	assert.True(t, strings.HasSuffix(result, ": Hello world\n"), result)
+}
+
+func TestTemplateFormatterLogging(t *testing.T) {
+	f, _ := NewTemplateFormatter("{{.Severity}} {{.Msg}}{{range .Stack}} | {{.}}{{end}}{{.ContextString}}\n")
+	SetFormatter(f)
+	defer SetFormatter(nil)
+
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	defer ops.Begin("name").End()
+	LoggerFor("myprefix").Debugf("Hello %v", true)
+	assert.Equal(t, "DEBUG Hello true [op=name root_op=name]\n", out.String())
+}
@@ -0,0 +1,91 @@
+package golog
+
+import (
+	"strings"
+	"sync"
+)
+
+// TestingT is the part of testing.TB used by TestLogger, which *testing.T and
+// *testing.B implement
+type TestingT interface {
+	Name() string
+	Log(args ...interface{})
+	Error(args ...interface{})
+	Cleanup(func())
+}
+
+// TestLoggerOptions configures a TestLogger
+type TestLoggerOptions struct {
+	// FailOnError fails the test when an ERROR or FATAL is logged
+	FailOnError bool
+
+	// FailOnFatal fails the test when a FATAL is logged. FATALs never exit the
+	// process.
+	FailOnFatal bool
+}
+
+// TestLogger returns a Logger, with the test's name as its prefix, that writes
+// to t.Log instead of the package-level outputs, so that entries are shown
+// along with the test that logged them. As only this Logger and the Loggers
+// derived from it are affected, it works with parallel tests: their errors
+// aren't reported to the ErrorReporters and their prefixes aren't listed by
+// the AdminHandler. Entries logged after the test completed are discarded.
+// opts may be nil. For example:
+//
+//	func TestSomething(t *testing.T) {
+//		t.Parallel()
+//		s := NewServer(golog.TestLogger(t, &golog.TestLoggerOptions{FailOnError: true}))
+//		...
+//	}
+func TestLogger(t TestingT, opts *TestLoggerOptions) Logger {
+	var o TestLoggerOptions
+	if opts != nil {
+		o = *opts
+	}
+	out := &testOutput{t: t, opts: o}
+	t.Cleanup(out.stop)
+	l := newLogger(t.Name())
+	l.setOutputs(out, out)
+	l.onFatal = func(err error) {}
+	l.noReport = true
+	l.noRegister = true
+	return l
+}
+
+// testOutput writes entries to a TestingT
+type testOutput struct {
+	t       TestingT
+	opts    TestLoggerOptions
+	mx      sync.Mutex
+	stopped bool
+}
+
+// Write implements io.Writer
+func (o *testOutput) Write(p []byte) (int, error) {
+	return len(p), o.writeFormatted(Entry{}, p)
+}
+
+// writeFormatted implements formattedEntryWriter, so that entries are
+// formatted with the Formatter of the logger that wrote them
+func (o *testOutput) writeFormatted(e Entry, formatted []byte) error {
+	msg := strings.TrimSuffix(string(formatted), "\n")
+	o.mx.Lock()
+	defer o.mx.Unlock()
+	if o.stopped {
+		return nil
+	}
+	if (o.opts.FailOnError && e.Severity >= ERROR) || (o.opts.FailOnFatal && e.Severity == FATAL) {
+		o.t.Error(msg)
+	} else {
+		o.t.Log(msg)
+	}
+	return nil
+}
+
+// stop discards entries written after the test completed, as t.Log panics
+// then
+func (o *testOutput) stop() {
+	o.mx.Lock()
+	o.stopped = true
+	o.mx.Unlock()
+}
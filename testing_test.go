@@ -0,0 +1,69 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string { return "TestFake" }
+
+func (t *fakeT) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+
+func (t *fakeT) Error(args ...interface{}) { t.errors = append(t.errors, fmt.Sprint(args...)) }
+
+func (t *fakeT) Cleanup(fn func()) { t.cleanups = append(t.cleanups, fn) }
+
+func TestTestLogger(t *testing.T) {
+	global := &bytes.Buffer{}
+	reset := SetOutputs(global, global)
+	defer reset()
+
+	reported := 0
+	RegisterReporter(func(err error, severity Severity, ctx map[string]interface{}) {
+		if ctx["prefix"] == "TestFake" || ctx["prefix"] == "TestFake.json" {
+			reported++
+		}
+	})
+
+	ft := &fakeT{}
+	l := TestLogger(ft, &TestLoggerOptions{FailOnError: true})
+	l.Debug("debug")
+	l.WithField("a", 1).Warn("warn")
+	l.Error("failed")
+	l.Fatal("fatal")
+	json := l.Named("json")
+	json.SetFormatter(&JSONFormatter{})
+	json.Debug("formatted")
+	assert.Empty(t, global.String(), "nothing should be written to the package-level outputs")
+	if assert.Len(t, ft.logs, 3) {
+		assert.Regexp(t, `^DEBUG TestFake: testing_test.go:\d+ debug$`, ft.logs[0])
+		assert.Regexp(t, `^WARN TestFake: testing_test.go:\d+ warn \[a=1\]$`, ft.logs[1])
+		assert.Regexp(t, `^\{.*"message":"formatted".*\}$`, ft.logs[2], "should use the logger's Formatter")
+	}
+	if assert.Len(t, ft.errors, 2) {
+		assert.Regexp(t, `^ERROR TestFake: testing_test.go:\d+ failed$`, ft.errors[0])
+		assert.Regexp(t, `^FATAL TestFake: testing_test.go:\d+ fatal$`, ft.errors[1], "FATALs should fail the test with FailOnError")
+	}
+	assert.Zero(t, reported, "errors shouldn't be reported")
+	prefixesMx.RLock()
+	assert.False(t, prefixes["TestFake"], "prefix shouldn't be registered")
+	assert.False(t, prefixes["TestFake.json"], "prefix shouldn't be registered")
+	prefixesMx.RUnlock()
+
+	for _, fn := range ft.cleanups {
+		fn()
+	}
+	l.Debug("after test")
+	assert.Len(t, ft.logs, 3, "entries logged after the test should be discarded")
+
+	TestLogger(t, nil).Debug("shown with go test -v")
+}
@@ -11,7 +11,7 @@ import (
 
 const (
 	expectedCapture = `ERROR mytest: testlog_test.go:29 error 1
-DEBUG mytest: buffer.go:54 debug 1
+DEBUG mytest: testlog_test.go:34 debug 1
 `
 )
 
@@ -0,0 +1,220 @@
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getlantern/hidden"
+)
+
+// NewlinePolicy controls how a TextFormatter writes messages that contain
+// newlines.
+type NewlinePolicy int
+
+const (
+	// NewlinesRaw writes newlines as is, so continuation lines don't have a
+	// header (the default).
+	NewlinesRaw NewlinePolicy = iota
+
+	// NewlinesEscape replaces newlines with the two characters \n.
+	NewlinesEscape
+
+	// NewlinesPrefix writes each continuation line with the same header as the
+	// first line, like the lines of a stack trace.
+	NewlinesPrefix
+
+	// NewlinesQuote writes messages containing newlines as a single quoted
+	// string with newlines escaped.
+	NewlinesQuote
+)
+
+// TextFormatter is the default Formatter. It writes each line of an entry as:
+//
+//	[timestamp] SEVERITY prefix: file.go:12 message [key1=value1 key2=value2]
+//
+// The timestamp is only written if enabled with SetTimestampFormat.
+// Context values are only written on the first line. Lines of the Stack are
+// written with the same header as the first line. Output from the prepender
+// (see SetPrepender) is written before each entry.
+type TextFormatter struct {
+	// Color forces colored output regardless of the ColorMode
+	Color bool
+
+	// KeyOrder lists context keys that are written first, in the given order.
+	// The remaining keys are written after them, sorted.
+	KeyOrder []string
+
+	// QuoteValues quotes context values that contain whitespace, quotes, '=',
+	// '[' or ']' so that the context can be parsed reliably. Keys containing
+	// such characters have them replaced with underscores.
+	QuoteValues bool
+
+	// Newlines controls how messages containing newlines are written
+	Newlines NewlinePolicy
+
+	// SingleLineStack writes each stack trace on a single line, like
+	// "  at main.a (main.go:1) <- main.b (main.go:2)", instead of writing a
+	// line per frame. Causes are still written on their own lines.
+	SingleLineStack bool
+}
+
+func (f *TextFormatter) Format(e Entry) []byte {
+	return f.format(e, false)
+}
+
+func (f *TextFormatter) format(e Entry, colored bool) []byte {
+	colored = colored || f.Color
+	buf := bufferPool.Get()
+	defer bufferPool.Put(buf)
+
+	GetPrepender()(buf)
+	timestamp := formatTimestamp(e.Time)
+	writeHeader := func() {
+		if timestamp != "" {
+			buf.WriteString(timestamp)
+			buf.WriteByte(' ')
+		}
+		if !colored && e.header.matches(e) {
+			buf.WriteString(e.header.text)
+		} else {
+			writeSeverity(buf, e.Severity, colored)
+			buf.WriteByte(' ')
+			buf.WriteString(e.Prefix)
+			buf.WriteString(": ")
+		}
+		if e.File == "" {
+			return
+		}
+		if colored {
+			buf.WriteString(ansiDim)
+		}
+		buf.WriteString(e.File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(e.Line))
+		if colored {
+			buf.WriteString(ansiReset)
+		}
+		buf.WriteByte(' ')
+	}
+	message, stack := f.splitMessage(e)
+	if f.SingleLineStack {
+		stack = singleLineStack(stack)
+	}
+	writeHeader()
+	buf.WriteString(message)
+	writeContext(buf, e.Context, colored, f.KeyOrder, f.QuoteValues)
+	buf.WriteByte('\n')
+	for _, line := range stack {
+		writeHeader()
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	if bytes.IndexByte(buf.Bytes(), 0) >= 0 {
+		return []byte(hidden.Clean(buf.String()))
+	}
+	return copyBytes(buf)
+}
+
+// splitMessage applies the NewlinePolicy to the entry's message, returning the
+// message and the remaining lines to write.
+func (f *TextFormatter) splitMessage(e Entry) (string, []string) {
+	if !strings.Contains(e.Message, "\n") {
+		return e.Message, e.Stack
+	}
+	switch f.Newlines {
+	case NewlinesEscape:
+		return strings.Replace(e.Message, "\n", `\n`, -1), e.Stack
+	case NewlinesPrefix:
+		lines := strings.Split(e.Message, "\n")
+		return lines[0], append(lines[1:], e.Stack...)
+	case NewlinesQuote:
+		return strconv.Quote(e.Message), e.Stack
+	default:
+		return e.Message, e.Stack
+	}
+}
+
+// writeContext writes the given context values as " [key1=value1 key2=value2]"
+// with the keys in keyOrder first and the remaining keys sorted. Nothing is
+// written if there are no values.
+func writeContext(buf *bytes.Buffer, values map[string]interface{}, colored bool, keyOrder []string, quote bool) {
+	if len(values) == 0 {
+		return
+	}
+	if colored {
+		buf.WriteString(ansiDim)
+		defer buf.WriteString(ansiReset)
+	}
+	var scratch [64]byte
+	buf.WriteString(" [")
+	for i, key := range orderedKeys(values, keyOrder) {
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		value := appendTextValue(scratch[:0], values[key])
+		if quote {
+			key = strings.Map(textKeyRune, key)
+			if s := string(value); textNeedsQuoting(s) {
+				value = strconv.AppendQuote(scratch[:0], s)
+			}
+		}
+		buf.WriteString(key)
+		buf.WriteString("=")
+		buf.Write(value)
+	}
+	buf.WriteByte(']')
+}
+
+// appendTextValue appends value to dst as formatted by fmt.Sprint, using
+// strconv for typed Fields and common types instead of going through fmt
+func appendTextValue(dst []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case Field:
+		return v.appendText(dst)
+	case string:
+		return append(dst, v...)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case bool:
+		return strconv.AppendBool(dst, v)
+	default:
+		return append(dst, fmt.Sprint(v)...)
+	}
+}
+
+// orderedKeys returns the keys of values with the keys listed in keyOrder first
+// and the remaining keys sorted.
+func orderedKeys(values map[string]interface{}, keyOrder []string) []string {
+	if len(keyOrder) == 0 {
+		return sortedKeys(values)
+	}
+	keys := make([]string, 0, len(values))
+	ordered := make(map[string]bool, len(keyOrder))
+	for _, key := range keyOrder {
+		if _, found := values[key]; found && !ordered[key] {
+			keys = append(keys, key)
+			ordered[key] = true
+		}
+	}
+	for _, key := range sortedKeys(values) {
+		if !ordered[key] {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func textKeyRune(r rune) rune {
+	if r == '[' || r == ']' {
+		return '_'
+	}
+	return logfmtKeyRune(r)
+}
+
+func textNeedsQuoting(value string) bool {
+	return logfmtNeedsQuoting(value) || strings.ContainsAny(value, "[]")
+}
@@ -0,0 +1,78 @@
+package golog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextFormatterContext(t *testing.T) {
+	e := Entry{
+		Severity: DEBUG,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello world",
+		Context: map[string]interface{}{
+			"plain":   "a",
+			"spaces":  "a b",
+			"equals":  "a=b",
+			"bracket": "a]",
+			"quote":   `say "hi"`,
+			"empty":   "",
+			"odd key": 5,
+		},
+	}
+
+	assert.Equal(t, "DEBUG myprefix: file.go:12 Hello world [bracket=a] empty= equals=a=b odd key=5 plain=a quote=say \"hi\" spaces=a b]\n",
+		string((&TextFormatter{}).Format(e)), "default should be unchanged")
+
+	f := &TextFormatter{KeyOrder: []string{"spaces", "plain", "missing", "plain"}, QuoteValues: true}
+	assert.Equal(t, `DEBUG myprefix: file.go:12 Hello world [spaces="a b" plain=a bracket="a]" empty="" equals="a=b" odd_key=5 quote="say \"hi\""]`+"\n",
+		string(f.Format(e)))
+}
+
+func TestTextFormatterNewlines(t *testing.T) {
+	e := Entry{
+		Severity: DEBUG,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "Hello\n\"world\"",
+		Stack:    []string{"  at a"},
+		Context:  map[string]interface{}{"a": "b"},
+	}
+
+	format := func(policy NewlinePolicy) string {
+		return string((&TextFormatter{Newlines: policy}).Format(e))
+	}
+	assert.Equal(t, "DEBUG myprefix: file.go:12 Hello\n\"world\" [a=b]\nDEBUG myprefix: file.go:12   at a\n", format(NewlinesRaw))
+	assert.Equal(t, "DEBUG myprefix: file.go:12 Hello\\n\"world\" [a=b]\nDEBUG myprefix: file.go:12   at a\n", format(NewlinesEscape))
+	assert.Equal(t, "DEBUG myprefix: file.go:12 Hello [a=b]\nDEBUG myprefix: file.go:12 \"world\"\nDEBUG myprefix: file.go:12   at a\n", format(NewlinesPrefix))
+	assert.Equal(t, "DEBUG myprefix: file.go:12 \"Hello\\n\\\"world\\\"\" [a=b]\nDEBUG myprefix: file.go:12   at a\n", format(NewlinesQuote))
+
+	e.Message = "Hello world"
+	assert.Equal(t, "DEBUG myprefix: file.go:12 Hello world [a=b]\nDEBUG myprefix: file.go:12   at a\n", format(NewlinesQuote))
+}
+
+func TestTextFormatterSingleLineStack(t *testing.T) {
+	e := Entry{
+		Severity: ERROR,
+		Prefix:   "myprefix",
+		File:     "file.go",
+		Line:     12,
+		Message:  "failed",
+		Stack: []string{
+			"  at main.a (main.go:1)",
+			"  at main.b (main.go:2)",
+			"  ... 3 more frames",
+			"Caused by: cause",
+			"  at main.c (main.go:3)",
+		},
+	}
+	assert.Equal(t, `ERROR myprefix: file.go:12 failed
+ERROR myprefix: file.go:12   at main.a (main.go:1) <- main.b (main.go:2) <- 3 more frames
+ERROR myprefix: file.go:12 Caused by: cause
+ERROR myprefix: file.go:12   at main.c (main.go:3)
+`, string((&TextFormatter{SingleLineStack: true}).Format(e)))
+}
@@ -0,0 +1,102 @@
+package golog
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// UnixMillis is a timestamp layout for SetTimestampFormat that writes the
+// number of milliseconds since the Unix epoch.
+const UnixMillis = "UnixMillis"
+
+var (
+	timestampFormat atomic.Value
+)
+
+type timestampSettings struct {
+	layout    string
+	loc       *time.Location
+	precision int64
+	cached    atomic.Value
+}
+
+// cachedTimestamp is the most recently formatted timestamp, which is reused
+// for all times within the same period of the layout's precision
+type cachedTimestamp struct {
+	period    int64
+	formatted string
+}
+
+func init() {
+	SetTimestampFormat("", nil)
+}
+
+// SetTimestampFormat enables timestamps at the beginning of each line of the
+// default text output using the given layout (e.g. time.RFC3339,
+// time.RFC3339Nano or UnixMillis) in the given location (e.g. time.Local or
+// time.UTC). A nil location means time.Local. An empty layout disables
+// timestamps, which is the default.
+func SetTimestampFormat(layout string, loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	timestampFormat.Store(&timestampSettings{layout: layout, loc: loc, precision: layoutPrecision(layout)})
+}
+
+// layoutPrecision returns the precision in nanoseconds of times formatted with
+// the given layout, which is a second unless the layout has fractional seconds.
+// Layouts without seconds are also treated as having a precision of a second,
+// as the formatted time can't change within a second.
+func layoutPrecision(layout string) int64 {
+	precision := int64(time.Second)
+	for i := 0; i+1 < len(layout); i++ {
+		if layout[i] != '.' && layout[i] != ',' {
+			continue
+		}
+		digit := layout[i+1]
+		if digit != '0' && digit != '9' {
+			continue
+		}
+		digits := len(layout[i+1:]) - len(strings.TrimLeft(layout[i+1:], string(digit)))
+		for ; digits > 0 && precision > 1; digits-- {
+			precision /= 10
+		}
+	}
+	return precision
+}
+
+// formatTimestamp formats the given time according to the current timestamp
+// format, returning "" if timestamps are disabled.
+func formatTimestamp(t time.Time) string {
+	settings := timestampFormat.Load().(*timestampSettings)
+	switch settings.layout {
+	case "":
+		return ""
+	case UnixMillis:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	default:
+		return settings.format(t)
+	}
+}
+
+// format formats t with the layout, reusing the previously formatted timestamp
+// if t is within the same period of the layout's precision, so that time.Format
+// isn't called for every entry when logging many entries per second.
+func (s *timestampSettings) format(t time.Time) string {
+	if s.precision == 1 {
+		return t.In(s.loc).Format(s.layout)
+	}
+	nanos := t.UnixNano()
+	period := nanos / s.precision
+	if nanos < 0 && nanos%s.precision != 0 {
+		period--
+	}
+	if cached, _ := s.cached.Load().(*cachedTimestamp); cached != nil && cached.period == period {
+		return cached.formatted
+	}
+	formatted := t.In(s.loc).Format(s.layout)
+	s.cached.Store(&cachedTimestamp{period, formatted})
+	return formatted
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	defer SetTimestampFormat("", nil)
+	ts := time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.FixedZone("CEST", 2*60*60))
+
+	assert.Equal(t, "", formatTimestamp(ts), "timestamps should be disabled by default")
+
+	SetTimestampFormat(time.RFC3339, time.UTC)
+	assert.Equal(t, "2019-06-10T13:04:05Z", formatTimestamp(ts))
+
+	SetTimestampFormat(time.RFC3339Nano, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, "2019-06-10T15:04:05.123456789+02:00", formatTimestamp(ts))
+
+	SetTimestampFormat(UnixMillis, nil)
+	assert.Equal(t, "1560171845123", formatTimestamp(ts))
+}
+
+func TestTimestampCache(t *testing.T) {
+	defer SetTimestampFormat("", nil)
+	assert.EqualValues(t, time.Second, layoutPrecision(time.RFC3339))
+	assert.EqualValues(t, time.Second, layoutPrecision("2006-01-02"))
+	assert.EqualValues(t, time.Millisecond, layoutPrecision("15:04:05.000"))
+	assert.EqualValues(t, time.Microsecond, layoutPrecision("15:04:05,999999"))
+	assert.EqualValues(t, 1, layoutPrecision(time.RFC3339Nano))
+
+	SetTimestampFormat("15:04:05.000", time.UTC)
+	ts := time.Date(2019, 6, 10, 15, 4, 5, 123456789, time.UTC)
+	assert.Equal(t, "15:04:05.123", formatTimestamp(ts))
+	assert.Equal(t, "15:04:05.123", formatTimestamp(ts.Add(543*time.Microsecond)), "should reuse cached timestamp")
+	assert.Equal(t, "15:04:05.124", formatTimestamp(ts.Add(544*time.Microsecond)))
+	assert.Equal(t, "15:04:05.123", formatTimestamp(ts), "should reformat when going back in time")
+
+	SetTimestampFormat(time.RFC3339, time.UTC)
+	before := time.Date(1969, 12, 31, 23, 59, 59, 500000000, time.UTC)
+	assert.Equal(t, "1969-12-31T23:59:59Z", formatTimestamp(before))
+	assert.Equal(t, "1970-01-01T00:00:00Z", formatTimestamp(before.Add(600*time.Millisecond)))
+}
+
+func TestTimestampedText(t *testing.T) {
+	SetTimestampFormat("2006", time.UTC)
+	defer SetTimestampFormat("", nil)
+
+	out := newBuffer()
+	SetOutputs(ioutil.Discard, out)
+	LoggerFor("myprefix").Debug("Hello world")
+	assert.Equal(t, "999 DEBUG myprefix: timestamp_test.go:999 Hello world\n", out.String())
+}
@@ -0,0 +1,34 @@
+package golog
+
+import (
+	"fmt"
+	"time"
+)
+
+func (l *logger) TimeOperation(operation string) func() {
+	return l.timeOperation(operation, 0)
+}
+
+func (l *logger) TimeOperationOver(operation string, threshold time.Duration) func() {
+	return l.timeOperation(operation, threshold)
+}
+
+// timeOperation returns a function that logs the time elapsed since calling
+// timeOperation, at DEBUG if threshold is 0 and otherwise at WARN if the
+// threshold was exceeded.
+func (l *logger) timeOperation(operation string, threshold time.Duration) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		if elapsed < threshold || (threshold <= 0 && !l.enabled(DEBUG)) {
+			return
+		}
+		c := l.WithFields(map[string]interface{}{"operation": operation, "elapsed": Duration("elapsed", elapsed)}).(*logger)
+		message := fmt.Sprintf("%v took %v", operation, elapsed)
+		if threshold > 0 {
+			c.errorSkipFrames(message, 1, WARN)
+		} else {
+			c.print(c.getOutputs().DebugOut, 4, DEBUG, message)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package golog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeOperation(t *testing.T) {
+	debugOut := &bytes.Buffer{}
+	errorOut := &bytes.Buffer{}
+	reset := SetOutputs(errorOut, debugOut)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	func() {
+		defer l.TimeOperation("db.query")()
+	}()
+	assert.Regexp(t, `^DEBUG myprefix: timing_test.go:\d+ db.query took \S+ \[elapsed=\S+ operation=db.query\]\n$`, debugOut.String())
+
+	func() {
+		defer l.TimeOperationOver("fast", time.Hour)()
+	}()
+	func() {
+		defer l.TimeOperationOver("slow", time.Millisecond)()
+		time.Sleep(5 * time.Millisecond)
+	}()
+	assert.Regexp(t, `^WARN myprefix: timing_test.go:\d+ slow took \S+ \[elapsed=\S+ operation=slow\]\n$`, errorOut.String())
+
+	SetPrefixLevel("myprefix", INFO)
+	defer ResetPrefixLevels()
+	debugOut.Reset()
+	l.TimeOperation("hidden")()
+	assert.Empty(t, debugOut.String())
+}
@@ -0,0 +1,53 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedFields(t *testing.T) {
+	fields := []Field{
+		String("s", "text"),
+		Int("i", -5),
+		Int64("i64", math.MaxInt64),
+		Float64("f", 1.5),
+		Bool("b", true),
+		Duration("d", 1500*time.Millisecond),
+		Err(errors.New("failed")),
+		Any("a", []int{1, 2}),
+	}
+	var formatted []string
+	var values []interface{}
+	for _, f := range fields {
+		formatted = append(formatted, f.String())
+		values = append(values, f.Value())
+	}
+	assert.Equal(t, []string{"text", "-5", "9223372036854775807", "1.5", "true", "1.5s", "failed", "[1 2]"}, formatted)
+	assert.Equal(t, []interface{}{"text", int64(-5), int64(math.MaxInt64), 1.5, true, 1500 * time.Millisecond, errors.New("failed"), []int{1, 2}}, values)
+	assert.Equal(t, "<nil>", Err(nil).String())
+
+	buf := &bytes.Buffer{}
+	writeJSONObject(buf, map[string]interface{}{"s": fields[0], "i": fields[1], "f": fields[3], "b": fields[4], "d": fields[5], "error": fields[6]})
+	var decoded map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded), buf.String()) {
+		assert.Equal(t, map[string]interface{}{"s": "text", "i": float64(-5), "f": 1.5, "b": true, "d": "1.5s", "error": "failed"}, decoded)
+	}
+}
+
+func TestWithTypedFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	reset := SetOutputs(ioutil.Discard, out)
+	defer reset()
+
+	l := LoggerFor("myprefix")
+	l.SetFormatter(&TextFormatter{})
+	l.With(String("user", "bob"), "plain", 1, Int("attempt", 3), Duration("elapsed", time.Second)).Debug("Hello")
+	assert.Regexp(t, `^DEBUG myprefix: typed_test.go:\d+ Hello \[attempt=3 elapsed=1s plain=1 user=bob\]\n$`, out.String())
+}
@@ -0,0 +1,145 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+
+	wsMaxControlPayload = 125
+)
+
+var (
+	errNotWebsocket = errors.New("not a websocket handshake")
+)
+
+// isWebsocketUpgrade indicates whether req asks to upgrade to a WebSocket
+func isWebsocketUpgrade(req *http.Request) bool {
+	return headerContains(req.Header, "Connection", "upgrade") &&
+		headerContains(req.Header, "Upgrade", "websocket")
+}
+
+func headerContains(h http.Header, name string, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeWebsocket performs the server side of the WebSocket opening
+// handshake described in RFC 6455 and returns the hijacked connection.
+func upgradeWebsocket(resp http.ResponseWriter, req *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	key := req.Header.Get("Sec-WebSocket-Key")
+	if req.Method != http.MethodGet || !isWebsocketUpgrade(req) || key == "" {
+		http.Error(resp, errNotWebsocket.Error(), http.StatusBadRequest)
+		return nil, nil, errNotWebsocket
+	}
+	hj, ok := resp.(http.Hijacker)
+	if !ok {
+		http.Error(resp, "websockets not supported", http.StatusInternalServerError)
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+// websocketAccept computes the Sec-WebSocket-Accept for the given key
+func websocketAccept(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+websocketGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// writeWebsocketFrame writes a single unmasked (server to client) frame
+func writeWebsocketFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	w.WriteByte(0x80 | opcode)
+	size := len(payload)
+	switch {
+	case size <= 125:
+		w.WriteByte(byte(size))
+	case size <= 0xffff:
+		w.WriteByte(126)
+		binary.Write(w, binary.BigEndian, uint16(size))
+	default:
+		w.WriteByte(127)
+		binary.Write(w, binary.BigEndian, uint64(size))
+	}
+	w.Write(payload)
+	return w.Flush()
+}
+
+// readWebsocketFrame reads a single frame, unmasking its payload if
+// necessary. Control frames sent by clients are small, so payloads larger than
+// maxPayload are rejected.
+func readWebsocketFrame(r *bufio.Reader, maxPayload int) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	size := uint64(header[1] & 0x7f)
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+	if size > uint64(maxPayload) {
+		err = errors.New("websocket frame too large")
+		return
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, size)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
@@ -0,0 +1,147 @@
+//go:build zap
+// +build zap
+
+// Package zapadapter provides a zapcore.Core that logs through golog, so that
+// code using go.uber.org/zap, including zap-based libraries, shares golog's
+// formatting, outputs, severity levels, ops context and ErrorReporters. For
+// example:
+//
+//	log := zap.New(zapadapter.NewCore(golog.LoggerFor("myapp")), zap.AddCaller())
+//
+// zap isn't a dependency of golog, so this package is only built with the
+// "zap" build tag, e.g. go build -tags zap, and requires go.uber.org/zap in
+// the go.mod of the main module.
+//
+// Levels are mapped to golog severities, with DPanic, Panic and Fatal entries
+// being logged at ERROR. zap itself panics or exits after writing them. Fields
+// become context values, except for errors added with zap.Error, which are
+// attached with Logger.WithError so that their causes are logged too. Stack
+// traces added with zap.AddStacktrace are written as the stack of the entry.
+package zapadapter
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/getlantern/golog"
+	"go.uber.org/zap/zapcore"
+)
+
+type core struct {
+	l golog.Logger
+}
+
+// NewCore creates a zapcore.Core that logs to l
+func NewCore(l golog.Logger) zapcore.Core {
+	return &core{l: l}
+}
+
+// Severity returns the golog Severity at which entries of the given level are
+// logged
+func Severity(level zapcore.Level) golog.Severity {
+	switch {
+	case level >= zapcore.ErrorLevel:
+		return golog.ERROR
+	case level == zapcore.WarnLevel:
+		return golog.WARN
+	case level == zapcore.InfoLevel:
+		return golog.INFO
+	default:
+		return golog.DEBUG
+	}
+}
+
+func (c *core) Enabled(level zapcore.Level) bool {
+	severity := Severity(level)
+	// errors are reported even when they aren't written
+	return severity >= golog.ERROR || c.l.IsEnabled(severity)
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	return &core{l: withFields(c.l, fields)}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	l := c.l
+	if ent.LoggerName != "" {
+		l = l.Named(ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		l = l.WithCaller(filepath.Base(ent.Caller.File), ent.Caller.Line)
+	} else {
+		l = l.WithoutCaller()
+	}
+	l = withFields(l, fields)
+	var arg interface{} = ent.Message
+	if ent.Stack != "" {
+		arg = &message{text: ent.Message, stack: strings.Split(ent.Stack, "\n")}
+	}
+	switch Severity(ent.Level) {
+	case golog.DEBUG:
+		l.Debug(arg)
+	case golog.INFO:
+		l.Info(arg)
+	case golog.WARN:
+		l.Warn(arg)
+	default:
+		l.Error(arg)
+	}
+	return nil
+}
+
+// Sync flushes golog's outputs
+func (c *core) Sync() error {
+	return golog.Flush()
+}
+
+// withFields returns l with the given fields, attaching errors added with
+// zap.Error with WithError
+func withFields(l golog.Logger, fields []zapcore.Field) golog.Logger {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		if err, ok := f.Interface.(error); ok && f.Type == zapcore.ErrorType && f.Key == "error" {
+			l = l.WithError(err)
+			continue
+		}
+		f.AddTo(enc)
+	}
+	if len(enc.Fields) == 0 {
+		return l
+	}
+	return l.WithFields(enc.Fields)
+}
+
+// message is a message with a stack trace, which is also an error so that
+// it's reported as is by Logger.Error
+type message struct {
+	text  string
+	stack []string
+}
+
+func (m *message) Error() string {
+	return m.text
+}
+
+func (m *message) MultiLinePrinter() func(buf *bytes.Buffer) bool {
+	i := -1
+	return func(buf *bytes.Buffer) bool {
+		if i < 0 {
+			buf.WriteString(m.text)
+		} else {
+			buf.WriteString(m.stack[i])
+		}
+		i++
+		return i < len(m.stack)
+	}
+}
@@ -0,0 +1,41 @@
+//go:build zap
+// +build zap
+
+package zapadapter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/getlantern/golog"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestCore(t *testing.T) {
+	buf := &bytes.Buffer{}
+	reset := golog.SetOutputs(buf, buf)
+	defer reset()
+
+	log := zap.New(NewCore(golog.LoggerFor("zapadapter")), zap.AddCaller())
+	log.Debug("debug", zap.Int("a", 1))
+	log.With(zap.String("b", "2")).Named("sub").Info("info")
+	log.Warn("warn")
+	log.Error("failed", zap.Error(errors.New("boom")))
+
+	assert.Regexp(t, `^DEBUG zapadapter: zapadapter_test.go:\d+ debug \[a=1\]
+INFO zapadapter.sub: zapadapter_test.go:\d+ info \[b=2\]
+WARN zapadapter: zapadapter_test.go:\d+ warn
+ERROR zapadapter: zapadapter_test.go:\d+ failed \[error=boom error_type=errors.errorString\]
+ERROR zapadapter: zapadapter_test.go:\d+ Caused by: boom
+$`, buf.String())
+}
+
+func TestSeverity(t *testing.T) {
+	assert.EqualValues(t, golog.DEBUG, Severity(zapcore.DebugLevel))
+	assert.EqualValues(t, golog.INFO, Severity(zapcore.InfoLevel))
+	assert.EqualValues(t, golog.WARN, Severity(zapcore.WarnLevel))
+	assert.EqualValues(t, golog.ERROR, Severity(zapcore.FatalLevel))
+}